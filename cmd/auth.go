@@ -0,0 +1,588 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amikos-tech/chroma-cli/chroma/secrets"
+	clitypes "github.com/amikos-tech/chroma-cli/chroma/types"
+	"github.com/amikos-tech/chroma-cli/chroma/utils"
+	chroma "github.com/amikos-tech/chroma-go"
+	"github.com/charmbracelet/huh"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// secretMigrationItem is the structured result of moving the credentials of a server to a secret backend.
+type secretMigrationItem struct {
+	Alias     string `json:"alias" yaml:"alias"`
+	AuthType  string `json:"auth_type" yaml:"auth_type"`
+	SecretRef string `json:"secret_ref" yaml:"secret_ref"`
+	Migrated  bool   `json:"migrated" yaml:"migrated"`
+}
+
+func migrateSecrets(cmd *cobra.Command, args []string) error {
+	backend, err := cmd.Flags().GetString("backend")
+	if err != nil {
+		return err
+	}
+	if backend == "" {
+		backend = defaultSecretBackend()
+	}
+	if backend == secrets.BackendPlain {
+		err := fmt.Errorf("choose a secret backend other than plain: %v", strings.Join(secrets.Backends, ", "))
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	dryRun, err := cmd.Flags().GetBool("dry-run")
+	if err != nil {
+		return err
+	}
+	servers, err := utils.GetServers()
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	var aliases = args
+	if len(aliases) == 0 {
+		aliases = configuredServers()
+	}
+	var items = make([]secretMigrationItem, 0)
+	var rows = make([][]string, 0)
+	for _, alias := range aliases {
+		serverConfig, ok := servers[alias]
+		if !ok {
+			err := fmt.Errorf("server with alias %v does not exist", alias)
+			cmd.Printf("%v\n", err)
+			return err
+		}
+		auth := serverConfig.Auth
+		if auth == nil || auth.Token == "" || auth.SecretRef != "" {
+			continue
+		}
+		var item = secretMigrationItem{Alias: alias, AuthType: string(auth.Type), SecretRef: secrets.Ref(backend, alias)}
+		if !dryRun {
+			if err := storeSecret(alias, backend, auth, auth.Token); err != nil {
+				err = fmt.Errorf("unable to migrate the credentials of %v: %v", alias, err)
+				cmd.Printf("%v\n", err)
+				return err
+			}
+			servers[alias] = serverConfig
+			item.Migrated = true
+		}
+		items = append(items, item)
+		rows = append(rows, []string{item.Alias, item.AuthType, item.SecretRef})
+	}
+	if len(items) == 0 {
+		return printMessage(cmd, "No plaintext credentials found in the config file", items)
+	}
+	if !dryRun {
+		// the config is written once all secrets are stored, a failure leaves the plaintext credentials in place
+		if err := utils.SetServers(servers); err != nil {
+			cmd.Printf("%v\n", err)
+			return err
+		}
+	}
+	err = printOutput(cmd, &tableOutput{Headers: []string{"ALIAS", "AUTH", "SECRET"}, Rows: rows, Items: items})
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	if dryRun {
+		fmt.Fprintf(cmd.ErrOrStderr(), "dry run, %v credentials would be moved to the %v secret backend\n", len(items), backend)
+		return nil
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "moved %v credentials to the %v secret backend\n", len(items), backend)
+	// backups of the config file written by migrations still hold the plaintext credentials
+	backups, _ := filepath.Glob(viper.ConfigFileUsed() + ".*.bak")
+	for _, backup := range backups {
+		fmt.Fprintf(cmd.ErrOrStderr(), "warning: %v may contain plaintext credentials, remove it\n", backup)
+	}
+	return nil
+}
+
+// readCredentials reads the credentials from the CHROMA_API_TOKEN, CHROMA_X_API_TOKEN or CHROMA_BASIC_AUTH
+// environment variables or, if prompt is set, asks for them on the terminal. A non-empty authType restricts the
+// credentials to that type. Returns AuthTypeNone if no credentials are provided.
+func readCredentials(authType AuthType, prompt bool) (AuthType, string, error) {
+	for _, env := range []struct {
+		name     string
+		authType AuthType
+	}{
+		{EnvChromaBasicAuth, AuthTypeBasic},
+		{EnvChromaXAPIToken, AuthTypeXToken},
+		{EnvChromaAPIToken, AuthTypeToken},
+	} {
+		if token := os.Getenv(env.name); token != "" && (authType == "" || authType == env.authType) {
+			return env.authType, token, nil
+		}
+	}
+	if !prompt {
+		return AuthTypeNone, "", nil
+	}
+	if !isInteractive() {
+		return "", "", fmt.Errorf("credentials are required. set %v, %v or %v", EnvChromaAPIToken, EnvChromaXAPIToken, EnvChromaBasicAuth)
+	}
+	if authType == "" {
+		err := huh.NewSelect[AuthType]().
+			Title("Authorization Type").
+			Options(
+				huh.NewOption("Basic", AuthTypeBasic),
+				huh.NewOption("Token (Authorization)", AuthTypeToken),
+				huh.NewOption("Token (X-Chroma-Token)", AuthTypeXToken),
+			).
+			Value(&authType).Run()
+		if err != nil {
+			return "", "", fmt.Errorf("unable to get the authorization type: %v", err)
+		}
+	}
+	var token string
+	var input = huh.NewInput().Value(&token).Password(true).Validate(func(s string) error {
+		if s == "" {
+			return errors.New("the credentials must not be empty")
+		}
+		return nil
+	})
+	if authType == AuthTypeBasic {
+		input = input.Title("Basic Auth").Placeholder("username:password")
+	} else {
+		input = input.Title("Token").Placeholder("token")
+	}
+	if err := input.Run(); err != nil {
+		return "", "", fmt.Errorf("unable to get the credentials: %v", err)
+	}
+	return authType, token, nil
+}
+
+// readOIDCConfig builds the identity provider settings of an OIDC login from the flags. Settings that are not given
+// are kept from the previous OIDC login of the server.
+func readOIDCConfig(cmd *cobra.Command, previous *clitypes.AuthConfig) (*clitypes.OIDCConfig, error) {
+	var config clitypes.OIDCConfig
+	if previous != nil && previous.Type == AuthTypeOIDC && previous.OIDC != nil {
+		config = *previous.OIDC
+	}
+	if f := cmd.Flag("issuer"); f.Changed {
+		config.Issuer = f.Value.String()
+	}
+	if f := cmd.Flag("client-id"); f.Changed {
+		config.ClientID = f.Value.String()
+	}
+	if f := cmd.Flag("audience"); f.Changed {
+		config.Audience = f.Value.String()
+	}
+	if f := cmd.Flag("flow"); f.Changed {
+		config.Flow = strings.ReplaceAll(f.Value.String(), "-", "_")
+	}
+	if config.Flow == "" {
+		config.Flow = clitypes.OIDCFlowDevice
+	}
+	if cmd.Flag("scopes").Changed {
+		scopes, err := cmd.Flags().GetStringSlice("scopes")
+		if err != nil {
+			return nil, err
+		}
+		config.Scopes = scopes
+	} else if config.Scopes == nil && config.Flow == clitypes.OIDCFlowDevice {
+		config.Scopes = []string{"openid", "offline_access"}
+	}
+	if config.Issuer == "" || config.ClientID == "" {
+		return nil, fmt.Errorf("--issuer and --client-id are required for the first OIDC login")
+	}
+	return &config, nil
+}
+
+// oidcLogin obtains tokens from the identity provider. The device flow prints the verification URL and code on stderr
+// and waits for the login to be approved. The client credentials flow reads the client secret from
+// CHROMA_OIDC_CLIENT_SECRET or asks for it on the terminal.
+func oidcLogin(cmd *cobra.Command, config *clitypes.OIDCConfig) (*oidcSession, error) {
+	if config.Flow == clitypes.OIDCFlowDevice {
+		return oidcDeviceLogin(context.Background(), config, func(verificationURL string, userCode string) {
+			fmt.Fprintf(cmd.ErrOrStderr(), "To log in, open %v and enter the code %v\nWaiting for the login to be approved...\n", verificationURL, userCode)
+		})
+	}
+	clientSecret := os.Getenv(EnvChromaOIDCClientSecret)
+	if clientSecret == "" {
+		if !isInteractive() {
+			return nil, fmt.Errorf("the client secret is required. set %v", EnvChromaOIDCClientSecret)
+		}
+		err := huh.NewInput().Title("Client Secret").Value(&clientSecret).Password(true).Validate(func(s string) error {
+			if s == "" {
+				return errors.New("the client secret must not be empty")
+			}
+			return nil
+		}).Run()
+		if err != nil {
+			return nil, fmt.Errorf("unable to get the client secret: %v", err)
+		}
+	}
+	return oidcClientCredentialsLogin(context.Background(), config, clientSecret)
+}
+
+// authIdentity is the identity the credentials of a server resolve to.
+type authIdentity struct {
+	UserID    string   `json:"user_id"`
+	Tenant    string   `json:"tenant"`
+	Databases []string `json:"databases"`
+}
+
+// verifyCredentials calls an authenticated endpoint. Servers with the identity endpoint report the user, tenant and
+// databases of the credentials. Older servers are checked by listing the collections of the database the client is
+// scoped to, the identity is then unknown.
+func verifyCredentials(ctx context.Context, client *chroma.Client) (*authIdentity, error) {
+	var identity authIdentity
+	err := apiRequest(ctx, client, http.MethodGet, "/api/v2/auth/identity", nil, &identity)
+	if isUnsupportedEndpoint(err) {
+		err = apiRequest(ctx, client, http.MethodGet, "/api/v1/collections", url.Values{"tenant": {client.Tenant}, "database": {client.Database}}, nil)
+		identity = authIdentity{}
+	}
+	var apiErr *apiError
+	if errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden) {
+		return nil, fmt.Errorf("the server rejected the credentials: %v", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to verify the credentials: %v", err)
+	}
+	return &identity, nil
+}
+
+// authAlias returns the server given as argument or the active server.
+func authAlias(args []string) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+	if alias := viper.GetString("active_server"); alias != "" {
+		return alias, nil
+	}
+	return "", fmt.Errorf("no active server. name the server or select one with chroma use")
+}
+
+func login(cmd *cobra.Command, args []string) error {
+	alias, err := authAlias(args)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	servers, err := utils.GetServers()
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	serverConfig, ok := servers[alias]
+	if !ok {
+		err := fmt.Errorf("server with alias %v does not exist", alias)
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	var candidate = serverConfig
+	var authType AuthType
+	var secret string
+	// the credentials the server is called with, for OIDC the fresh access token
+	var verifyAuth *clitypes.AuthConfig
+	if useOIDC, _ := cmd.Flags().GetBool("oidc"); useOIDC {
+		oidcConfig, err := readOIDCConfig(cmd, serverConfig.Auth)
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			return err
+		}
+		authType = AuthTypeOIDC
+		candidate.Auth = &clitypes.AuthConfig{Type: authType, OIDC: oidcConfig}
+		if err := candidate.Validate(); err != nil {
+			cmd.Printf("%v\n", err)
+			return err
+		}
+		session, err := oidcLogin(cmd, oidcConfig)
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			return err
+		}
+		secret = session.String()
+		verifyAuth = &clitypes.AuthConfig{Type: AuthTypeToken, Token: session.AccessToken}
+	} else {
+		typeFlag, _ := cmd.Flags().GetString("type")
+		switch AuthType(typeFlag) {
+		case "", AuthTypeBasic, AuthTypeToken, AuthTypeXToken:
+		default:
+			err := fmt.Errorf("unsupported auth type: %v. use basic, token or x-token", typeFlag)
+			cmd.Printf("%v\n", err)
+			return err
+		}
+		authType, secret, err = readCredentials(AuthType(typeFlag), true)
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			return err
+		}
+		candidate.Auth = &clitypes.AuthConfig{Type: authType, Token: secret}
+		if err := candidate.Validate(); err != nil {
+			cmd.Printf("%v\n", err)
+			return err
+		}
+		verifyAuth = candidate.Auth
+	}
+	var identity *authIdentity
+	if noVerify, _ := cmd.Flags().GetBool("no-verify"); !noVerify {
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		var verifyConfig = candidate
+		verifyConfig.Auth = verifyAuth
+		client, err := newClient(alias, verifyConfig, "", "")
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			return err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		if identity, err = verifyCredentials(ctx, client); err != nil {
+			cmd.Printf("%v\n", err)
+			return err
+		}
+	}
+	backend, _ := cmd.Flags().GetString("secret-backend")
+	if backend == "" {
+		backend = defaultSecretBackend()
+	}
+	var previous = serverConfig.Auth
+	if err := storeSecret(alias, backend, candidate.Auth, secret); err != nil {
+		err = fmt.Errorf("unable to store the credentials: %v", err)
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	if previous != nil && previous.SecretRef != "" && previous.SecretRef != candidate.Auth.SecretRef {
+		if err := deleteSecret(previous); err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "unable to remove the previous credentials of %v: %v\n", alias, err)
+		}
+	}
+	servers[alias] = candidate
+	if err := utils.SetServers(servers); err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	var message = fmt.Sprintf("Logged in to '%v' with %v credentials", alias, authType)
+	if identity != nil && identity.UserID != "" {
+		message = fmt.Sprintf("Logged in to '%v' as %v (tenant %v)", alias, identity.UserID, identity.Tenant)
+	}
+	return printMessage(cmd, message, serverItemFromConfig(alias, candidate))
+}
+
+func logout(cmd *cobra.Command, args []string) error {
+	alias, err := authAlias(args)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	servers, err := utils.GetServers()
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	serverConfig, ok := servers[alias]
+	if !ok {
+		err := fmt.Errorf("server with alias %v does not exist", alias)
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	if serverConfig.Auth == nil {
+		return printMessage(cmd, fmt.Sprintf("Server '%v' has no stored credentials", alias), serverItemFromConfig(alias, serverConfig))
+	}
+	if err := deleteSecret(serverConfig.Auth); err != nil {
+		err = fmt.Errorf("unable to remove the credentials: %v", err)
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	serverConfig.Auth = nil
+	servers[alias] = serverConfig
+	if err := utils.SetServers(servers); err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	return printMessage(cmd, fmt.Sprintf("Logged out of '%v'", alias), serverItemFromConfig(alias, serverConfig))
+}
+
+// authStatusItem is the structured result of checking the credentials of a server.
+type authStatusItem struct {
+	Server        string   `json:"server" yaml:"server"`
+	URL           string   `json:"url" yaml:"url"`
+	AuthType      string   `json:"auth_type" yaml:"auth_type"`
+	Credentials   string   `json:"credentials,omitempty" yaml:"credentials,omitempty"`
+	Issuer        string   `json:"issuer,omitempty" yaml:"issuer,omitempty"`
+	Authenticated bool     `json:"authenticated" yaml:"authenticated"`
+	UserID        string   `json:"user_id,omitempty" yaml:"user_id,omitempty"`
+	Tenant        string   `json:"tenant,omitempty" yaml:"tenant,omitempty"`
+	Databases     []string `json:"databases,omitempty" yaml:"databases,omitempty"`
+	Error         string   `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+func authStatus(cmd *cobra.Command, args []string) error {
+	alias, err := authAlias(args)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	timeout, err := cmd.Flags().GetDuration("timeout")
+	if err != nil {
+		return err
+	}
+	serverConfig, err := utils.GetServer(alias)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	var item = authStatusItem{
+		Server:      alias,
+		URL:         serverConfig.URL(),
+		AuthType:    string(serverConfig.AuthType()),
+		Credentials: describeCredentials(serverConfig.Auth),
+	}
+	if serverConfig.Auth != nil && serverConfig.Auth.OIDC != nil {
+		item.Issuer = serverConfig.Auth.OIDC.Issuer
+	}
+	client, err := newClient(alias, serverConfig, "", "")
+	if err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		var identity *authIdentity
+		if identity, err = verifyCredentials(ctx, client); err == nil {
+			item.Authenticated = true
+			item.UserID, item.Tenant, item.Databases = identity.UserID, identity.Tenant, identity.Databases
+		}
+	}
+	if err != nil {
+		item.Error = err.Error()
+	}
+	var rows = [][]string{
+		{"Server", alias},
+		{"URL", item.URL},
+		{"Auth", strings.TrimSpace(item.AuthType + " " + item.Credentials)},
+	}
+	if item.Issuer != "" {
+		rows = append(rows, []string{"Issuer", item.Issuer})
+	}
+	rows = append(rows, []string{"Authenticated", strconv.FormatBool(item.Authenticated)})
+	if item.Authenticated && item.UserID == "" {
+		rows = append(rows, []string{"Identity", "not reported by the server"})
+	}
+	if item.UserID != "" {
+		rows = append(rows, []string{"User", item.UserID}, []string{"Tenant", item.Tenant}, []string{"Databases", strings.Join(item.Databases, ",")})
+	}
+	if item.Error != "" {
+		rows = append(rows, []string{"Error", item.Error})
+	}
+	err = printOutput(cmd, &tableOutput{Headers: []string{"PROPERTY", "VALUE"}, Rows: rows, Items: item})
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	if !item.Authenticated {
+		return fmt.Errorf("not authenticated to %v: %v", alias, item.Error)
+	}
+	return nil
+}
+
+var LoginCommand = &cobra.Command{
+	Use:   "login [alias]",
+	Short: "Store credentials for a server",
+	Long: `Store the credentials of a server, the active server if no alias is given. The credentials are read from
+` + EnvChromaAPIToken + `, ` + EnvChromaXAPIToken + ` or ` + EnvChromaBasicAuth + ` or asked on the terminal. They are checked
+against the server before they are stored, use --no-verify to skip the check.
+
+With --oidc the credentials are access tokens from an OpenID Connect issuer, obtained with the device flow (a code is
+entered in the browser) or the client credentials grant (the client secret is read from ` + EnvChromaOIDCClientSecret + `
+or asked on the terminal). The tokens are cached in the secret backend and refreshed before they expire. The issuer,
+client id, flow, scopes and audience are remembered for the next login.`,
+	Args: cobra.MaximumNArgs(1),
+	Example: `  chroma auth login prod --type token
+  CHROMA_BASIC_AUTH=admin:secret chroma auth login local --secret-backend file
+  chroma auth login prod --oidc --issuer https://login.example.com --client-id chroma-cli
+  CHROMA_OIDC_CLIENT_SECRET=... chroma auth login ci --oidc --flow client-credentials --issuer https://login.example.com --client-id ci`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := login(cmd, args)
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+var LogoutCommand = &cobra.Command{
+	Use:   "logout [alias]",
+	Short: "Remove the stored credentials of a server",
+	Long:  `Remove the credentials of a server, the active server if no alias is given, from the config file and the secret backend.`,
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		err := logout(cmd, args)
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+var AuthStatusCommand = &cobra.Command{
+	Use:   "status [alias]",
+	Short: "Check the credentials of a server",
+	Long: `Check the credentials of a server, the active server if no alias is given, by calling an authenticated endpoint.
+Servers that support it report the user, tenant and databases the credentials resolve to. Exits with a non-zero status
+if the credentials are rejected.`,
+	Args: cobra.MaximumNArgs(1),
+	Example: `  chroma auth status
+  chroma auth status prod -o json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := authStatus(cmd, args)
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Manage the credentials of servers",
+}
+
+var MigrateSecretsCommand = &cobra.Command{
+	Use:   "migrate-secrets [alias...]",
+	Short: "Move plaintext credentials from the config file to a secret backend",
+	Long: `Move the tokens and basic auth credentials stored in plaintext in the config file to a secret backend and keep only
+a reference (e.g. keyring:prod) in the config file. Without aliases all servers are migrated.
+
+Secret backends:
+  keyring  the OS keyring (Secret Service on Linux, Keychain on macOS, Credential Manager on Windows)
+  file     a file encrypted with a master passphrase, secrets.age next to the config file unless secrets.file is set.
+           The passphrase is read from ` + EnvChromaSecretsPassphrase + ` or asked on the terminal
+  pass     pass or a compatible command set with secrets.command, entries are stored under chroma/<alias>`,
+	Args: cobra.ArbitraryArgs,
+	Example: `  chroma auth migrate-secrets --dry-run
+  chroma auth migrate-secrets prod --backend file`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := migrateSecrets(cmd, args)
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	MigrateSecretsCommand.Flags().String("backend", "", "Secret backend: keyring, file or pass. Defaults to secrets.backend of the config or keyring")
+	MigrateSecretsCommand.Flags().Bool("dry-run", false, "Only list the credentials that would be moved")
+	authCmd.AddCommand(MigrateSecretsCommand)
+	LoginCommand.Flags().String("type", "", "Auth type: basic, token or x-token. Asked on the terminal if not provided")
+	LoginCommand.Flags().String("secret-backend", "", "Where to store the credentials: keyring, file, pass or plain. Defaults to secrets.backend of the config or keyring")
+	LoginCommand.Flags().Bool("no-verify", false, "Store the credentials without checking them against the server")
+	LoginCommand.Flags().Duration("timeout", 10*time.Second, "Time to wait for the server to respond")
+	LoginCommand.Flags().Bool("oidc", false, "Log in with an OpenID Connect issuer")
+	LoginCommand.Flags().String("issuer", "", "OIDC issuer URL")
+	LoginCommand.Flags().String("client-id", "", "OIDC client id")
+	LoginCommand.Flags().String("flow", "", "OIDC flow: device or client-credentials. Defaults to device")
+	LoginCommand.Flags().StringSlice("scopes", nil, "OIDC scopes. Defaults to openid,offline_access for the device flow")
+	LoginCommand.Flags().String("audience", "", "OIDC audience of the access tokens, if the issuer requires one")
+	LoginCommand.MarkFlagsMutuallyExclusive("oidc", "type")
+	authCmd.AddCommand(LoginCommand)
+	authCmd.AddCommand(LogoutCommand)
+	AuthStatusCommand.Flags().Duration("timeout", 10*time.Second, "Time to wait for the server to respond")
+	authCmd.AddCommand(AuthStatusCommand)
+	RootCmd.AddCommand(authCmd)
+}
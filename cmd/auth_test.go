@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/amikos-tech/chroma-cli/chroma/secrets"
+	clitypes "github.com/amikos-tech/chroma-cli/chroma/types"
+	"github.com/amikos-tech/chroma-cli/chroma/utils"
+	"github.com/stretchr/testify/require"
+)
+
+// helperSecretsVault replaces the file secret backend with a vault in a temporary directory.
+func helperSecretsVault(t *testing.T) *secrets.Vault {
+	key := secrets.Ref(secrets.BackendFile, secretsVaultFile())
+	vault := &secrets.Vault{
+		File:       filepath.Join(t.TempDir(), "secrets.age"),
+		Passphrase: func(confirm bool) (string, error) { return "correct horse battery staple", nil },
+		WorkFactor: 10,
+	}
+	secretBackends[key] = vault
+	t.Cleanup(func() {
+		delete(secretBackends, key)
+	})
+	return vault
+}
+
+func TestMigrateSecrets(t *testing.T) {
+	command := RootCmd
+	defer resetCommandFlags(MigrateSecretsCommand)
+	helperRestoreServers(t)
+	vault := helperSecretsVault(t)
+	alias := strings.ToLower(getRandomName("secret"))
+	require.NoError(t, utils.SetServer(alias, clitypes.ServerConfig{
+		Host: "localhost",
+		Port: 8000,
+		Auth: &clitypes.AuthConfig{Type: AuthTypeBasic, Token: "admin:secret"},
+	}))
+	buf := new(bytes.Buffer)
+	command.SetOut(buf)
+	command.SetErr(buf)
+
+	t.Run("Dry run", func(t *testing.T) {
+		resetCommandFlags(MigrateSecretsCommand)
+		buf.Reset()
+		command.SetArgs([]string{"auth", "migrate-secrets", alias, "--backend", "file", "--dry-run"})
+		_, err := command.ExecuteC()
+		require.NoError(t, err)
+		require.Contains(t, buf.String(), "file:"+alias)
+		serverConfig, err := utils.GetServer(alias)
+		require.NoError(t, err)
+		require.Equal(t, "admin:secret", serverConfig.Auth.Token)
+	})
+
+	t.Run("Migrate", func(t *testing.T) {
+		resetCommandFlags(MigrateSecretsCommand)
+		buf.Reset()
+		command.SetArgs([]string{"auth", "migrate-secrets", alias, "--backend", "file"})
+		_, err := command.ExecuteC()
+		require.NoError(t, err)
+		require.Contains(t, buf.String(), "moved 1 credentials to the file secret backend")
+		serverConfig, err := utils.GetServer(alias)
+		require.NoError(t, err)
+		require.Empty(t, serverConfig.Auth.Token)
+		require.Equal(t, "file:"+alias, serverConfig.Auth.SecretRef)
+		secret, err := vault.Get(alias)
+		require.NoError(t, err)
+		require.Equal(t, "admin:secret", secret)
+		client, err := getClient(alias, "", "")
+		require.NoError(t, err)
+		require.NotEmpty(t, client.ApiClient.GetConfig().DefaultHeader["Authorization"])
+
+		resetCommandFlags(MigrateSecretsCommand)
+		buf.Reset()
+		command.SetArgs([]string{"auth", "migrate-secrets", alias, "--backend", "file"})
+		_, err = command.ExecuteC()
+		require.NoError(t, err)
+		require.Contains(t, buf.String(), "No plaintext credentials")
+	})
+
+	t.Run("Rename and remove", func(t *testing.T) {
+		renamed := alias + "-renamed"
+		require.NoError(t, renameServer(RenameCommand, []string{alias, renamed}))
+		serverConfig, err := utils.GetServer(renamed)
+		require.NoError(t, err)
+		require.Equal(t, "file:"+renamed, serverConfig.Auth.SecretRef)
+		_, err = vault.Get(alias)
+		require.ErrorIs(t, err, secrets.ErrNotFound)
+		require.NoError(t, deleteSecret(serverConfig.Auth))
+		_, err = vault.Get(renamed)
+		require.ErrorIs(t, err, secrets.ErrNotFound)
+	})
+}
+
+// helperAuthServer starts a server that accepts the bearer token good-token on the identity endpoint.
+func helperAuthServer(t *testing.T) (string, int) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/api/v2/auth/identity" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer good-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"Unauthorized"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"user_id":"alice","tenant":"acme","databases":["production","staging"]}`))
+	}))
+	t.Cleanup(server.Close)
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(u.Port())
+	require.NoError(t, err)
+	return u.Hostname(), port
+}
+
+func TestAuthLoginLogoutStatus(t *testing.T) {
+	command := RootCmd
+	defer resetCommandFlags(LoginCommand)
+	defer resetCommandFlags(AuthStatusCommand)
+	helperRestoreServers(t)
+	vault := helperSecretsVault(t)
+	host, port := helperAuthServer(t)
+	alias := strings.ToLower(getRandomName("auth"))
+	require.NoError(t, utils.SetServer(alias, clitypes.ServerConfig{Host: host, Port: port}))
+	buf := new(bytes.Buffer)
+	command.SetOut(buf)
+	command.SetErr(buf)
+
+	t.Run("Rejected credentials", func(t *testing.T) {
+		t.Setenv(EnvChromaAPIToken, "bad-token")
+		resetCommandFlags(LoginCommand)
+		require.NoError(t, LoginCommand.ParseFlags([]string{"--secret-backend", "file"}))
+		buf.Reset()
+		err := login(LoginCommand, []string{alias})
+		require.ErrorContains(t, err, "the server rejected the credentials")
+		serverConfig, err := utils.GetServer(alias)
+		require.NoError(t, err)
+		require.Nil(t, serverConfig.Auth)
+	})
+
+	t.Run("Login", func(t *testing.T) {
+		t.Setenv(EnvChromaAPIToken, "good-token")
+		resetCommandFlags(LoginCommand)
+		buf.Reset()
+		command.SetArgs([]string{"auth", "login", alias, "--secret-backend", "file"})
+		_, err := command.ExecuteC()
+		require.NoError(t, err)
+		require.Contains(t, buf.String(), "Logged in to '"+alias+"' as alice (tenant acme)")
+		serverConfig, err := utils.GetServer(alias)
+		require.NoError(t, err)
+		require.Equal(t, "file:"+alias, serverConfig.Auth.SecretRef)
+		secret, err := vault.Get(alias)
+		require.NoError(t, err)
+		require.Equal(t, "good-token", secret)
+	})
+
+	t.Run("Status", func(t *testing.T) {
+		resetCommandFlags(AuthStatusCommand)
+		buf.Reset()
+		command.SetArgs([]string{"auth", "status", alias, "-o", "json"})
+		_, err := command.ExecuteC()
+		require.NoError(t, err)
+		var item authStatusItem
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &item))
+		require.True(t, item.Authenticated)
+		require.Equal(t, "alice", item.UserID)
+		require.Equal(t, "acme", item.Tenant)
+		require.Equal(t, []string{"production", "staging"}, item.Databases)
+		require.Equal(t, "file:"+alias, item.Credentials)
+	})
+
+	t.Run("Logout", func(t *testing.T) {
+		buf.Reset()
+		command.SetArgs([]string{"auth", "logout", alias})
+		_, err := command.ExecuteC()
+		require.NoError(t, err)
+		serverConfig, err := utils.GetServer(alias)
+		require.NoError(t, err)
+		require.Nil(t, serverConfig.Auth)
+		_, err = vault.Get(alias)
+		require.ErrorIs(t, err, secrets.ErrNotFound)
+
+		resetCommandFlags(AuthStatusCommand)
+		require.NoError(t, AuthStatusCommand.ParseFlags([]string{}))
+		err = authStatus(AuthStatusCommand, []string{alias})
+		require.ErrorContains(t, err, "not authenticated")
+	})
+}
@@ -0,0 +1,739 @@
+package cmd
+
+import (
+	"archive/tar"
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/oklog/ulid/v2"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	chroma "github.com/amikos-tech/chroma-go"
+	"github.com/amikos-tech/chroma-go/types"
+)
+
+const (
+	// backupManifestVersion is the version of the archive layout written by chroma backup. Archives with a newer
+	// version are rejected by chroma restore. Version 2 added record hashes and incremental backups.
+	backupManifestVersion = 2
+	backupManifestFile    = "manifest.json"
+
+	backupTypeFull        = "full"
+	backupTypeIncremental = "incremental"
+)
+
+// backupManifest describes the content of a backup archive. It is the first entry of the archive and lists the
+// collections and the SHA-256 checksum of every other file in the archive. An incremental backup only contains the
+// records that changed since its base backup.
+type backupManifest struct {
+	Version     int                `json:"version"`
+	ID          string             `json:"id,omitempty"`
+	Type        string             `json:"type,omitempty"`
+	Base        string             `json:"base,omitempty"`
+	BaseFile    string             `json:"base_file,omitempty"`
+	CreatedAt   time.Time          `json:"created_at"`
+	Server      string             `json:"server"`
+	URL         string             `json:"url"`
+	Tenant      string             `json:"tenant,omitempty"`
+	Database    string             `json:"database,omitempty"`
+	Collections []backupCollection `json:"collections"`
+	Files       []backupFile       `json:"files"`
+}
+
+// backupCollection is a collection in a backup archive. Its records are stored as JSONL in File. HashesFile lists the
+// id and content hash of every record of the collection at the time of the backup and is the base for the next
+// incremental backup. DeletedFile lists the ids of the records deleted since the base backup.
+type backupCollection struct {
+	Tenant      string                 `json:"tenant"`
+	Database    string                 `json:"database"`
+	Name        string                 `json:"name"`
+	ID          string                 `json:"id"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	Records     int                    `json:"records"`
+	Total       int                    `json:"total,omitempty"`
+	Deleted     int                    `json:"deleted,omitempty"`
+	File        string                 `json:"file"`
+	HashesFile  string                 `json:"hashes_file,omitempty"`
+	DeletedFile string                 `json:"deleted_file,omitempty"`
+}
+
+// backupFile is a file in a backup archive.
+type backupFile struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+func (c backupCollection) key() string {
+	return c.ref().String()
+}
+
+// total returns the number of records of the collection at the time of the backup.
+func (c backupCollection) total() int {
+	if c.Total > 0 {
+		return c.Total
+	}
+	return c.Records
+}
+
+func (c backupCollection) ref() collectionRef {
+	return collectionRef{Tenant: c.Tenant, Database: c.Database, Collection: c.Name}
+}
+
+func (m *backupManifest) file(name string) *backupFile {
+	for i := range m.Files {
+		if m.Files[i].Path == name {
+			return &m.Files[i]
+		}
+	}
+	return nil
+}
+
+// stagedFile is a file of a backup archive written to the staging directory. The size and checksum are computed while
+// the file is written.
+type stagedFile struct {
+	file backupFile
+	f    *os.File
+	hash hash.Hash
+	w    io.Writer
+}
+
+func newStagedFile(dir string, name string) (*stagedFile, error) {
+	target := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+		return nil, err
+	}
+	f, err := os.Create(target)
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	return &stagedFile{file: backupFile{Path: name}, f: f, hash: h, w: io.MultiWriter(f, h)}, nil
+}
+
+func (s *stagedFile) Write(p []byte) (int, error) {
+	n, err := s.w.Write(p)
+	s.file.Size += int64(n)
+	return n, err
+}
+
+func (s *stagedFile) Close() (backupFile, error) {
+	s.file.SHA256 = hex.EncodeToString(s.hash.Sum(nil))
+	return s.file, s.f.Close()
+}
+
+// recordContentHash returns the hash of the document, metadata and embedding of a record.
+func recordContentHash(record recordItem) (string, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:16]), nil
+}
+
+// writeCollectionBackup writes the records of a collection to the staging directory. With a base, the record hashes
+// of the collection in the base backup, only the records whose hash changed are written and the ids of the records
+// that are not in the collection anymore are listed as deleted.
+func writeCollectionBackup(ctx context.Context, col *chroma.Collection, dir string, entry *backupCollection, base map[string]string, batchSize int32) ([]backupFile, error) {
+	prefix := path.Dir(entry.File)
+	entry.HashesFile = prefix + "/hashes.tsv"
+	var staged = make([]*stagedFile, 0, 3)
+	defer func() {
+		for _, f := range staged {
+			_, _ = f.Close()
+		}
+	}()
+	records, err := newStagedFile(dir, entry.File)
+	if err != nil {
+		return nil, err
+	}
+	staged = append(staged, records)
+	hashes, err := newStagedFile(dir, entry.HashesFile)
+	if err != nil {
+		return nil, err
+	}
+	staged = append(staged, hashes)
+	writer, err := newRecordWriter(records, RecordFormatJSONL, nil, true)
+	if err != nil {
+		return nil, err
+	}
+	var seen = make(map[string]struct{}, len(base))
+	err = forEachRecordBatch(ctx, col, batchSize, nil, nil, []types.QueryEnum{types.IDocuments, types.IMetadatas, types.IEmbeddings}, func(batch []recordItem) error {
+		var changed = make([]recordItem, 0, len(batch))
+		for _, record := range batch {
+			contentHash, err := recordContentHash(record)
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintf(hashes, "%v\t%v\n", record.ID, contentHash); err != nil {
+				return err
+			}
+			entry.Total++
+			if base != nil {
+				seen[record.ID] = struct{}{}
+				if base[record.ID] == contentHash {
+					continue
+				}
+			}
+			changed = append(changed, record)
+		}
+		entry.Records += len(changed)
+		return writer.Write(changed)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	if base != nil {
+		entry.DeletedFile = prefix + "/deleted.txt"
+		deleted, err := newStagedFile(dir, entry.DeletedFile)
+		if err != nil {
+			return nil, err
+		}
+		staged = append(staged, deleted)
+		var ids = make([]string, 0)
+		for id := range base {
+			if _, ok := seen[id]; !ok {
+				ids = append(ids, id)
+			}
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			if _, err := fmt.Fprintln(deleted, id); err != nil {
+				return nil, err
+			}
+		}
+		entry.Deleted = len(ids)
+	}
+	var files = make([]backupFile, 0, len(staged))
+	for _, f := range staged {
+		file, err := f.Close()
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	staged = nil
+	return files, nil
+}
+
+// readBackupHashes returns the record hashes of every collection in a backup archive by collection key.
+func readBackupHashes(archive string) (*backupManifest, map[string]map[string]string, error) {
+	var result = make(map[string]map[string]string)
+	manifest, err := readBackupArchive(archive, func(manifest *backupManifest, name string, r io.Reader) error {
+		for _, c := range manifest.Collections {
+			if c.HashesFile != name {
+				continue
+			}
+			var hashes = make(map[string]string, c.Total)
+			scanner := bufio.NewScanner(r)
+			scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+			for scanner.Scan() {
+				if id, contentHash, ok := strings.Cut(scanner.Text(), "\t"); ok {
+					hashes[id] = contentHash
+				}
+			}
+			if err := scanner.Err(); err != nil {
+				return err
+			}
+			result[c.key()] = hashes
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, c := range manifest.Collections {
+		if c.HashesFile == "" {
+			return nil, nil, fmt.Errorf("backup %v has no record hashes and cannot be the base of an incremental backup. create a full backup first", archive)
+		}
+	}
+	return manifest, result, nil
+}
+
+// writeBackupArchive writes the manifest and the staged files to a zstd compressed tar archive. The archive is written
+// to a temporary file first so an interrupted backup does not leave a truncated archive behind.
+func writeBackupArchive(archive string, manifest *backupManifest, dir string) error {
+	tmp := archive + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp) }()
+	defer f.Close()
+	zw, err := zstd.NewWriter(f)
+	if err != nil {
+		return err
+	}
+	tw := tar.NewWriter(zw)
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = tw.WriteHeader(&tar.Header{Name: backupManifestFile, Mode: 0600, Size: int64(len(data)), ModTime: manifest.CreatedAt, Typeflag: tar.TypeReg})
+	if err != nil {
+		return err
+	}
+	if _, err := tw.Write(data); err != nil {
+		return err
+	}
+	for _, file := range manifest.Files {
+		err := tw.WriteHeader(&tar.Header{Name: file.Path, Mode: 0600, Size: file.Size, ModTime: manifest.CreatedAt, Typeflag: tar.TypeReg})
+		if err != nil {
+			return err
+		}
+		staged, err := os.Open(filepath.Join(dir, filepath.FromSlash(file.Path)))
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(tw, staged)
+		_ = staged.Close()
+		if err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, archive)
+}
+
+// readBackupArchive reads the manifest of a backup archive and calls fn for every other file in the archive.
+func readBackupArchive(archive string, fn func(manifest *backupManifest, name string, r io.Reader) error) (*backupManifest, error) {
+	f, err := os.Open(archive)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	zr, err := zstd.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	tr := tar.NewReader(zr)
+	hdr, err := tr.Next()
+	if err != nil {
+		return nil, fmt.Errorf("invalid backup archive %v: %v", archive, err)
+	}
+	if hdr.Name != backupManifestFile {
+		return nil, fmt.Errorf("invalid backup archive %v: %v is not the first file", archive, backupManifestFile)
+	}
+	decoder := json.NewDecoder(tr)
+	decoder.UseNumber()
+	var manifest backupManifest
+	if err := decoder.Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("invalid backup manifest: %v", err)
+	}
+	if manifest.Version < 1 || manifest.Version > backupManifestVersion {
+		return nil, fmt.Errorf("unsupported backup archive version %v. upgrade the chroma CLI", manifest.Version)
+	}
+	var mapping recordMapping
+	for i := range manifest.Collections {
+		for key, value := range manifest.Collections[i].Metadata {
+			manifest.Collections[i].Metadata[key] = mapping.metadataValue(value)
+		}
+	}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return &manifest, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid backup archive %v: %v", archive, err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := fn(&manifest, path.Clean(hdr.Name), tr); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// verifyBackupArchive checks the size and checksum of every file listed in the manifest of a backup archive.
+func verifyBackupArchive(archive string) (*backupManifest, error) {
+	var verified = make(map[string]bool)
+	manifest, err := readBackupArchive(archive, func(manifest *backupManifest, name string, r io.Reader) error {
+		file := manifest.file(name)
+		if file == nil {
+			return fmt.Errorf("backup archive contains file %v that is not in the manifest", name)
+		}
+		hash := sha256.New()
+		size, err := io.Copy(hash, r)
+		if err != nil {
+			return err
+		}
+		if size != file.Size || hex.EncodeToString(hash.Sum(nil)) != file.SHA256 {
+			return fmt.Errorf("checksum mismatch for %v", name)
+		}
+		verified[name] = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range manifest.Files {
+		if !verified[file.Path] {
+			return nil, fmt.Errorf("backup archive is missing file %v", file.Path)
+		}
+	}
+	return manifest, nil
+}
+
+// backupResultItem is the structured representation of a finished backup.
+type backupResultItem struct {
+	ID          string `json:"id" yaml:"id"`
+	Type        string `json:"type" yaml:"type"`
+	Base        string `json:"base,omitempty" yaml:"base,omitempty"`
+	File        string `json:"file" yaml:"file"`
+	Collections int    `json:"collections" yaml:"collections"`
+	Records     int    `json:"records" yaml:"records"`
+	Deleted     int    `json:"deleted" yaml:"deleted"`
+	Size        int64  `json:"size" yaml:"size"`
+}
+
+// getBackupClients returns a client for every database included in a backup: the database of every given tenant, or
+// all databases of every tenant with --all-databases. Without tenants the active tenant or the server default is used.
+func getBackupClients(cmd *cobra.Command, alias string, tenants []string) ([]*chroma.Client, error) {
+	allDatabases, err := cmd.Flags().GetBool("all-databases")
+	if err != nil {
+		return nil, err
+	}
+	var database string
+	if f := cmd.Flag("database"); f.Changed {
+		database = f.Value.String()
+	}
+	if len(tenants) == 0 {
+		tenants = []string{""}
+	}
+	var clients = make([]*chroma.Client, 0, len(tenants))
+	for _, tenant := range tenants {
+		client, err := getClient(alias, tenant, database)
+		if err != nil {
+			return nil, err
+		}
+		if !allDatabases {
+			clients = append(clients, client)
+			continue
+		}
+		databases, err := listDatabases(context.TODO(), client, client.Tenant)
+		if err != nil {
+			return nil, err
+		}
+		for _, database := range databases {
+			databaseClient, err := getClient(alias, client.Tenant, database.Name)
+			if err != nil {
+				return nil, err
+			}
+			clients = append(clients, databaseClient)
+		}
+	}
+	return clients, nil
+}
+
+// getBackupBase returns the catalog entry of the base of an incremental backup, the backup given with --base or the
+// newest backup of the same scope.
+func getBackupBase(cmd *cobra.Command, catalog *backupCatalog, scope string) (*backupCatalogEntry, error) {
+	baseID, err := cmd.Flags().GetString("base")
+	if err != nil {
+		return nil, err
+	}
+	if baseID != "" {
+		base := catalog.find(baseID)
+		if base == nil {
+			return nil, fmt.Errorf("backup %v not found in the catalog", baseID)
+		}
+		if base.scope() != scope {
+			return nil, fmt.Errorf("backup %v is a backup of %v, not of %v", baseID, base.scope(), scope)
+		}
+		return base, nil
+	}
+	base := catalog.latest(scope)
+	if base == nil {
+		return nil, fmt.Errorf("no backup of %v found to base the incremental backup on. create a full backup first", scope)
+	}
+	return base, nil
+}
+
+func backupCollections(cmd *cobra.Command, _ []string) error {
+	file, err := cmd.Flags().GetString("file")
+	if err != nil {
+		return err
+	}
+	batchSize, err := cmd.Flags().GetInt("batch-size")
+	if err != nil {
+		return err
+	}
+	if batchSize <= 0 {
+		err := fmt.Errorf("batch-size must be greater than 0")
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	names, err := cmd.Flags().GetStringSlice("collection")
+	if err != nil {
+		return err
+	}
+	incremental, err := cmd.Flags().GetBool("incremental")
+	if err != nil {
+		return err
+	}
+	policy, err := getRetentionPolicyFromFlags(cmd)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	alias, err := cmd.Flags().GetString("alias")
+	if err != nil {
+		return err
+	}
+	if alias == "" {
+		alias = viper.GetString("active_server")
+	}
+	tenants, err := cmd.Flags().GetStringSlice("tenant")
+	if err != nil {
+		return err
+	}
+	clients, err := getBackupClients(cmd, alias, tenants)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	if len(clients) == 0 {
+		err := fmt.Errorf("no databases to back up")
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	client := clients[0]
+	var backupTenants = make([]string, 0, len(tenants))
+	for _, c := range clients {
+		if !slices.Contains(backupTenants, c.Tenant) {
+			backupTenants = append(backupTenants, c.Tenant)
+		}
+	}
+	catalog, err := loadBackupCatalog()
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	createdAt := time.Now().UTC()
+	var entry = backupCatalogEntry{
+		ID:        createdAt.Format("20060102T150405Z") + "-" + strings.ToLower(ulid.Make().String()[20:]),
+		Type:      backupTypeFull,
+		CreatedAt: createdAt,
+		Server:    alias,
+		Tenant:    strings.Join(backupTenants, ","),
+		Database:  client.Database,
+	}
+	if allDatabases, _ := cmd.Flags().GetBool("all-databases"); allDatabases {
+		entry.Database = "*"
+	}
+	for _, name := range names {
+		if !slices.Contains(entry.Selection, name) {
+			entry.Selection = append(entry.Selection, name)
+		}
+	}
+	sort.Strings(entry.Selection)
+	var manifest = &backupManifest{
+		Version:     backupManifestVersion,
+		ID:          entry.ID,
+		Type:        backupTypeFull,
+		CreatedAt:   createdAt,
+		Server:      alias,
+		URL:         client.ApiClient.GetConfig().Servers[0].URL,
+		Tenant:      entry.Tenant,
+		Database:    entry.Database,
+		Collections: make([]backupCollection, 0),
+		Files:       make([]backupFile, 0),
+	}
+	var baseHashes map[string]map[string]string
+	if incremental {
+		base, err := getBackupBase(cmd, catalog, entry.scope())
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			return err
+		}
+		if _, baseHashes, err = readBackupHashes(base.File); err != nil {
+			err = fmt.Errorf("cannot read base backup %v: %v", base.ID, err)
+			cmd.Printf("%v\n", err)
+			return err
+		}
+		entry.Type, entry.Base = backupTypeIncremental, base.ID
+		manifest.Type, manifest.Base, manifest.BaseFile = backupTypeIncremental, base.ID, filepath.Base(base.File)
+	}
+	if file == "" {
+		dir, err := backupDir()
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			return err
+		}
+		file = filepath.Join(dir, fmt.Sprintf("%v-%v.tar.zst", alias, entry.ID))
+	}
+	if entry.File, err = filepath.Abs(file); err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	dir, err := os.MkdirTemp("", "chroma-backup-")
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	defer os.RemoveAll(dir)
+	var selected = make(map[string]bool)
+	for _, name := range names {
+		selected[name] = false
+	}
+	var deleted int
+	ctx := context.TODO()
+	for _, scopedClient := range clients {
+		collections, err := listScopedCollections(ctx, scopedClient)
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			return err
+		}
+		sort.Slice(collections, func(i, j int) bool { return collections[i].Name < collections[j].Name })
+		for _, col := range collections {
+			if _, ok := selected[col.Name]; len(names) > 0 && !ok {
+				continue
+			}
+			selected[col.Name] = true
+			c := backupCollection{
+				Tenant:   scopedClient.Tenant,
+				Database: scopedClient.Database,
+				Name:     col.Name,
+				ID:       col.ID,
+				Metadata: col.Metadata,
+				File:     fmt.Sprintf("collections/%04d/records.jsonl", len(manifest.Collections)),
+			}
+			var base map[string]string
+			if incremental {
+				// a collection that is not in the base backup is backed up in full
+				if base = baseHashes[c.key()]; base == nil {
+					base = make(map[string]string)
+				}
+			}
+			files, err := writeCollectionBackup(ctx, col, dir, &c, base, int32(batchSize))
+			if err != nil {
+				err = fmt.Errorf("failed to back up %v: %v", c.ref(), err)
+				cmd.Printf("%v\n", err)
+				return err
+			}
+			entry.Records += c.Records
+			deleted += c.Deleted
+			manifest.Collections = append(manifest.Collections, c)
+			manifest.Files = append(manifest.Files, files...)
+			if incremental {
+				fmt.Fprintf(cmd.ErrOrStderr(), "backed up %v: %v changed and %v deleted of %v records\n", c.ref(), c.Records, c.Deleted, c.Total)
+			} else {
+				fmt.Fprintf(cmd.ErrOrStderr(), "backed up %v: %v records\n", c.ref(), c.Records)
+			}
+		}
+	}
+	for _, name := range names {
+		if !selected[name] {
+			err := fmt.Errorf("collection %v not found", name)
+			cmd.Printf("%v\n", err)
+			return err
+		}
+	}
+	if err := writeBackupArchive(file, manifest, dir); err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	entry.Collections = len(manifest.Collections)
+	entry.Size = info.Size()
+	catalog.Backups = append(catalog.Backups, entry)
+	if err := catalog.save(); err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	if policy.KeepDaily > 0 || policy.KeepWeekly > 0 {
+		if err := pruneBackups(cmd, catalog, policy, entry.scope(), false); err != nil {
+			cmd.Printf("%v\n", err)
+			return err
+		}
+	}
+	var message = fmt.Sprintf("Backed up %v collections (%v records) to %v", entry.Collections, entry.Records, file)
+	if incremental {
+		message = fmt.Sprintf("Backed up %v changed and %v deleted records of %v collections to %v (base %v)", entry.Records, deleted, entry.Collections, file, entry.Base)
+	}
+	return printMessage(cmd, message, backupResultItem{
+		ID:          entry.ID,
+		Type:        entry.Type,
+		Base:        entry.Base,
+		File:        file,
+		Collections: entry.Collections,
+		Records:     entry.Records,
+		Deleted:     deleted,
+		Size:        entry.Size,
+	})
+}
+
+var BackupCommand = &cobra.Command{
+	Use:   "backup",
+	Short: "Back up the collections of databases to an archive",
+	Long: `Back up the collections of a database, or of all databases of a tenant with --all-databases, to a single zstd
+compressed tar archive. The archive contains a manifest with the collection metadata and HNSW configuration and the
+SHA-256 checksum of every file, the records of every collection as JSONL including the embeddings and the content hash
+of every record. Chroma does not list tenants, so a whole server is backed up by repeating --tenant for every tenant
+together with --all-databases.
+
+Backups are stored in ~/.chroma/backups unless --file is given and are recorded in the catalog listed by chroma backup
+ls. An --incremental backup only stores the records whose content changed since the base backup, by default the newest
+backup of the same server, tenant and database, and the ids of the deleted records. --keep-daily and --keep-weekly prune
+old backups of the same scope after the backup, see chroma backup prune.`,
+	Example: `  chroma backup
+  chroma backup --incremental --keep-daily 7 --keep-weekly 4
+  chroma backup -s prod -t acme --all-databases
+  chroma backup -s prod -t default_tenant -t acme -t globex --all-databases
+  chroma backup -c my-collection -c other-collection -f collections.tar.zst`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := backupCollections(cmd, args)
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	BackupCommand.Flags().StringP("alias", "s", "", "Server alias name. If not provided, the active server will be used.")
+	BackupCommand.Flags().StringSliceP("tenant", "t", []string{}, "Tenant name. Can be repeated to back up several tenants to one archive. If not provided, the active tenant or the server default will be used.")
+	BackupCommand.Flags().StringP("database", "d", "", "Database name. If not provided, the active database or the server default will be used.")
+	BackupCommand.Flags().Bool("all-databases", false, "Back up all databases of the tenants")
+	BackupCommand.Flags().StringSliceP("collection", "c", []string{}, "Back up only the given collections. Can be repeated.")
+	BackupCommand.Flags().StringP("file", "f", "", "Archive file. Defaults to ~/.chroma/backups/<alias>-<id>.tar.zst")
+	BackupCommand.Flags().IntP("batch-size", "b", 1000, "Number of records read from a collection per request")
+	BackupCommand.Flags().BoolP("incremental", "i", false, "Only back up the records that changed since the base backup")
+	BackupCommand.Flags().String("base", "", "Id of the base backup of an incremental backup. Defaults to the newest backup of the same scope.")
+	addRetentionFlags(BackupCommand)
+	RootCmd.AddCommand(BackupCommand)
+}
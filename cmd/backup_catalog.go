@@ -0,0 +1,297 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const backupCatalogFile = "catalog.json"
+
+// backupCatalogEntry is a backup recorded in the catalog.
+type backupCatalogEntry struct {
+	ID          string    `json:"id" yaml:"id"`
+	Type        string    `json:"type" yaml:"type"`
+	Base        string    `json:"base,omitempty" yaml:"base,omitempty"`
+	File        string    `json:"file" yaml:"file"`
+	CreatedAt   time.Time `json:"created_at" yaml:"created_at"`
+	Server      string    `json:"server" yaml:"server"`
+	Tenant      string    `json:"tenant" yaml:"tenant"`
+	Database    string    `json:"database" yaml:"database"`
+	Selection   []string  `json:"selection,omitempty" yaml:"selection,omitempty"`
+	Collections int       `json:"collections" yaml:"collections"`
+	Records     int       `json:"records" yaml:"records"`
+	Size        int64     `json:"size" yaml:"size"`
+}
+
+// scope identifies what was backed up. Incremental backups and retention only consider backups of the same scope.
+// Backups of selected collections are scoped to the selection.
+func (e backupCatalogEntry) scope() string {
+	var scope = e.Server + "/" + e.Tenant + "/" + e.Database
+	if len(e.Selection) > 0 {
+		scope += "/" + strings.Join(e.Selection, ",")
+	}
+	return scope
+}
+
+// backupCatalog is the list of backups created with chroma backup, stored in ~/.chroma/backups/catalog.json.
+type backupCatalog struct {
+	Backups []backupCatalogEntry `json:"backups"`
+
+	path string
+}
+
+func backupDir() (string, error) {
+	return getChromaDir("backups")
+}
+
+func loadBackupCatalog() (*backupCatalog, error) {
+	dir, err := backupDir()
+	if err != nil {
+		return nil, err
+	}
+	var catalog = &backupCatalog{Backups: make([]backupCatalogEntry, 0), path: filepath.Join(dir, backupCatalogFile)}
+	data, err := os.ReadFile(catalog.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return catalog, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, catalog); err != nil {
+		return nil, fmt.Errorf("invalid backup catalog %v: %v", catalog.path, err)
+	}
+	return catalog, nil
+}
+
+// save atomically replaces the catalog.
+func (c *backupCatalog) save() error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path)
+}
+
+func (c *backupCatalog) find(id string) *backupCatalogEntry {
+	for i := range c.Backups {
+		if c.Backups[i].ID == id {
+			return &c.Backups[i]
+		}
+	}
+	return nil
+}
+
+// latest returns the newest backup of a scope or nil. Of backups created at the same time the last added one wins.
+func (c *backupCatalog) latest(scope string) *backupCatalogEntry {
+	var latest *backupCatalogEntry
+	for i := range c.Backups {
+		if c.Backups[i].scope() == scope && (latest == nil || !c.Backups[i].CreatedAt.Before(latest.CreatedAt)) {
+			latest = &c.Backups[i]
+		}
+	}
+	return latest
+}
+
+// retentionPolicy keeps the newest backup of each of the last KeepDaily days and KeepWeekly weeks that have backups.
+type retentionPolicy struct {
+	KeepDaily  int
+	KeepWeekly int
+}
+
+// expired returns the backups the policy does not keep. The policy is applied per scope and backups that are the
+// base of a kept incremental backup are kept as well.
+func (p retentionPolicy) expired(backups []backupCatalogEntry) []backupCatalogEntry {
+	var scopes = make(map[string][]backupCatalogEntry)
+	for i := len(backups) - 1; i >= 0; i-- {
+		scopes[backups[i].scope()] = append(scopes[backups[i].scope()], backups[i])
+	}
+	var keep = make(map[string]bool)
+	for _, entries := range scopes {
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].CreatedAt.After(entries[j].CreatedAt) })
+		var days = make(map[string]bool)
+		var weeks = make(map[string]bool)
+		for _, entry := range entries {
+			local := entry.CreatedAt.Local()
+			day := local.Format("2006-01-02")
+			if !days[day] && len(days) < p.KeepDaily {
+				days[day] = true
+				keep[entry.ID] = true
+			}
+			year, week := local.ISOWeek()
+			weekKey := strconv.Itoa(year) + "-" + strconv.Itoa(week)
+			if !weeks[weekKey] && len(weeks) < p.KeepWeekly {
+				weeks[weekKey] = true
+				keep[entry.ID] = true
+			}
+		}
+	}
+	var byID = make(map[string]backupCatalogEntry, len(backups))
+	for _, backup := range backups {
+		byID[backup.ID] = backup
+	}
+	for id := range keep {
+		for base := byID[id].Base; base != "" && !keep[base]; base = byID[base].Base {
+			keep[base] = true
+		}
+	}
+	var expired = make([]backupCatalogEntry, 0)
+	for _, backup := range backups {
+		if !keep[backup.ID] {
+			expired = append(expired, backup)
+		}
+	}
+	return expired
+}
+
+func getRetentionPolicyFromFlags(cmd *cobra.Command) (retentionPolicy, error) {
+	var policy retentionPolicy
+	var err error
+	if policy.KeepDaily, err = cmd.Flags().GetInt("keep-daily"); err != nil {
+		return policy, err
+	}
+	if policy.KeepWeekly, err = cmd.Flags().GetInt("keep-weekly"); err != nil {
+		return policy, err
+	}
+	if policy.KeepDaily < 0 || policy.KeepWeekly < 0 {
+		return policy, fmt.Errorf("keep-daily and keep-weekly must not be negative")
+	}
+	return policy, nil
+}
+
+// pruneBackups deletes the archives of the expired backups and removes them from the catalog. If scope is not empty
+// only the backups of that scope are pruned.
+func pruneBackups(cmd *cobra.Command, catalog *backupCatalog, policy retentionPolicy, scope string, dryRun bool) error {
+	var backups = catalog.Backups
+	if scope != "" {
+		backups = make([]backupCatalogEntry, 0, len(catalog.Backups))
+		for _, backup := range catalog.Backups {
+			if backup.scope() == scope {
+				backups = append(backups, backup)
+			}
+		}
+	}
+	expired := policy.expired(backups)
+	var deleted = make(map[string]bool, len(expired))
+	for _, backup := range expired {
+		if dryRun {
+			cmd.Printf("would delete backup %v (%v)\n", backup.ID, backup.File)
+			continue
+		}
+		if err := os.Remove(backup.File); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		deleted[backup.ID] = true
+		cmd.Printf("deleted backup %v (%v)\n", backup.ID, backup.File)
+	}
+	if len(deleted) == 0 {
+		return nil
+	}
+	var kept = make([]backupCatalogEntry, 0, len(catalog.Backups)-len(deleted))
+	for _, backup := range catalog.Backups {
+		if !deleted[backup.ID] {
+			kept = append(kept, backup)
+		}
+	}
+	catalog.Backups = kept
+	return catalog.save()
+}
+
+func addRetentionFlags(command *cobra.Command) {
+	command.Flags().Int("keep-daily", 0, "Keep the newest backup of each of the last N days with backups")
+	command.Flags().Int("keep-weekly", 0, "Keep the newest backup of each of the last N weeks with backups")
+}
+
+var ListBackupsCommand = &cobra.Command{
+	Use:     "ls",
+	Aliases: []string{"list"},
+	Short:   "List the backups in the catalog",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		catalog, err := loadBackupCatalog()
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		backups := catalog.Backups
+		sort.SliceStable(backups, func(i, j int) bool { return backups[i].CreatedAt.Before(backups[j].CreatedAt) })
+		var rows = make([][]string, 0, len(backups))
+		for _, b := range backups {
+			rows = append(rows, []string{b.ID, b.Type, b.CreatedAt.Local().Format(time.DateTime), b.scope(), strconv.Itoa(b.Collections), strconv.Itoa(b.Records), formatSize(b.Size), b.Base, b.File})
+		}
+		err = printOutput(cmd, &tableOutput{
+			Headers:     []string{"ID", "TYPE", "CREATED", "SCOPE", "COLLECTIONS", "RECORDS", "SIZE"},
+			WideHeaders: []string{"BASE", "FILE"},
+			Rows:        rows,
+			Items:       backups,
+		})
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var PruneBackupsCommand = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete the backups not kept by the retention policy",
+	Long: `Delete the catalogued backups that are not kept by --keep-daily and --keep-weekly. The policy is applied to the
+backups of each server, tenant, database and collection selection separately. Backups that are the base of a kept incremental backup are
+kept as well.`,
+	Example: `  chroma backup prune --keep-daily 7 --keep-weekly 4 --dry-run`,
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		policy, err := getRetentionPolicyFromFlags(cmd)
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		if policy.KeepDaily == 0 && policy.KeepWeekly == 0 {
+			cmd.Printf("at least one of --keep-daily or --keep-weekly is required\n")
+			os.Exit(1)
+		}
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		catalog, err := loadBackupCatalog()
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		if err := pruneBackups(cmd, catalog, policy, "", dryRun); err != nil {
+			cmd.Printf("%v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// formatSize formats a number of bytes in binary units.
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%v B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+func init() {
+	addRetentionFlags(PruneBackupsCommand)
+	PruneBackupsCommand.Flags().Bool("dry-run", false, "Show the backups that would be deleted")
+	BackupCommand.AddCommand(ListBackupsCommand)
+	BackupCommand.AddCommand(PruneBackupsCommand)
+}
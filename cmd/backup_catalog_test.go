@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetentionPolicy(t *testing.T) {
+	day := func(d int, hour int) time.Time {
+		return time.Date(2024, 3, d, hour, 0, 0, 0, time.Local)
+	}
+	var backups = []backupCatalogEntry{
+		{ID: "full-1", Type: backupTypeFull, CreatedAt: day(1, 1), Server: "local"},
+		{ID: "inc-4", Type: backupTypeIncremental, Base: "full-1", CreatedAt: day(4, 1), Server: "local"},
+		{ID: "full-11", Type: backupTypeFull, CreatedAt: day(11, 1), Server: "local"},
+		{ID: "inc-12-a", Type: backupTypeIncremental, Base: "full-11", CreatedAt: day(12, 1), Server: "local"},
+		{ID: "inc-12-b", Type: backupTypeIncremental, Base: "inc-12-a", CreatedAt: day(12, 2), Server: "local"},
+		{ID: "inc-13", Type: backupTypeIncremental, Base: "inc-12-b", CreatedAt: day(13, 1), Server: "local"},
+		{ID: "other-1", Type: backupTypeFull, CreatedAt: day(1, 1), Server: "prod"},
+	}
+	ids := func(entries []backupCatalogEntry) []string {
+		var result = make([]string, 0, len(entries))
+		for _, entry := range entries {
+			result = append(result, entry.ID)
+		}
+		return result
+	}
+
+	t.Run("Daily keeps the newest backup of a day and its bases", func(t *testing.T) {
+		expired := retentionPolicy{KeepDaily: 2}.expired(backups)
+		require.ElementsMatch(t, []string{"full-1", "inc-4"}, ids(expired))
+	})
+
+	t.Run("Weekly keeps the bases of kept backups", func(t *testing.T) {
+		// March 1 and 4 2024 are in different ISO weeks, March 11 to 13 in a third one
+		expired := retentionPolicy{KeepWeekly: 2}.expired(backups)
+		require.Empty(t, expired)
+		expired = retentionPolicy{KeepWeekly: 1}.expired(backups)
+		require.ElementsMatch(t, []string{"full-1", "inc-4"}, ids(expired))
+	})
+
+	t.Run("Scopes are pruned separately", func(t *testing.T) {
+		expired := retentionPolicy{KeepDaily: 1}.expired(backups)
+		require.NotContains(t, ids(expired), "other-1")
+	})
+}
+
+func TestPruneBackupsOfScope(t *testing.T) {
+	dir := t.TempDir()
+	day := func(d int) time.Time {
+		return time.Date(2024, 3, d, 1, 0, 0, 0, time.Local)
+	}
+	var catalog = &backupCatalog{path: filepath.Join(dir, backupCatalogFile)}
+	for _, b := range []struct {
+		id         string
+		collection string
+		day        int
+	}{{"a-1", "a", 1}, {"a-2", "a", 2}, {"a-3", "a", 3}, {"b-1", "b", 1}, {"b-2", "b", 2}} {
+		file := filepath.Join(dir, b.id+".tar.zst")
+		require.NoError(t, os.WriteFile(file, []byte(b.id), 0600))
+		catalog.Backups = append(catalog.Backups, backupCatalogEntry{
+			ID: b.id, Type: backupTypeFull, File: file, CreatedAt: day(b.day), Server: "local", Tenant: "t", Database: "d",
+			Selection: []string{b.collection},
+		})
+	}
+	scope := catalog.find("a-3").scope()
+	require.NotEqual(t, scope, catalog.find("b-2").scope())
+	cmd := &cobra.Command{}
+	cmd.SetOut(io.Discard)
+
+	require.NoError(t, pruneBackups(cmd, catalog, retentionPolicy{KeepDaily: 1}, scope, false))
+	ids := make([]string, 0, len(catalog.Backups))
+	for _, backup := range catalog.Backups {
+		ids = append(ids, backup.ID)
+	}
+	require.ElementsMatch(t, []string{"a-3", "b-1", "b-2"}, ids)
+	for _, id := range []string{"a-1", "a-2"} {
+		require.NoFileExists(t, filepath.Join(dir, id+".tar.zst"))
+	}
+	for _, id := range []string{"a-3", "b-1", "b-2"} {
+		require.FileExists(t, filepath.Join(dir, id+".tar.zst"))
+	}
+
+	require.NoError(t, pruneBackups(cmd, catalog, retentionPolicy{KeepDaily: 1}, "", false))
+	require.Len(t, catalog.Backups, 2)
+	require.NoFileExists(t, filepath.Join(dir, "b-1.tar.zst"))
+}
+
+func TestFormatSize(t *testing.T) {
+	require.Equal(t, "512 B", formatSize(512))
+	require.Equal(t, "1.5 KiB", formatSize(1536))
+	require.Equal(t, "2.0 MiB", formatSize(2*1024*1024))
+}
@@ -0,0 +1,280 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/amikos-tech/chroma-go/collection"
+	"github.com/amikos-tech/chroma-go/types"
+	"github.com/stretchr/testify/require"
+)
+
+func helperBackupArchive(t *testing.T, manifest *backupManifest, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	}
+	archive := filepath.Join(t.TempDir(), "backup.tar.zst")
+	require.NoError(t, writeBackupArchive(archive, manifest, dir))
+	return archive
+}
+
+// helperCleanBackupCatalog removes the backups a test adds to the catalog when the test ends.
+func helperCleanBackupCatalog(t *testing.T) {
+	catalog, err := loadBackupCatalog()
+	require.NoError(t, err)
+	var existing = make(map[string]bool)
+	for _, backup := range catalog.Backups {
+		existing[backup.ID] = true
+	}
+	t.Cleanup(func() {
+		catalog, err := loadBackupCatalog()
+		require.NoError(t, err)
+		var backups = make([]backupCatalogEntry, 0)
+		for _, backup := range catalog.Backups {
+			if existing[backup.ID] {
+				backups = append(backups, backup)
+			}
+		}
+		catalog.Backups = backups
+		require.NoError(t, catalog.save())
+	})
+}
+
+func sha256File(t *testing.T, path string) string {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestBackupArchive(t *testing.T) {
+	content := `{"id":"1","document":"hello"}` + "\n"
+	file := backupFile{Path: "collections/0000/records.jsonl", Size: int64(len(content)), SHA256: "0000"}
+	manifest := func(file backupFile) *backupManifest {
+		return &backupManifest{
+			Version:     backupManifestVersion,
+			CreatedAt:   time.Now().UTC(),
+			Collections: []backupCollection{{Tenant: "t", Database: "d", Name: "c", Records: 1, File: file.Path, Metadata: map[string]interface{}{"hnsw:M": 16}}},
+			Files:       []backupFile{file},
+		}
+	}
+
+	t.Run("Checksum mismatch", func(t *testing.T) {
+		archive := helperBackupArchive(t, manifest(file), map[string]string{file.Path: content})
+		_, err := verifyBackupArchive(archive)
+		require.ErrorContains(t, err, "checksum mismatch")
+	})
+
+	t.Run("Valid archive", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "records.jsonl")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+		valid := file
+		valid.SHA256 = sha256File(t, path)
+		archive := helperBackupArchive(t, manifest(valid), map[string]string{valid.Path: content})
+		m, err := verifyBackupArchive(archive)
+		require.NoError(t, err)
+		require.Len(t, m.Collections, 1)
+		require.Equal(t, int64(16), m.Collections[0].Metadata["hnsw:M"])
+	})
+
+	t.Run("Unsupported version", func(t *testing.T) {
+		m := manifest(file)
+		m.Version = backupManifestVersion + 1
+		archive := helperBackupArchive(t, m, map[string]string{file.Path: content})
+		_, err := verifyBackupArchive(archive)
+		require.ErrorContains(t, err, "unsupported backup archive version")
+	})
+}
+
+func TestBackupRestore(t *testing.T) {
+	command := RootCmd
+	defer resetCommandFlags(BackupCommand)
+	defer resetCommandFlags(RestoreCommand)
+	helperCleanBackupCatalog(t)
+	client := setup()
+	defer tearDown(client)
+	var first = getRandomName("backup-first")
+	var second = getRandomName("backup-second")
+	helperCreateCollection(t, client, first)
+	helperCreateCollection(t, client, second)
+	addDummyRecordsToCollection(t, client, first, 12)
+	addDummyRecordsToCollection(t, client, second, 3)
+	archive := filepath.Join(t.TempDir(), "backup.tar.zst")
+	buf := new(bytes.Buffer)
+	command.SetOut(buf)
+	command.SetErr(buf)
+
+	command.SetArgs([]string{"backup", "-f", archive, "-b", "5"})
+	_, err := command.ExecuteC()
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), "Backed up 2 collections (15 records)")
+	manifest, err := verifyBackupArchive(archive)
+	require.NoError(t, err)
+	require.Len(t, manifest.Collections, 2)
+
+	t.Run("Dry run", func(t *testing.T) {
+		resetCommandFlags(RestoreCommand)
+		buf.Reset()
+		command.SetArgs([]string{"restore", archive, "--dry-run", "--rename", second + "=" + second + "-copy"})
+		_, err := command.ExecuteC()
+		require.NoError(t, err)
+		require.Contains(t, buf.String(), restoreConflict)
+		require.Contains(t, buf.String(), restoreCreate)
+		require.Contains(t, buf.String(), "dry run: nothing was restored")
+		exists, err := collectionExists(client, second+"-copy")
+		require.NoError(t, err)
+		require.False(t, exists)
+	})
+
+	t.Run("Conflicting collections", func(t *testing.T) {
+		resetCommandFlags(RestoreCommand)
+		require.NoError(t, RestoreCommand.ParseFlags([]string{}))
+		err := restoreBackup(RestoreCommand, []string{archive})
+		require.ErrorContains(t, err, "already exist")
+	})
+
+	t.Run("Restore with rename", func(t *testing.T) {
+		resetCommandFlags(RestoreCommand)
+		buf.Reset()
+		command.SetArgs([]string{"restore", archive, "-c", first, "--rename", first + "=" + first + "-restored", "-b", "5"})
+		_, err := command.ExecuteC()
+		require.NoError(t, err)
+		require.Contains(t, buf.String(), "12 records")
+		source, err := getCollection(client, first)
+		require.NoError(t, err)
+		restored, err := getCollection(client, first+"-restored")
+		require.NoError(t, err)
+		diff, err := diffCollections(context.TODO(), source, restored, diffOptions{BatchSize: 100, CompareEmbeddings: true})
+		require.NoError(t, err)
+		require.True(t, diff.identical(), diff.summary())
+	})
+
+	t.Run("Overwrite", func(t *testing.T) {
+		resetCommandFlags(RestoreCommand)
+		buf.Reset()
+		command.SetArgs([]string{"restore", archive, "-c", second, "--overwrite", "-o", "json"})
+		defer resetCommandFlags(RootCmd)
+		_, err := command.ExecuteC()
+		require.NoError(t, err)
+		require.Contains(t, buf.String(), `"action": "overwrite"`)
+		col, err := getCollection(client, second)
+		require.NoError(t, err)
+		count, err := col.Count(context.TODO())
+		require.NoError(t, err)
+		require.Equal(t, int32(3), count)
+	})
+}
+
+func TestBackupTenants(t *testing.T) {
+	command := RootCmd
+	defer resetCommandFlags(BackupCommand)
+	helperCleanBackupCatalog(t)
+	client := setup()
+	defer tearDown(client)
+	var tenantName = getRandomName("backup-tenant")
+	var dbName = getRandomName("backup-db")
+	helperCreateTenant(t, client, tenantName)
+	_, err := client.CreateDatabase(context.TODO(), dbName, &tenantName)
+	require.NoError(t, err)
+	var first = getRandomName("backup-first")
+	var second = getRandomName("backup-second")
+	helperCreateCollection(t, client, first)
+	addDummyRecordsToCollection(t, client, first, 4)
+	tenantClient, err := getClient("", tenantName, dbName)
+	require.NoError(t, err)
+	_, err = createScopedCollection(context.TODO(), tenantClient, collection.WithName(second))
+	require.NoError(t, err)
+	archive := filepath.Join(t.TempDir(), "backup.tar.zst")
+	buf := new(bytes.Buffer)
+	command.SetOut(buf)
+	command.SetErr(buf)
+
+	command.SetArgs([]string{"backup", "-f", archive, "-t", DefaultTenant, "-t", tenantName, "--all-databases"})
+	_, err = command.ExecuteC()
+	require.NoError(t, err)
+	manifest, err := verifyBackupArchive(archive)
+	require.NoError(t, err)
+	require.Equal(t, DefaultTenant+","+tenantName, manifest.Tenant)
+	var refs = make([]string, 0, len(manifest.Collections))
+	for _, c := range manifest.Collections {
+		refs = append(refs, c.Tenant+"/"+c.Database+"/"+c.Name)
+	}
+	require.Contains(t, refs, DefaultTenant+"/"+DefaultDatabase+"/"+first)
+	require.Contains(t, refs, tenantName+"/"+dbName+"/"+second)
+}
+
+func TestIncrementalBackup(t *testing.T) {
+	command := RootCmd
+	defer resetCommandFlags(RootCmd)
+	defer resetCommandFlags(BackupCommand)
+	defer resetCommandFlags(RestoreCommand)
+	helperCleanBackupCatalog(t)
+	client := setup()
+	defer tearDown(client)
+	var collectionName = getRandomName("backup-incremental")
+	helperCreateCollection(t, client, collectionName)
+	addDummyRecordsToCollection(t, client, collectionName, 10)
+	dir := t.TempDir()
+	buf := new(bytes.Buffer)
+	command.SetOut(buf)
+	command.SetErr(new(bytes.Buffer))
+
+	command.SetArgs([]string{"backup", "-c", collectionName, "-f", filepath.Join(dir, "full.tar.zst"), "-o", "json"})
+	_, err := command.ExecuteC()
+	require.NoError(t, err)
+	var full backupResultItem
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &full))
+	require.Equal(t, backupTypeFull, full.Type)
+
+	col, err := getCollection(client, collectionName)
+	require.NoError(t, err)
+	result, err := col.GetWithOptions(context.TODO(), types.WithIds([]string{"id-1"}), types.WithLimit(1), types.WithInclude(types.IDocuments, types.IEmbeddings))
+	require.NoError(t, err)
+	changed := recordItemsFromResult(result)
+	changed[0].Metadata = map[string]interface{}{"changed": true}
+	changed = append(changed, recordItem{ID: "id-new", Embedding: changed[0].Embedding})
+	require.NoError(t, upsertRecords(context.TODO(), col, changed))
+	_, err = col.Delete(context.TODO(), []string{"id-2"}, nil, nil)
+	require.NoError(t, err)
+
+	resetCommandFlags(BackupCommand)
+	buf.Reset()
+	incrementalFile := filepath.Join(dir, "incremental.tar.zst")
+	command.SetArgs([]string{"backup", "-c", collectionName, "-i", "--base", full.ID, "-f", incrementalFile, "-o", "json"})
+	_, err = command.ExecuteC()
+	require.NoError(t, err)
+	var incremental backupResultItem
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &incremental))
+	require.Equal(t, backupTypeIncremental, incremental.Type)
+	require.Equal(t, full.ID, incremental.Base)
+	require.Equal(t, 2, incremental.Records)
+	require.Equal(t, 1, incremental.Deleted)
+
+	command.SetArgs([]string{"backup", "ls", "-o", "json"})
+	buf.Reset()
+	_, err = command.ExecuteC()
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), incremental.ID)
+
+	resetCommandFlags(RootCmd)
+	buf.Reset()
+	command.SetArgs([]string{"restore", incrementalFile, "--rename", collectionName + "=" + collectionName + "-restored"})
+	_, err = command.ExecuteC()
+	require.NoError(t, err)
+	restored, err := getCollection(client, collectionName+"-restored")
+	require.NoError(t, err)
+	diff, err := diffCollections(context.TODO(), col, restored, diffOptions{BatchSize: 100, CompareEmbeddings: true})
+	require.NoError(t, err)
+	require.True(t, diff.identical(), diff.summary())
+}
@@ -0,0 +1,302 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	chroma "github.com/amikos-tech/chroma-go"
+	"github.com/amikos-tech/chroma-go/types"
+	"golang.org/x/sync/errgroup"
+)
+
+// cloneState is the checkpoint of a clone. It is written to ~/.chroma/clone after every copied batch so an interrupted
+// clone can be continued with --resume. The ids of the copied records are appended to a log next to the state file.
+type cloneState struct {
+	Source        string    `json:"source"`
+	Destination   string    `json:"destination"`
+	SourceID      string    `json:"source_id"`
+	DestinationID string    `json:"destination_id"`
+	SourceCount   int32     `json:"source_count"`
+	Offset        int       `json:"offset"`
+	Copied        int       `json:"copied"`
+	UpdatedAt     time.Time `json:"updated_at"`
+
+	path string
+}
+
+// cloneJobID identifies a clone by the resolved server, tenant, database and name of both collections, so the same
+// clone is found regardless of how the collections were referenced.
+func cloneJobID(source *chroma.Client, sourceName string, destination *chroma.Client, destinationName string) string {
+	var parts = make([]string, 0, 8)
+	for _, end := range []struct {
+		client *chroma.Client
+		name   string
+	}{{source, sourceName}, {destination, destinationName}} {
+		var url string
+		if servers := end.client.ApiClient.GetConfig().Servers; len(servers) > 0 {
+			url = servers[0].URL
+		}
+		parts = append(parts, url, end.client.Tenant, end.client.Database, end.name)
+	}
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:8])
+}
+
+func cloneStatePath(jobID string) (string, error) {
+	dir, err := getChromaDir("clone")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, jobID+".json"), nil
+}
+
+// loadCloneState returns the checkpoint of a clone or nil if there is none.
+func loadCloneState(jobID string) (*cloneState, error) {
+	path, err := cloneStatePath(jobID)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var state cloneState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("invalid clone checkpoint %v: %v", path, err)
+	}
+	state.path = path
+	return &state, nil
+}
+
+func newCloneState(jobID string, source string, destination string) (*cloneState, error) {
+	path, err := cloneStatePath(jobID)
+	if err != nil {
+		return nil, err
+	}
+	return &cloneState{Source: source, Destination: destination, path: path}, nil
+}
+
+// save atomically replaces the checkpoint.
+func (s *cloneState) save() error {
+	s.UpdatedAt = time.Now().UTC()
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+func (s *cloneState) idsPath() string {
+	return strings.TrimSuffix(s.path, ".json") + ".ids"
+}
+
+// appendCopiedIDs records the ids of a copied batch.
+func (s *cloneState) appendCopiedIDs(ids []string) error {
+	f, err := os.OpenFile(s.idsPath(), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(strings.Join(ids, "\n") + "\n"); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// remove deletes the checkpoint and the copied ids.
+func (s *cloneState) remove() error {
+	for _, path := range []string{s.path, s.idsPath()} {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// retryWithBackoff calls fn until it succeeds, retrying up to retries times with an exponentially growing, jittered
+// delay.
+func retryWithBackoff(ctx context.Context, retries int, delay time.Duration, fn func() error) error {
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if attempt >= retries || ctx.Err() != nil {
+			return err
+		}
+		backoff := delay << attempt
+		if backoff > 0 {
+			backoff += time.Duration(rand.Int63n(int64(backoff)/2 + 1))
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+	}
+}
+
+// cloneOptions controls how records are copied between collections.
+type cloneOptions struct {
+	BatchSize int
+	// Workers is the number of concurrent readers and the number of concurrent writers.
+	Workers int
+	// MaxInflight is the number of read batches that can wait for a writer.
+	MaxInflight int
+	Retries     int
+	RetryDelay  time.Duration
+	// ReEmbed drops the source embeddings so the records are embedded with the destination embedding function.
+	ReEmbed bool
+	// Progress is called with the number of records of every written batch.
+	Progress func(records int)
+}
+
+// cloneBatch is a batch of records read from the source collection.
+type cloneBatch struct {
+	offset  int
+	records []recordItem
+}
+
+// cloneCheckpoint advances the checkpoint of a clone as batches are written. Batches are written out of order, the
+// offset only moves past a batch once all batches before it are written so a resumed clone never skips records. The
+// offset advances by the planned batch size, a batch can have fewer records if records were deleted from the source
+// during the clone.
+type cloneCheckpoint struct {
+	mu        sync.Mutex
+	state     *cloneState
+	batchSize int
+	written   map[int]int
+}
+
+func (c *cloneCheckpoint) done(batch cloneBatch) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var ids = make([]string, 0, len(batch.records))
+	for _, record := range batch.records {
+		ids = append(ids, record.ID)
+	}
+	if err := c.state.appendCopiedIDs(ids); err != nil {
+		return err
+	}
+	c.written[batch.offset] = len(batch.records)
+	for {
+		n, ok := c.written[c.state.Offset]
+		if !ok {
+			break
+		}
+		delete(c.written, c.state.Offset)
+		c.state.Offset = min(c.state.Offset+c.batchSize, int(c.state.SourceCount))
+		c.state.Copied += n
+	}
+	return c.state.save()
+}
+
+// copyCollectionRecords copies the records of the source collection to the destination starting at the checkpointed
+// offset. Readers fetch batches at different offsets concurrently and hand them to the writers over a bounded channel.
+// Records are upserted so a batch that is copied again after an interruption does not fail. Records added to the
+// source after the clone started (beyond state.SourceCount) are not copied.
+func copyCollectionRecords(ctx context.Context, source *chroma.Collection, destination *chroma.Collection, state *cloneState, options cloneOptions) error {
+	if options.BatchSize <= 0 {
+		return fmt.Errorf("clone-batch-size must be greater than 0")
+	}
+	if options.Workers <= 0 {
+		return fmt.Errorf("workers must be greater than 0")
+	}
+	if options.MaxInflight <= 0 {
+		return fmt.Errorf("max-inflight must be greater than 0")
+	}
+	var include = []types.QueryEnum{types.IMetadatas, types.IDocuments}
+	if !options.ReEmbed {
+		include = append(include, types.IEmbeddings)
+	}
+	checkpoint := &cloneCheckpoint{state: state, batchSize: options.BatchSize, written: make(map[int]int)}
+	group, ctx := errgroup.WithContext(ctx)
+	offsets := make(chan int)
+	batches := make(chan cloneBatch, options.MaxInflight)
+
+	group.Go(func() error {
+		defer close(offsets)
+		for offset := state.Offset; offset < int(state.SourceCount); offset += options.BatchSize {
+			select {
+			case offsets <- offset:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+		return nil
+	})
+
+	var readers sync.WaitGroup
+	for i := 0; i < options.Workers; i++ {
+		readers.Add(1)
+		group.Go(func() error {
+			defer readers.Done()
+			for offset := range offsets {
+				var result *chroma.GetResults
+				err := retryWithBackoff(ctx, options.Retries, options.RetryDelay, func() error {
+					var err error
+					result, err = source.GetWithOptions(ctx,
+						types.WithOffset(int32(offset)),
+						types.WithLimit(int32(options.BatchSize)),
+						types.WithInclude(include...),
+					)
+					return err
+				})
+				if err != nil {
+					return fmt.Errorf("failed to read records at offset %v: %v", offset, err)
+				}
+				select {
+				case batches <- cloneBatch{offset: offset, records: recordItemsFromResult(result)}:
+				case <-ctx.Done():
+					return nil
+				}
+			}
+			return nil
+		})
+	}
+	group.Go(func() error {
+		readers.Wait()
+		close(batches)
+		return nil
+	})
+
+	for i := 0; i < options.Workers; i++ {
+		group.Go(func() error {
+			for batch := range batches {
+				if len(batch.records) > 0 {
+					err := retryWithBackoff(ctx, options.Retries, options.RetryDelay, func() error {
+						return upsertRecords(ctx, destination, batch.records)
+					})
+					if err != nil {
+						return fmt.Errorf("failed to write records at offset %v: %v", batch.offset, err)
+					}
+				}
+				if err := checkpoint.done(batch); err != nil {
+					return err
+				}
+				if options.Progress != nil {
+					options.Progress(len(batch.records))
+				}
+			}
+			return nil
+		})
+	}
+	return group.Wait()
+}
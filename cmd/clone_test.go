@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amikos-tech/chroma-go/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryWithBackoff(t *testing.T) {
+	t.Run("Succeeds after transient failures", func(t *testing.T) {
+		var calls int
+		err := retryWithBackoff(context.TODO(), 3, time.Millisecond, func() error {
+			calls++
+			if calls < 3 {
+				return errors.New("transient")
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, 3, calls)
+	})
+
+	t.Run("Gives up after the retries", func(t *testing.T) {
+		var calls int
+		err := retryWithBackoff(context.TODO(), 2, time.Millisecond, func() error {
+			calls++
+			return errors.New("permanent")
+		})
+		require.EqualError(t, err, "permanent")
+		require.Equal(t, 3, calls)
+	})
+}
+
+func TestCloneCheckpoint(t *testing.T) {
+	state := &cloneState{SourceCount: 7, path: filepath.Join(t.TempDir(), "job.json")}
+	checkpoint := &cloneCheckpoint{state: state, batchSize: 2, written: make(map[int]int)}
+	batch := func(offset int, n int) cloneBatch {
+		var records = make([]recordItem, n)
+		for i := range records {
+			records[i].ID = strconv.Itoa(offset + i)
+		}
+		return cloneBatch{offset: offset, records: records}
+	}
+
+	require.NoError(t, checkpoint.done(batch(2, 2)))
+	require.Equal(t, 0, state.Offset, "the offset must not move past an unwritten batch")
+	require.NoError(t, checkpoint.done(batch(6, 1)))
+	require.NoError(t, checkpoint.done(batch(0, 2)))
+	require.Equal(t, 4, state.Offset)
+	require.NoError(t, checkpoint.done(batch(4, 2)))
+	require.Equal(t, 7, state.Offset)
+	require.Equal(t, 7, state.Copied)
+
+	saved, err := os.ReadFile(state.path)
+	require.NoError(t, err)
+	require.Contains(t, string(saved), `"offset": 7`)
+	ids, err := os.ReadFile(state.idsPath())
+	require.NoError(t, err)
+	require.Len(t, strings.Fields(string(ids)), 7)
+
+	t.Run("Short and empty batches", func(t *testing.T) {
+		// records deleted from the source during the clone shorten the batches after them
+		state := &cloneState{SourceCount: 8, path: filepath.Join(t.TempDir(), "job.json")}
+		checkpoint := &cloneCheckpoint{state: state, batchSize: 2, written: make(map[int]int)}
+		require.NoError(t, checkpoint.done(batch(6, 0)))
+		require.NoError(t, checkpoint.done(batch(0, 2)))
+		require.NoError(t, checkpoint.done(batch(2, 1)))
+		require.Equal(t, 4, state.Offset)
+		require.Equal(t, 3, state.Copied)
+		require.NoError(t, checkpoint.done(batch(4, 0)))
+		require.Equal(t, 8, state.Offset)
+		require.Equal(t, 3, state.Copied)
+		ids, err := os.ReadFile(state.idsPath())
+		require.NoError(t, err)
+		require.Len(t, strings.Fields(string(ids)), 3)
+	})
+}
+
+func TestCloneResume(t *testing.T) {
+	command := RootCmd
+
+	t.Run("Clone with small batches", func(t *testing.T) {
+		resetCloneCommandFlags()
+		client := setup()
+		defer tearDown(client)
+		var sourceCollectionName = getRandomName("clone-source")
+		var targetCollectionName = getRandomName("clone-target")
+		helperCreateCollection(t, client, sourceCollectionName)
+		addDummyRecordsToCollection(t, client, sourceCollectionName, 10)
+		buf := new(bytes.Buffer)
+		command.SetOut(buf)
+		command.SetErr(buf)
+		command.SetArgs([]string{"clone", sourceCollectionName, targetCollectionName, "--clone-batch-size", "3"})
+		_, err := command.ExecuteC()
+		require.NoError(t, err)
+		require.Contains(t, buf.String(), "copied records: 10")
+		col := assertCollectionExists(t, client, targetCollectionName)
+		count, err := col.Count(context.TODO())
+		require.NoError(t, err)
+		require.Equal(t, int32(10), count)
+	})
+
+	t.Run("Clone with parallel workers", func(t *testing.T) {
+		resetCloneCommandFlags()
+		client := setup()
+		defer tearDown(client)
+		var sourceCollectionName = getRandomName("clone-source")
+		var targetCollectionName = getRandomName("clone-target")
+		helperCreateCollection(t, client, sourceCollectionName)
+		addDummyRecordsToCollection(t, client, sourceCollectionName, 25)
+		buf := new(bytes.Buffer)
+		command.SetOut(buf)
+		command.SetErr(buf)
+		command.SetArgs([]string{"clone", sourceCollectionName, targetCollectionName, "--clone-batch-size", "2", "--workers", "3", "--max-inflight", "1"})
+		_, err := command.ExecuteC()
+		require.NoError(t, err)
+		require.Contains(t, buf.String(), "copied records: 25")
+		col := assertCollectionExists(t, client, targetCollectionName)
+		count, err := col.Count(context.TODO())
+		require.NoError(t, err)
+		require.Equal(t, int32(25), count)
+	})
+
+	t.Run("Resume an interrupted clone", func(t *testing.T) {
+		resetCloneCommandFlags()
+		client := setup()
+		defer tearDown(client)
+		var sourceCollectionName = getRandomName("clone-source")
+		var targetCollectionName = getRandomName("clone-target")
+		helperCreateCollection(t, client, sourceCollectionName)
+		addDummyRecordsToCollection(t, client, sourceCollectionName, 10)
+
+		// simulate a clone that was interrupted after the first batch
+		sourceClient, err := getClient("", "", "")
+		require.NoError(t, err)
+		source, err := getCollection(sourceClient, sourceCollectionName)
+		require.NoError(t, err)
+		helperCreateCollection(t, client, targetCollectionName)
+		target, err := getCollection(sourceClient, targetCollectionName)
+		require.NoError(t, err)
+		result, err := source.GetWithOptions(context.TODO(), types.WithLimit(4), types.WithInclude(types.IDocuments, types.IMetadatas, types.IEmbeddings))
+		require.NoError(t, err)
+		require.NoError(t, upsertRecords(context.TODO(), target, recordItemsFromResult(result)))
+		state, err := newCloneState(cloneJobID(sourceClient, sourceCollectionName, sourceClient, targetCollectionName), sourceCollectionName, targetCollectionName)
+		require.NoError(t, err)
+		state.Offset, state.Copied = 4, 4
+		require.NoError(t, state.save())
+		defer func() { _ = state.remove() }()
+
+		buf := new(bytes.Buffer)
+		command.SetOut(buf)
+		command.SetErr(buf)
+		err = cloneCollection(CloneCollectionCommand, []string{sourceCollectionName, targetCollectionName})
+		require.Error(t, err)
+		require.Contains(t, buf.String(), "--resume")
+
+		resetCloneCommandFlags()
+		buf.Reset()
+		command.SetArgs([]string{"clone", sourceCollectionName, targetCollectionName, "--resume", "--clone-batch-size", "4"})
+		_, err = command.ExecuteC()
+		require.NoError(t, err)
+		require.Contains(t, buf.String(), "copied records: 10")
+		count, err := target.Count(context.TODO())
+		require.NoError(t, err)
+		require.Equal(t, int32(10), count)
+		_, err = os.Stat(state.path)
+		require.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("Resume without checkpoint", func(t *testing.T) {
+		resetCloneCommandFlags()
+		client := setup()
+		defer tearDown(client)
+		var sourceCollectionName = getRandomName("clone-source")
+		helperCreateCollection(t, client, sourceCollectionName)
+		addDummyRecordsToCollection(t, client, sourceCollectionName, 1)
+		buf := new(bytes.Buffer)
+		command.SetOut(buf)
+		command.SetErr(buf)
+		require.NoError(t, CloneCollectionCommand.ParseFlags([]string{"--resume"}))
+		err := cloneCollection(CloneCollectionCommand, []string{sourceCollectionName, getRandomName("clone-target")})
+		require.Error(t, err)
+		require.Contains(t, buf.String(), "no interrupted clone")
+	})
+}
@@ -4,36 +4,85 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"strconv"
-	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 
+	chroma "github.com/amikos-tech/chroma-go"
 	"github.com/amikos-tech/chroma-go/collection"
 	"github.com/amikos-tech/chroma-go/types"
 )
 
 func listCollections(cmd *cobra.Command, args []string) error {
-	activeAlias := viper.GetString("active_server")
-	alias, err := getStringFlagIfChangedWithDefault(cmd, "alias", &activeAlias)
-	if err != nil {
-		return err
-	}
-	client, err := getClient(*alias)
+	client, err := getClientForCommand(cmd)
 	if err != nil {
 		cmd.Printf("%v\n", err)
 		return err
 	}
-	colList, err := client.ListCollections(context.TODO())
+	colList, err := listScopedCollections(context.TODO(), client)
 	if err != nil {
 		cmd.Printf("%v\n", err)
 		return err
 	}
+	var items = make([]collectionItem, 0, len(colList))
+	var rows = make([][]string, 0, len(colList))
 	for _, col := range colList {
-		cmd.Printf("%v\n", col) // TODO make into a table?
+		count, err := col.Count(context.TODO())
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			return err
+		}
+		items = append(items, collectionItem{
+			Name:     col.Name,
+			ID:       col.ID,
+			Tenant:   col.Tenant,
+			Database: col.Database,
+			Count:    count,
+			Metadata: col.Metadata,
+		})
+		var userMetadata = make(map[string]interface{})
+		for k, v := range col.Metadata {
+			if !isHNSWMetadataKey(k) {
+				userMetadata[k] = v
+			}
+		}
+		rows = append(rows, []string{
+			col.Name,
+			col.ID,
+			fmt.Sprintf("%v", count),
+			formatOptional(col.Metadata[types.HNSWSpace]),
+			formatOptional(col.Metadata[types.HNSWM]),
+			formatOptional(col.Metadata[types.HNSWConstructionEF]),
+			formatOptional(col.Metadata[types.HNSWSearchEF]),
+			col.Tenant,
+			col.Database,
+			formatMetadata(userMetadata),
+		})
+	}
+	return printOutput(cmd, &tableOutput{
+		Headers:     []string{"NAME", "ID", "COUNT", "SPACE", "M", "CONSTRUCTION_EF", "SEARCH_EF"},
+		WideHeaders: []string{"TENANT", "DATABASE", "METADATA"},
+		Rows:        rows,
+		Items:       items,
+	})
+}
+
+// collectionItem is the structured representation of a collection in the command output.
+type collectionItem struct {
+	Name     string                 `json:"name" yaml:"name"`
+	ID       string                 `json:"id" yaml:"id"`
+	Tenant   string                 `json:"tenant" yaml:"tenant"`
+	Database string                 `json:"database" yaml:"database"`
+	Count    int32                  `json:"count" yaml:"count"`
+	Metadata map[string]interface{} `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+}
+
+func isHNSWMetadataKey(key string) bool {
+	switch key {
+	case types.HNSWSpace, types.HNSWM, types.HNSWConstructionEF, types.HNSWSearchEF, types.HNSWBatchSize, types.HNSWSyncThreshold, types.HNSWNumThreads, types.HNSWResizeFactor:
+		return true
 	}
-	return nil
+	return false
 }
 
 var ListCollectionsCommand = &cobra.Command{
@@ -50,12 +99,7 @@ var ListCollectionsCommand = &cobra.Command{
 
 func createCollection(cmd *cobra.Command, args []string) error {
 	collectionName := args[0]
-	activeAlias := viper.GetString("active_server")
-	alias, err := getStringFlagIfChangedWithDefault(cmd, "alias", &activeAlias)
-	if err != nil {
-		return err
-	}
-	client, err := getClient(*alias)
+	client, err := getClientForCommand(cmd)
 	if err != nil {
 		cmd.Printf("%v\n", err)
 		return err
@@ -64,45 +108,11 @@ func createCollection(cmd *cobra.Command, args []string) error {
 	var options = make([]collection.Option, 0)
 	options = append(options, collection.WithName(collectionName))
 
-	if mVal, err := getIntFlagIfChangedWithDefault(cmd, "m", nil); err != nil {
-		cmd.Printf("invalid m: %v\n", err)
-		return err
-	} else if mVal != nil {
-		options = append(options, collection.WithHNSWM(int32(*mVal)))
-	}
-	if constructionEfVal, err := getIntFlagIfChangedWithDefault(cmd, "construction-ef", nil); err != nil {
-		cmd.Printf("invalid construction-ef: %v\n", err)
-		return err
-	} else if constructionEfVal != nil {
-		options = append(options, collection.WithHNSWConstructionEf(int32(*constructionEfVal)))
-	}
-
-	if searchEfVal, err := getIntFlagIfChangedWithDefault(cmd, "search-ef", nil); err != nil {
-		cmd.Printf("invalid search-ef: %v\n", err)
-		return err
-	} else if searchEfVal != nil {
-		options = append(options, collection.WithHNSWSearchEf(int32(*searchEfVal)))
-	}
-
-	if batchSizeVal, err := getIntFlagIfChangedWithDefault(cmd, "batch-size", nil); err != nil {
-		cmd.Printf("invalid batch-size: %v\n", err)
-		return err
-	} else if batchSizeVal != nil {
-		options = append(options, collection.WithHNSWBatchSize(int32(*batchSizeVal)))
-	}
-
-	if syncThresholdVal, err := getIntFlagIfChangedWithDefault(cmd, "sync-threshold", nil); err != nil {
-		cmd.Printf("invalid sync-threshold: %v\n", err)
+	hnswOptions, err := getHNSWOptionsFromFlags(cmd)
+	if err != nil {
 		return err
-	} else if syncThresholdVal != nil {
-		options = append(options, collection.WithHNSWSyncThreshold(int32(*syncThresholdVal)))
-	}
-
-	if threadsVal, err := getIntFlagIfChangedWithDefault(cmd, "threads", nil); err != nil {
-		cmd.Printf("invalid threads: %v\n", err)
-	} else if threadsVal != nil && *threadsVal > 0 {
-		options = append(options, collection.WithHNSWNumThreads(int32(*threadsVal)))
 	}
+	options = append(options, hnswOptions...)
 
 	if ensure, err := cmd.Flags().GetBool("ensure"); err != nil {
 		cmd.Printf("invalid ensure: %v\n", err)
@@ -111,25 +121,6 @@ func createCollection(cmd *cobra.Command, args []string) error {
 		options = append(options, collection.WithCreateIfNotExist(ensure))
 	}
 
-	if resizeFactorVal, err := getFloatFlagIfChangedWithDefault(cmd, "resize-factor", nil); err != nil {
-		cmd.Printf("invalid resize-factor: %v\n", err)
-		return err
-	} else if resizeFactorVal != nil {
-		options = append(options, collection.WithHNSWResizeFactor(*resizeFactorVal))
-	}
-
-	if spaceVar, err := getStringFlagIfChangedWithDefault(cmd, "space", nil); err != nil {
-		cmd.Printf("invalid space: %v\n", err)
-		return err
-	} else if spaceVar != nil {
-		df, err := types.ToDistanceFunction(*spaceVar)
-		if err != nil {
-			cmd.Printf("invalid distance function: %v\n", err)
-			return err
-		}
-		options = append(options, collection.WithHNSWDistanceFunction(df))
-	}
-
 	metadatasVar, err := getStringSliceFlagIfChangedWithDefault(cmd, "meta", &[]string{})
 	if err != nil {
 		cmd.Printf("invalid meta: %v\n", err)
@@ -137,36 +128,29 @@ func createCollection(cmd *cobra.Command, args []string) error {
 	}
 	if cmd.Flag("meta").Changed {
 		metadata := make(map[string]interface{})
-		for _, meta := range *metadatasVar {
-			kvPair := strings.Split(meta, "=")
-			if len(kvPair) != 2 {
-				cmd.Printf("invalid metadata format: %v. should be key=value.", meta)
-				os.Exit(1)
-			}
-			if b, err := strconv.ParseBool(kvPair[1]); err == nil {
-				metadata[kvPair[0]] = b
-			} else if f, err := strconv.ParseFloat(kvPair[1], 32); strings.Contains(kvPair[1], ".") && err == nil {
-				metadata[kvPair[0]] = float32(f)
-			} else if i, err := strconv.ParseInt(kvPair[1], 10, 32); err == nil {
-				metadata[kvPair[0]] = i
-			} else {
-				metadata[kvPair[0]] = kvPair[1]
-			}
+		if err := parseMetadataPairs(*metadatasVar, metadata); err != nil {
+			cmd.Printf("%v\n", err)
+			return err
 		}
 		options = append(options, collection.WithMetadatas(metadata))
 	}
 
-	_, err = client.NewCollection(
+	col, err := createScopedCollection(
 		context.Background(),
+		client,
 		options...,
 	)
 	if err != nil {
 		cmd.Printf("failed to create collection: %v\n", err)
 		return err
 	}
-	cmd.Printf("Collection created: %v\n", collectionName)
-
-	return nil
+	return printMessage(cmd, fmt.Sprintf("Collection created: %v", collectionName), collectionItem{
+		Name:     col.Name,
+		ID:       col.ID,
+		Tenant:   col.Tenant,
+		Database: col.Database,
+		Metadata: col.Metadata,
+	})
 }
 
 var CreateCollectionCommand = &cobra.Command{
@@ -186,23 +170,21 @@ var CreateCollectionCommand = &cobra.Command{
 
 func deleteCollection(cmd *cobra.Command, args []string) error {
 	collectionName := args[0]
-	activeAlias := viper.GetString("active_server")
-	alias, err := getStringFlagIfChangedWithDefault(cmd, "alias", &activeAlias)
-	if err != nil {
-		return err
-	}
-	client, err := getClient(*alias)
+	client, err := getClientForCommand(cmd)
 	if err != nil {
 		cmd.Printf("%v\n", err)
 		return err
 	}
-	_, err = client.DeleteCollection(context.TODO(), collectionName)
+	err = deleteScopedCollection(context.TODO(), client, collectionName)
 	if err != nil {
 		cmd.Printf("%v\n", err)
 		return err
 	}
-	cmd.Printf("Collection deleted: %v\n", collectionName)
-	return nil
+	return printMessage(cmd, fmt.Sprintf("Collection deleted: %v", collectionName), collectionItem{
+		Name:     collectionName,
+		Tenant:   client.Tenant,
+		Database: client.Database,
+	})
 }
 
 var DeleteCollectionCommand = &cobra.Command{
@@ -219,37 +201,73 @@ var DeleteCollectionCommand = &cobra.Command{
 }
 
 func cloneCollection(cmd *cobra.Command, args []string) error {
-	sourceCollectionName := args[0]
-	destinationCollectionName := args[1]
-	activeAlias := viper.GetString("active_server")
-	alias, err := getStringFlagIfChangedWithDefault(cmd, "alias", &activeAlias)
+	sourceRef, err := parseCollectionRef(args[0])
 	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	destinationRef, err := parseCollectionRef(args[1])
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	sourceCollectionName := sourceRef.Collection
+	destinationCollectionName := destinationRef.Collection
+	sourceClient, err := getClientForRef(cmd, sourceRef)
+	if err != nil {
+		cmd.Printf("%v\n", err)
 		return err
 	}
-	client, err := getClient(*alias)
+	destinationClient, err := getClientForRef(cmd, destinationRef)
 	if err != nil {
 		cmd.Printf("%v\n", err)
 		return err
 	}
-	sourceExists, err := collectionExists(client, sourceCollectionName)
+	sourceExists, err := collectionExists(sourceClient, sourceCollectionName)
 	if err != nil {
 		cmd.Printf("%v\n", err)
 		return err
 	}
 	if !sourceExists {
-		cmd.Printf("source collection %v does not exist\n", sourceCollectionName)
+		err := fmt.Errorf("source collection %v does not exist", sourceRef)
+		cmd.Printf("%v\n", err)
 		return err
 	}
-	destinationExists, err := collectionExists(client, destinationCollectionName)
+	resume, err := cmd.Flags().GetBool("resume")
+	if err != nil {
+		return err
+	}
+	jobID := cloneJobID(sourceClient, sourceCollectionName, destinationClient, destinationCollectionName)
+	state, err := loadCloneState(jobID)
 	if err != nil {
 		cmd.Printf("%v\n", err)
 		return err
 	}
-	if destinationExists {
-		cmd.Printf("destination collection %v already exists\n", destinationCollectionName)
+	if resume && state == nil {
+		err := fmt.Errorf("no interrupted clone of %v to %v found", sourceRef, destinationRef)
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	destinationExists, err := collectionExists(destinationClient, destinationCollectionName)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	if destinationExists && !resume {
+		err := fmt.Errorf("destination collection %v already exists", destinationRef)
+		if state != nil {
+			err = fmt.Errorf("%v. use --resume to continue the interrupted clone", err)
+		}
+		cmd.Printf("%v\n", err)
 		return err
 	}
-	sourceCollection, err := getCollection(client, sourceCollectionName)
+	if !destinationExists || state == nil {
+		if state, err = newCloneState(jobID, sourceRef.String(), destinationRef.String()); err != nil {
+			cmd.Printf("%v\n", err)
+			return err
+		}
+	}
+	sourceCollection, err := getCollection(sourceClient, sourceCollectionName)
 	if err != nil {
 		cmd.Printf("%v\n", err)
 		return err
@@ -259,7 +277,8 @@ func cloneCollection(cmd *cobra.Command, args []string) error {
 		cmd.Printf("%v\n", err)
 		return err
 	} else if count == 0 {
-		cmd.Printf("source collection %v is empty\n", sourceCollectionName)
+		err := fmt.Errorf("source collection %v is empty", sourceRef)
+		cmd.Printf("%v\n", err)
 		return err
 	}
 	cloneBatchSize := 100
@@ -320,28 +339,16 @@ func cloneCollection(cmd *cobra.Command, args []string) error {
 	}
 	var metadatasVal = make(map[string]interface{})
 	for k, v := range sourceCollection.Metadata {
-		if k == types.HNSWSpace || k == types.HNSWM || k == types.HNSWConstructionEF || k == types.HNSWSearchEF || k == types.HNSWBatchSize || k == types.HNSWSyncThreshold || k == types.HNSWNumThreads || k == types.HNSWResizeFactor {
+		if isHNSWMetadataKey(k) {
 			continue
 		}
 		metadatasVal[k] = v
 	}
 
 	if cmd.Flag("meta").Changed {
-		for _, meta := range *metadatasVar {
-			kvPair := strings.Split(meta, "=")
-			if len(kvPair) != 2 {
-				cmd.Printf("invalid metadata format: %v. should be key=value.", meta)
-				return err
-			}
-			if b, err := strconv.ParseBool(kvPair[1]); err == nil {
-				metadatasVal[kvPair[0]] = b
-			} else if f, err := strconv.ParseFloat(kvPair[1], 32); strings.Contains(kvPair[1], ".") && err == nil {
-				metadatasVal[kvPair[0]] = float32(f)
-			} else if i, err := strconv.ParseInt(kvPair[1], 10, 32); err == nil {
-				metadatasVal[kvPair[0]] = i
-			} else {
-				metadatasVal[kvPair[0]] = kvPair[1]
-			}
+		if err := parseMetadataPairs(*metadatasVar, metadatasVal); err != nil {
+			cmd.Printf("%v\n", err)
+			return err
 		}
 	}
 	var collectionOptions = make([]collection.Option, 0)
@@ -353,7 +360,8 @@ func cloneCollection(cmd *cobra.Command, args []string) error {
 		collectionOptions = append(collectionOptions, collection.WithHNSWDistanceFunction(df))
 	}
 	var hasEf = false
-	if efVal, err := embeddingFunctionForString(cmd.Flags().GetString("embedding-function")); err != nil {
+	efVal, err := embeddingFunctionForString(cmd.Flags().GetString("embedding-function"))
+	if err != nil {
 		cmd.Printf("invalid embedding-function: %v\n", err)
 		return err
 	} else if efVal != nil {
@@ -385,52 +393,133 @@ func cloneCollection(cmd *cobra.Command, args []string) error {
 	if len(metadatasVal) > 0 {
 		collectionOptions = append(collectionOptions, collection.WithMetadatas(metadatasVal))
 	}
-	targetCollection, err := client.NewCollection(context.TODO(),
-		collectionOptions...,
-	)
-	if err != nil {
+	var targetCollection *chroma.Collection
+	if destinationExists {
+		targetCollection, err = getCollection(destinationClient, destinationCollectionName)
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			return err
+		}
+		targetCollection.EmbeddingFunction = efVal
+	} else {
+		targetCollection, err = createScopedCollection(context.TODO(), destinationClient,
+			collectionOptions...,
+		)
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			return err
+		}
+	}
+	state.SourceID = sourceCollection.ID
+	state.DestinationID = targetCollection.ID
+	state.SourceCount = count
+	if err := state.save(); err != nil {
 		cmd.Printf("%v\n", err)
 		return err
 	}
-
-	var totalNumberOfRecordsCopied = 0
-
-	for start := 0; start < int(count); start += cloneBatchSize {
-		end := start + cloneBatchSize
-		if end > int(count) {
-			end = int(count)
+	retries, err := cmd.Flags().GetInt("retries")
+	if err != nil {
+		return err
+	}
+	retryDelay, err := cmd.Flags().GetDuration("retry-delay")
+	if err != nil {
+		return err
+	}
+	workers, err := cmd.Flags().GetInt("workers")
+	if err != nil {
+		return err
+	}
+	maxInflight, err := cmd.Flags().GetInt("max-inflight")
+	if err != nil {
+		return err
+	}
+	progress := newProgressBar(cmd.ErrOrStderr(), fmt.Sprintf("copying %v", sourceRef), int(count))
+	progress.Add(state.Copied)
+	err = copyCollectionRecords(context.TODO(), sourceCollection, targetCollection, state, cloneOptions{
+		BatchSize:   cloneBatchSize,
+		Workers:     workers,
+		MaxInflight: maxInflight,
+		Retries:     retries,
+		RetryDelay:  retryDelay,
+		ReEmbed:     hasEf,
+		Progress:    progress.Add,
+	})
+	progress.Finish()
+	if err != nil {
+		cmd.Printf("clone of %v to %v failed after %v records: %v\n", sourceRef, destinationRef, state.Copied, err)
+		if rollback, _ := cmd.Flags().GetBool("rollback-on-failure"); rollback {
+			if rbErr := deleteScopedCollection(context.TODO(), destinationClient, destinationCollectionName); rbErr != nil {
+				cmd.Printf("rollback failed: %v\n", rbErr)
+				return err
+			}
+			_ = state.remove()
+			cmd.Printf("rolled back: deleted destination collection %v\n", destinationRef)
+			return err
 		}
-
-		result, err := sourceCollection.GetWithOptions(
-			context.TODO(),
-			types.WithOffset(int32(start)),
-			types.WithLimit(int32(end)),
-			types.WithInclude(types.IMetadatas, types.IDocuments, types.IEmbeddings),
-		)
+		cmd.Printf("run the same command with --resume to continue the clone\n")
+		return err
+	}
+	if err := state.remove(); err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	totalNumberOfRecordsCopied := state.Copied
+	verify, err := cmd.Flags().GetBool("verify")
+	if err != nil {
+		return err
+	}
+	if verify {
+		options, err := getDiffOptionsFromFlags(cmd)
 		if err != nil {
 			cmd.Printf("%v\n", err)
 			return err
 		}
-		var _embeddings []*types.Embedding
-		if !hasEf {
-			_embeddings = result.Embeddings
+		options.BatchSize = int32(cloneBatchSize)
+		// re-embedded records are expected to have different embeddings
+		options.CompareEmbeddings = !hasEf
+		diff, err := diffCollections(context.TODO(), sourceCollection, targetCollection, options)
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			return err
 		}
-		_, err = targetCollection.Add(context.TODO(), _embeddings, result.Metadatas, result.Documents, result.Ids)
-		if err != nil { // TODO not great to exit on first error but for now that will do. Consider rollback?
+		diff.Source, diff.Target = sourceRef.String(), destinationRef.String()
+		if !diff.identical() {
+			err := fmt.Errorf("verification of the clone failed: %v", diff.summary())
 			cmd.Printf("%v\n", err)
 			return err
 		}
-		totalNumberOfRecordsCopied += len(result.Ids)
-	}
-	cmd.Printf("successfully cloned %v to %v. copied records: %v\n", sourceCollection.Name, targetCollection.Name, totalNumberOfRecordsCopied)
-	return nil
+		cmd.Printf("verified: %v\n", diff.summary())
+	}
+	return printMessage(cmd, fmt.Sprintf("successfully cloned %v to %v. copied records: %v", sourceRef, destinationRef, totalNumberOfRecordsCopied), cloneResultItem{
+		Source:        sourceRef.String(),
+		Destination:   destinationRef.String(),
+		RecordsCopied: totalNumberOfRecordsCopied,
+		Verified:      verify,
+	})
+}
+
+// cloneResultItem is the structured representation of a finished clone in the command output.
+type cloneResultItem struct {
+	Source        string `json:"source" yaml:"source"`
+	Destination   string `json:"destination" yaml:"destination"`
+	RecordsCopied int    `json:"records_copied" yaml:"records_copied"`
+	Verified      bool   `json:"verified" yaml:"verified"`
 }
 
 var CloneCollectionCommand = &cobra.Command{
 	Use:     "clone",
 	Aliases: []string{"cp"},
 	Short:   "Clone a collection",
-	Args:    cobra.MinimumNArgs(2),
+	Long: `Clone a collection, its metadata and HNSW settings and all of its records. The source and destination can be on
+different servers, tenants or databases. They are given as collection, alias:collection,
+alias:tenant/database/collection or alias/tenant/database/collection. The --alias, --tenant and --database flags are
+used for the parts a reference does not specify.`,
+	Example: `  chroma cp my-collection my-collection-copy
+  chroma cp prod:my-collection local:my-collection
+  chroma cp prod/acme/default_database/my-collection local:my-collection
+  chroma cp prod:my-collection local:my-collection --workers 8 --clone-batch-size 500
+  chroma cp prod:my-collection local:my-collection --verify`,
+	Args: cobra.ExactArgs(2),
 	Run: func(cmd *cobra.Command, args []string) {
 		err := cloneCollection(cmd, args)
 		if err != nil {
@@ -439,6 +528,82 @@ var CloneCollectionCommand = &cobra.Command{
 	},
 }
 
+// getHNSWOptionsFromFlags returns the collection options for the HNSW flags set with addHNSWFlags that were changed.
+func getHNSWOptionsFromFlags(cmd *cobra.Command) ([]collection.Option, error) {
+	var options = make([]collection.Option, 0)
+	if mVal, err := getIntFlagIfChangedWithDefault(cmd, "m", nil); err != nil {
+		cmd.Printf("invalid m: %v\n", err)
+		return nil, err
+	} else if mVal != nil {
+		options = append(options, collection.WithHNSWM(int32(*mVal)))
+	}
+	if constructionEfVal, err := getIntFlagIfChangedWithDefault(cmd, "construction-ef", nil); err != nil {
+		cmd.Printf("invalid construction-ef: %v\n", err)
+		return nil, err
+	} else if constructionEfVal != nil {
+		options = append(options, collection.WithHNSWConstructionEf(int32(*constructionEfVal)))
+	}
+
+	if searchEfVal, err := getIntFlagIfChangedWithDefault(cmd, "search-ef", nil); err != nil {
+		cmd.Printf("invalid search-ef: %v\n", err)
+		return nil, err
+	} else if searchEfVal != nil {
+		options = append(options, collection.WithHNSWSearchEf(int32(*searchEfVal)))
+	}
+
+	if batchSizeVal, err := getIntFlagIfChangedWithDefault(cmd, "batch-size", nil); err != nil {
+		cmd.Printf("invalid batch-size: %v\n", err)
+		return nil, err
+	} else if batchSizeVal != nil {
+		options = append(options, collection.WithHNSWBatchSize(int32(*batchSizeVal)))
+	}
+
+	if syncThresholdVal, err := getIntFlagIfChangedWithDefault(cmd, "sync-threshold", nil); err != nil {
+		cmd.Printf("invalid sync-threshold: %v\n", err)
+		return nil, err
+	} else if syncThresholdVal != nil {
+		options = append(options, collection.WithHNSWSyncThreshold(int32(*syncThresholdVal)))
+	}
+
+	if threadsVal, err := getIntFlagIfChangedWithDefault(cmd, "threads", nil); err != nil {
+		cmd.Printf("invalid threads: %v\n", err)
+	} else if threadsVal != nil && *threadsVal > 0 {
+		options = append(options, collection.WithHNSWNumThreads(int32(*threadsVal)))
+	}
+
+	if resizeFactorVal, err := getFloatFlagIfChangedWithDefault(cmd, "resize-factor", nil); err != nil {
+		cmd.Printf("invalid resize-factor: %v\n", err)
+		return nil, err
+	} else if resizeFactorVal != nil {
+		options = append(options, collection.WithHNSWResizeFactor(*resizeFactorVal))
+	}
+
+	if spaceVar, err := getStringFlagIfChangedWithDefault(cmd, "space", nil); err != nil {
+		cmd.Printf("invalid space: %v\n", err)
+		return nil, err
+	} else if spaceVar != nil {
+		df, err := types.ToDistanceFunction(*spaceVar)
+		if err != nil {
+			cmd.Printf("invalid distance function: %v\n", err)
+			return nil, err
+		}
+		options = append(options, collection.WithHNSWDistanceFunction(df))
+	}
+	return options, nil
+}
+
+// addHNSWFlags adds the flags configuring the HNSW index of a new collection.
+func addHNSWFlags(command *cobra.Command) {
+	command.Flags().StringP("space", "p", string(types.L2), "Distance metric to use for the collection")
+	command.Flags().IntP("m", "m", 16, "hnsw:m - The maximum number of outgoing connections (links) for a single node within the HNSW graph.")
+	command.Flags().IntP("construction-ef", "u", 100, "hnsw:construction_ef - This parameter influences the size of the dynamic list used during the graph construction phase.")
+	command.Flags().IntP("search-ef", "f", 10, "hnsw:search_ef - The size of the dynamic list employed during the search phase.")
+	command.Flags().IntP("batch-size", "b", 100, "hnsw:batch_size - The number of elements held in brute force index (in-memory), before adding them to the HNSW index.")
+	command.Flags().IntP("sync-threshold", "k", 1000, "hnsw:sync_threshold - The number of elements added to the HNSW index before the index is synced to disk.")
+	command.Flags().IntP("threads", "n", -1, "hnsw:threads - The number of threads to use during index construction and searches. Defaults to the number of logical cores on the machine.")
+	command.Flags().Float32P("resize-factor", "r", 1.2, "hnsw:resize_factor - This parameter is used by HNSW's hierarchical layers during insertion..")
+}
+
 func getIntFlagIfChangedWithDefault(cmd *cobra.Command, flag string, defaultValue *int) (*int, error) {
 	if cmd.Flag(flag).Changed {
 		flagValue, err := cmd.Flags().GetInt(flag)
@@ -539,34 +704,36 @@ func init() {
 	RootCmd.AddCommand(ListCollectionsCommand)
 	collectionCommand.AddCommand(ListCollectionsCommand)
 	ListCollectionsCommand.Flags().StringP("alias", "s", "", "Server alias name. If not provided, the active server will be used.")
+	ListCollectionsCommand.Flags().StringP("tenant", "t", "", "Tenant name. If not provided, the active tenant or the server default will be used.")
+	ListCollectionsCommand.Flags().StringP("database", "d", "", "Database name. If not provided, the active database or the server default will be used.")
 	CreateCollectionCommand.Flags().String("name", "", "Name of the collection")
 	CreateCollectionCommand.Flags().StringP("alias", "s", "", "Server alias name. If not provided, the active server will be used.")
+	CreateCollectionCommand.Flags().StringP("tenant", "t", "", "Tenant name. If not provided, the active tenant or the server default will be used.")
+	CreateCollectionCommand.Flags().StringP("database", "d", "", "Database name. If not provided, the active database or the server default will be used.")
 	CreateCollectionCommand.Flags().Bool("ensure", false, "Create collection only if it doesn't exist. Chroma will be queried before sending create, if the collection exists, exit with 0. The metadata will be overwritten.")
-	CreateCollectionCommand.Flags().StringP("space", "p", string(types.L2), "Distance metric to use for the collection")
-	CreateCollectionCommand.Flags().IntP("m", "m", 16, "hnsw:m - The maximum number of outgoing connections (links) for a single node within the HNSW graph.")
-	CreateCollectionCommand.Flags().IntP("construction-ef", "u", 100, "hnsw:construction_ef - This parameter influences the size of the dynamic list used during the graph construction phase.")
-	CreateCollectionCommand.Flags().IntP("search-ef", "f", 10, "hnsw:search_ef - The size of the dynamic list employed during the search phase.")
-	CreateCollectionCommand.Flags().IntP("batch-size", "b", 100, "hnsw:batch_size - The number of elements held in brute force index (in-memory), before adding them to the HNSW index.")
-	CreateCollectionCommand.Flags().IntP("sync-threshold", "k", 1000, "hnsw:sync_threshold - The number of elements added to the HNSW index before the index is synced to disk.")
-	CreateCollectionCommand.Flags().IntP("threads", "n", -1, "hnsw:threads - The number of threads to use during index construction and searches. Defaults to the number of logical cores on the machine.")
-	CreateCollectionCommand.Flags().Float32P("resize-factor", "r", 1.2, "hnsw:resize_factor - This parameter is used by HNSW's hierarchical layers during insertion..")
+	addHNSWFlags(CreateCollectionCommand)
 	CreateCollectionCommand.Flags().StringSliceVarP(&metaSlice, "meta", "a", []string{}, "Defines a single key-value attribute (KVP) to added to collection metadata.")
 	collectionCommand.AddCommand(CreateCollectionCommand)
 	RootCmd.AddCommand(CreateCollectionCommand)
 	DeleteCollectionCommand.Flags().StringP("alias", "s", "", "Server alias name. If not provided, the active server will be used.")
+	DeleteCollectionCommand.Flags().StringP("tenant", "t", "", "Tenant name. If not provided, the active tenant or the server default will be used.")
+	DeleteCollectionCommand.Flags().StringP("database", "d", "", "Database name. If not provided, the active database or the server default will be used.")
 	collectionCommand.AddCommand(DeleteCollectionCommand)
 	RootCmd.AddCommand(DeleteCollectionCommand)
 	CloneCollectionCommand.Flags().IntP("clone-batch-size", "z", 100, "The batch size for cloning from one collection to another.")
 	CloneCollectionCommand.Flags().StringP("alias", "s", "", "Server alias name. If not provided, the active server will be used.")
-	CloneCollectionCommand.Flags().StringP("space", "p", string(types.L2), "Distance metric to use for the collection")
-	CloneCollectionCommand.Flags().IntP("m", "m", 16, "hnsw:m - The maximum number of outgoing connections (links) for a single node within the HNSW graph.")
-	CloneCollectionCommand.Flags().IntP("construction-ef", "u", 100, "hnsw:construction_ef - This parameter influences the size of the dynamic list used during the graph construction phase.")
-	CloneCollectionCommand.Flags().IntP("search-ef", "f", 10, "hnsw:search_ef - The size of the dynamic list employed during the search phase.")
-	CloneCollectionCommand.Flags().IntP("batch-size", "b", 100, "hnsw:batch_size - The number of elements held in brute force index (in-memory), before adding them to the HNSW index.")
-	CloneCollectionCommand.Flags().IntP("sync-threshold", "k", 1000, "hnsw:sync_threshold - The number of elements added to the HNSW index before the index is synced to disk.")
-	CloneCollectionCommand.Flags().IntP("threads", "n", -1, "hnsw:threads - The number of threads to use during index construction and searches. Defaults to the number of logical cores on the machine.")
-	CloneCollectionCommand.Flags().Float32P("resize-factor", "r", 1.2, "hnsw:resize_factor - This parameter is used by HNSW's hierarchical layers during insertion..")
+	CloneCollectionCommand.Flags().StringP("tenant", "t", "", "Tenant name. If not provided, the active tenant or the server default will be used.")
+	CloneCollectionCommand.Flags().StringP("database", "d", "", "Database name. If not provided, the active database or the server default will be used.")
+	addHNSWFlags(CloneCollectionCommand)
 	CloneCollectionCommand.Flags().StringP("embedding-function", "e", "", "The name of the embedding function to use for the target collection")
 	CloneCollectionCommand.Flags().StringSliceVarP(&metaSlice, "meta", "a", []string{}, "Defines a single key-value attribute (KVP) to added to collection metadata.")
+	CloneCollectionCommand.Flags().Bool("resume", false, "Continue an interrupted clone from its checkpoint")
+	CloneCollectionCommand.Flags().Int("retries", 3, "Number of retries of a failed batch read or write")
+	CloneCollectionCommand.Flags().Duration("retry-delay", time.Second, "Delay before the first retry. The delay doubles with every retry.")
+	CloneCollectionCommand.Flags().Int("workers", 4, "Number of concurrent readers and of concurrent writers")
+	CloneCollectionCommand.Flags().Int("max-inflight", 8, "Maximum number of read batches waiting to be written")
+	CloneCollectionCommand.Flags().Bool("verify", false, "Compare the source and destination collections after the clone")
+	addDiffFlags(CloneCollectionCommand)
+	CloneCollectionCommand.Flags().Bool("rollback-on-failure", false, "Delete the destination collection if the clone fails")
 	RootCmd.AddCommand(CloneCollectionCommand)
 }
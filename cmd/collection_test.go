@@ -52,6 +52,14 @@ func resetCloneCommandFlags() {
 	CloneCollectionCommand.Flag("resize-factor").Changed = false
 	_ = CloneCollectionCommand.Flags().Set("meta", "")
 	CloneCollectionCommand.Flag("meta").Changed = false
+	_ = CloneCollectionCommand.Flag("resume").Value.Set("false")
+	CloneCollectionCommand.Flag("resume").Changed = false
+	_ = CloneCollectionCommand.Flag("workers").Value.Set("4")
+	CloneCollectionCommand.Flag("workers").Changed = false
+	_ = CloneCollectionCommand.Flag("max-inflight").Value.Set("8")
+	CloneCollectionCommand.Flag("max-inflight").Changed = false
+	_ = CloneCollectionCommand.Flag("verify").Value.Set("false")
+	CloneCollectionCommand.Flag("verify").Changed = false
 	metaSlice = []string{}
 }
 
@@ -1459,4 +1467,32 @@ func TestCloneCollectionCommandWithSourceOverride(t *testing.T) {
 		require.Contains(t, output, targetCollectionName)
 		require.Contains(t, output, "10")
 	})
+	t.Run("Clone Collection to another tenant and database", func(t *testing.T) {
+		resetCloneCommandFlags()
+		client := setup()
+		defer tearDown(client)
+		var sourceCollectionName = getRandomName("my-new-collection")
+		var tenantName = getRandomName("clone-tenant")
+		var dbName = getRandomName("clone-db")
+		helperCreateTenant(t, client, tenantName)
+		_, err := client.CreateDatabase(context.TODO(), dbName, &tenantName)
+		require.NoError(t, err)
+		helperCreateCollection(t, client, sourceCollectionName)
+		addDummyRecordsToCollection(t, client, sourceCollectionName, 10)
+		buf := new(bytes.Buffer)
+		command.SetOut(buf)
+		command.SetErr(buf)
+		var destination = tenantName + "/" + dbName + "/" + sourceCollectionName
+		command.SetArgs([]string{"cp", sourceCollectionName, destination})
+		_, err = command.ExecuteC()
+		require.NoError(t, err)
+		require.Contains(t, buf.String(), "successfully cloned "+sourceCollectionName+" to "+destination)
+		scopedClient, err := getClient("", tenantName, dbName)
+		require.NoError(t, err)
+		col, err := getCollection(scopedClient, sourceCollectionName)
+		require.NoError(t, err)
+		count, err := col.Count(context.TODO())
+		require.NoError(t, err)
+		require.Equal(t, int32(10), count)
+	})
 }
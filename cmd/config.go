@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	clitypes "github.com/amikos-tech/chroma-cli/chroma/types"
+	"github.com/amikos-tech/chroma-cli/chroma/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// configValidationItem is the structured result of validating a config file.
+type configValidationItem struct {
+	File     string   `json:"file" yaml:"file"`
+	Version  int      `json:"version" yaml:"version"`
+	Servers  int      `json:"servers" yaml:"servers"`
+	Valid    bool     `json:"valid" yaml:"valid"`
+	Problems []string `json:"problems,omitempty" yaml:"problems,omitempty"`
+}
+
+func validateConfigFile(cmd *cobra.Command, args []string) error {
+	var file = viper.ConfigFileUsed()
+	var config *clitypes.Config
+	var err error
+	if len(args) > 0 {
+		file = args[0]
+		config, err = utils.ReadConfigFile(file)
+	} else {
+		config, err = utils.GetConfig()
+	}
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	var item = configValidationItem{File: file, Version: config.Version, Servers: len(config.Servers)}
+	for _, problem := range clitypes.Problems(config.Validate()) {
+		item.Problems = append(item.Problems, problem.Error())
+	}
+	item.Valid = len(item.Problems) == 0
+	if item.Valid {
+		var message = fmt.Sprintf("Config file %v is valid (version %v, %v servers)", file, config.Version, len(config.Servers))
+		if config.Version < clitypes.ConfigVersion {
+			message += fmt.Sprintf(". It will be migrated to version %v when it is loaded", clitypes.ConfigVersion)
+		}
+		return printMessage(cmd, message, item)
+	}
+	var rows = make([][]string, 0, len(item.Problems))
+	for _, problem := range clitypes.Problems(config.Validate()) {
+		var serverErr *clitypes.ServerError
+		if errors.As(problem, &serverErr) {
+			rows = append(rows, []string{serverErr.Alias, serverErr.Err.Error()})
+		} else {
+			rows = append(rows, []string{"", problem.Error()})
+		}
+	}
+	err = printOutput(cmd, &tableOutput{Headers: []string{"SERVER", "PROBLEM"}, Rows: rows, Items: item})
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	return fmt.Errorf("config file %v has %v problems", file, len(item.Problems))
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage the CLI config file",
+}
+
+var ValidateConfigCommand = &cobra.Command{
+	Use:   "validate [file]",
+	Short: "Check the config file for problems",
+	Long: `Check the server entries of the config file: hosts, ports, credentials, TLS options and the active server. All
+problems are reported and the command exits with a non-zero status if there are any. Without a file the loaded config
+file is checked.`,
+	Args: cobra.MaximumNArgs(1),
+	Example: `  chroma config validate
+  chroma config validate ~/backup/config.yaml -o json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := validateConfigFile(cmd, args)
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	configCmd.AddCommand(ValidateConfigCommand)
+	RootCmd.AddCommand(configCmd)
+}
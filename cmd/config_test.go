@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateConfig(t *testing.T) {
+	dir := t.TempDir()
+	buf := new(bytes.Buffer)
+	ValidateConfigCommand.SetOut(buf)
+	ValidateConfigCommand.SetErr(buf)
+
+	t.Run("Unversioned", func(t *testing.T) {
+		file := filepath.Join(dir, "v0.yaml")
+		require.NoError(t, os.WriteFile(file, []byte(`active_server: local
+servers:
+  local:
+    host: localhost
+    port: "8000"
+`), 0600))
+		buf.Reset()
+		require.NoError(t, validateConfigFile(ValidateConfigCommand, []string{file}))
+		require.Contains(t, buf.String(), "is valid (version 0, 1 servers)")
+		require.Contains(t, buf.String(), "will be migrated to version 1")
+	})
+
+	t.Run("Problems", func(t *testing.T) {
+		file := filepath.Join(dir, "broken.yaml")
+		require.NoError(t, os.WriteFile(file, []byte(`version: 1
+active_server: missing
+servers:
+  broken:
+    host: http://localhost
+    port: 70000
+    auth:
+      type: basic
+      token: nocolon
+    tls:
+      cert_file: client.pem
+  ok:
+    host: localhost
+    port: 8000
+    secure: true
+    tls:
+      ca_file: ca.pem
+`), 0600))
+		require.NoError(t, ValidateConfigCommand.ParseFlags([]string{"-o", "json"}))
+		defer resetCommandFlags(ValidateConfigCommand)
+		buf.Reset()
+		err := validateConfigFile(ValidateConfigCommand, []string{file})
+		require.ErrorContains(t, err, "has 6 problems")
+		var item configValidationItem
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &item))
+		require.False(t, item.Valid)
+		require.Equal(t, []string{
+			"active server missing does not exist",
+			"server broken: invalid host: http://localhost",
+			"server broken: invalid port: 70000. must be between 1 and 65535",
+			"server broken: invalid basic auth credentials, expected username:password",
+			"server broken: tls options require secure: true",
+			"server broken: tls cert_file and key_file must be set together",
+		}, item.Problems)
+	})
+
+	t.Run("Newer version", func(t *testing.T) {
+		file := filepath.Join(dir, "v9.yaml")
+		require.NoError(t, os.WriteFile(file, []byte("version: 9\n"), 0600))
+		buf.Reset()
+		err := validateConfigFile(ValidateConfigCommand, []string{file})
+		require.Error(t, err)
+		require.Contains(t, buf.String(), "upgrade the CLI")
+	})
+}
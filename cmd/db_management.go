@@ -2,11 +2,16 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
 
+	"github.com/charmbracelet/huh"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
+
+	chroma "github.com/amikos-tech/chroma-go"
 )
 
 var CreateTenantCommand = &cobra.Command{
@@ -16,52 +21,280 @@ var CreateTenantCommand = &cobra.Command{
 	Args:    cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		tenantName := args[0]
-		activeAlias := viper.GetString("active_server")
-		alias, err := getStringFlagIfChangedWithDefault(cmd, "alias", &activeAlias)
+		client, err := getClientForCommand(cmd)
 		if err != nil {
 			cmd.Printf("%v\n", err)
 			os.Exit(1)
 		}
-		client, err := getClient(*alias)
+		_, err = client.CreateTenant(context.TODO(), tenantName)
 		if err != nil {
 			cmd.Printf("%v\n", err)
 			os.Exit(1)
 		}
-		_, err = client.CreateTenant(context.TODO(), tenantName)
+		err = printMessage(cmd, fmt.Sprintf("Tenant '%v' created", tenantName), tenantItem{Name: tenantName})
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// tenantItem is the structured representation of a tenant in the command output.
+type tenantItem struct {
+	Name string `json:"name" yaml:"name"`
+}
+
+var GetTenantCommand = &cobra.Command{
+	Use:   "get",
+	Short: "Show a tenant",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClientForCommand(cmd)
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		resp, err := client.GetTenant(context.TODO(), args[0])
+		if err != nil {
+			cmd.Printf("unable to get tenant %v: %v\n", args[0], err)
+			os.Exit(1)
+		}
+		var item = tenantItem{Name: args[0]}
+		if resp.Name != nil {
+			item.Name = *resp.Name
+		}
+		err = printOutput(cmd, &tableOutput{Headers: []string{"NAME"}, Rows: [][]string{{item.Name}}, Items: item})
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// databaseItem is the structured representation of a database in the command output.
+type databaseItem struct {
+	Name   string `json:"name" yaml:"name"`
+	ID     string `json:"id,omitempty" yaml:"id,omitempty"`
+	Tenant string `json:"tenant" yaml:"tenant"`
+}
+
+func databaseRows(databases ...databaseItem) [][]string {
+	var rows = make([][]string, 0, len(databases))
+	for _, db := range databases {
+		rows = append(rows, []string{db.Name, db.Tenant, db.ID})
+	}
+	return rows
+}
+
+// errListDatabasesUnsupported is returned by listDatabases for servers without an endpoint to list databases.
+var errListDatabasesUnsupported = errors.New("the server does not support listing databases. upgrade Chroma or name the database with --database")
+
+// listDatabases lists the databases of a tenant. Listing databases is only supported by recent versions of Chroma, the
+// v1 endpoint is tried first and the v2 endpoint second.
+func listDatabases(ctx context.Context, client *chroma.Client, tenantName string) ([]databaseItem, error) {
+	var databases []databaseItem
+	err := apiRequest(ctx, client, http.MethodGet, "/api/v1/databases", url.Values{"tenant": {tenantName}}, &databases)
+	if isUnsupportedEndpoint(err) {
+		err = apiRequest(ctx, client, http.MethodGet, "/api/v2/tenants/"+url.PathEscape(tenantName)+"/databases", nil, &databases)
+	}
+	if isUnsupportedEndpoint(err) {
+		return nil, errListDatabasesUnsupported
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i := range databases {
+		if databases[i].Tenant == "" {
+			databases[i].Tenant = tenantName
+		}
+	}
+	return databases, nil
+}
+
+// deleteDatabase deletes a database and its collections. Like listing, deleting databases is only supported by recent
+// versions of Chroma.
+func deleteDatabase(ctx context.Context, client *chroma.Client, tenantName string, databaseName string) error {
+	err := apiRequest(ctx, client, http.MethodDelete, "/api/v1/databases/"+url.PathEscape(databaseName), url.Values{"tenant": {tenantName}}, nil)
+	if isUnsupportedEndpoint(err) {
+		err = apiRequest(ctx, client, http.MethodDelete, "/api/v2/tenants/"+url.PathEscape(tenantName)+"/databases/"+url.PathEscape(databaseName), nil, nil)
+	}
+	if isUnsupportedEndpoint(err) {
+		return fmt.Errorf("the server does not support deleting databases. upgrade Chroma to delete database %v", databaseName)
+	}
+	return err
+}
+
+var ListDatabasesCommand = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List the databases of a tenant",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClientForCommand(cmd)
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		databases, err := listDatabases(context.TODO(), client, client.Tenant)
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		err = printOutput(cmd, &tableOutput{
+			Headers:     []string{"NAME", "TENANT"},
+			WideHeaders: []string{"ID"},
+			Rows:        databaseRows(databases...),
+			Items:       databases,
+		})
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var GetDatabaseCommand = &cobra.Command{
+	Use:   "get",
+	Short: "Show a database",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClientForCommand(cmd)
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		resp, err := client.GetDatabase(context.TODO(), args[0], &client.Tenant)
+		if err != nil {
+			cmd.Printf("unable to get database %v of tenant %v: %v\n", args[0], client.Tenant, err)
+			os.Exit(1)
+		}
+		var item = databaseItem{Name: args[0], Tenant: client.Tenant}
+		if resp.Id != nil {
+			item.ID = *resp.Id
+		}
+		err = printOutput(cmd, &tableOutput{
+			Headers:     []string{"NAME", "TENANT"},
+			WideHeaders: []string{"ID"},
+			Rows:        databaseRows(item),
+			Items:       item,
+		})
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var RmDatabaseCommand = &cobra.Command{
+	Use:     "remove",
+	Aliases: []string{"rm"},
+	Short:   "Delete a database and all its collections",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		dbName := args[0]
+		client, err := getClientForCommand(cmd)
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		ctx := context.TODO()
+		if _, err := client.GetDatabase(ctx, dbName, &client.Tenant); err != nil {
+			cmd.Printf("Database %v does not exist in tenant %v! \n", dbName, client.Tenant)
+			os.Exit(1)
+		}
+		confirm, err := cmd.Flags().GetBool("force")
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		if !confirm {
+			err := huh.NewConfirm().
+				Title("Are you sure you want to remove database [" + dbName + "] of tenant [" + client.Tenant + "] and all its collections?").
+				Affirmative("Yes!").
+				Negative("No.").
+				Value(&confirm).Run()
+			if err != nil {
+				cmd.Printf("unable to get confirmation: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if !confirm {
+			cmd.Printf("Operation aborted!\n")
+			os.Exit(0)
+		}
+		if err := deleteDatabase(ctx, client, client.Tenant, dbName); err != nil {
+			cmd.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		err = printMessage(cmd, fmt.Sprintf("Database '%v' removed from tenant '%v'", dbName, client.Tenant), databaseItem{Name: dbName, Tenant: client.Tenant})
 		if err != nil {
 			cmd.Printf("%v\n", err)
 			os.Exit(1)
 		}
-		cmd.Printf("Tenant '%v' created\n", tenantName)
 	},
 }
 
-var tenant string // Tenant name
+// verifyContext checks that the tenant and the database exist on the server. The v1 endpoints are tried first and the
+// v2 endpoints second. A 404 is only reported as a missing tenant or database if the server supports the API version,
+// a v2 only server also answers 404 for the v1 endpoints of existing tenants.
+func verifyContext(ctx context.Context, client *chroma.Client, tenantName string, databaseName string) error {
+	var tenantPath = "/api/v1/tenants/" + url.PathEscape(tenantName)
+	var databasePath = "/api/v1/databases/" + url.PathEscape(databaseName)
+	var databaseQuery = url.Values{"tenant": {tenantName}}
+	err := apiRequest(ctx, client, http.MethodGet, tenantPath, nil, nil)
+	if isUnsupportedEndpoint(err) && !supportsAPIVersion(ctx, client, "v1") {
+		tenantPath = "/api/v2/tenants/" + url.PathEscape(tenantName)
+		databasePath = tenantPath + "/databases/" + url.PathEscape(databaseName)
+		databaseQuery = nil
+		err = apiRequest(ctx, client, http.MethodGet, tenantPath, nil, nil)
+		if isUnsupportedEndpoint(err) && !supportsAPIVersion(ctx, client, "v2") {
+			return fmt.Errorf("unable to verify tenant %v: the server supports neither the v1 nor the v2 API", tenantName)
+		}
+	}
+	var apiErr *apiError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("tenant %v does not exist", tenantName)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to verify tenant %v: %v", tenantName, err)
+	}
+	err = apiRequest(ctx, client, http.MethodGet, databasePath, databaseQuery, nil)
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("database %v does not exist in tenant %v", databaseName, tenantName)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to verify database %v: %v", databaseName, err)
+	}
+	return nil
+}
+
+// supportsAPIVersion reports whether the heartbeat endpoint of an API version, e.g. v1, answers.
+func supportsAPIVersion(ctx context.Context, client *chroma.Client, version string) bool {
+	return apiRequest(ctx, client, http.MethodGet, "/api/"+version+"/heartbeat", nil, nil) == nil
+}
+
 var CreateDatabaseCommand = &cobra.Command{
 	Use:     "create",
 	Aliases: []string{"c"},
-	Short:   "Create a db for a tenant, if no tenant is specified with --tenant/-t, the default_tenant is used.",
+	Short:   "Create a db for a tenant, if no tenant is specified with --tenant/-t, the active tenant or the server default is used.",
 	Args:    cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		dbName := args[0]
-		activeAlias := viper.GetString("active_server")
-		alias, err := getStringFlagIfChangedWithDefault(cmd, "alias", &activeAlias)
+		client, err := getClientForCommand(cmd)
 		if err != nil {
 			cmd.Printf("%v\n", err)
 			os.Exit(1)
 		}
-		client, err := getClient(*alias)
-		fmt.Printf("tenant: %v\n", tenant)
+		_, err = client.CreateDatabase(context.TODO(), dbName, &client.Tenant)
 		if err != nil {
 			cmd.Printf("%v\n", err)
 			os.Exit(1)
 		}
-		_, err = client.CreateDatabase(context.TODO(), dbName, &tenant)
+		err = printMessage(cmd, fmt.Sprintf("Database '%v' created in tenant '%v'", dbName, client.Tenant), databaseItem{Name: dbName, Tenant: client.Tenant})
 		if err != nil {
 			cmd.Printf("%v\n", err)
 			os.Exit(1)
 		}
-		cmd.Printf("Database '%v' created in tenant '%v'\n", dbName, tenant)
 	},
 }
 
@@ -81,10 +314,22 @@ func init() {
 	CreateTenantCommand.Flags().StringP("alias", "s", "", "Server alias")
 	CreateTenantCommand.ValidArgs = []string{"tenant"}
 	CreateDatabaseCommand.Flags().StringP("alias", "s", "", "Server alias")
-	CreateDatabaseCommand.Flags().StringVarP(&tenant, "tenant", "t", DefaultTenant, "Tenant name")
+	CreateDatabaseCommand.Flags().StringP("tenant", "t", "", "Tenant name. If not provided, the active tenant or the server default will be used.")
 	CreateDatabaseCommand.ValidArgs = []string{"db"}
+	GetTenantCommand.Flags().StringP("alias", "s", "", "Server alias")
+	ListDatabasesCommand.Flags().StringP("alias", "s", "", "Server alias")
+	ListDatabasesCommand.Flags().StringP("tenant", "t", "", "Tenant name. If not provided, the active tenant or the server default will be used.")
+	GetDatabaseCommand.Flags().StringP("alias", "s", "", "Server alias")
+	GetDatabaseCommand.Flags().StringP("tenant", "t", "", "Tenant name. If not provided, the active tenant or the server default will be used.")
+	RmDatabaseCommand.Flags().StringP("alias", "s", "", "Server alias")
+	RmDatabaseCommand.Flags().StringP("tenant", "t", "", "Tenant name. If not provided, the active tenant or the server default will be used.")
+	RmDatabaseCommand.Flags().BoolP("force", "f", false, "Remove the database without confirmation")
 	TenantCommand.AddCommand(CreateTenantCommand)
+	TenantCommand.AddCommand(GetTenantCommand)
 	DBCommand.AddCommand(CreateDatabaseCommand)
+	DBCommand.AddCommand(ListDatabasesCommand)
+	DBCommand.AddCommand(GetDatabaseCommand)
+	DBCommand.AddCommand(RmDatabaseCommand)
 	RootCmd.AddCommand(TenantCommand)
 	RootCmd.AddCommand(DBCommand)
 }
@@ -4,8 +4,11 @@ import (
 	"bytes"
 	"context"
 	"math/rand"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -69,7 +72,7 @@ func TestCreateDatabase(t *testing.T) {
 		defer tearDown(client)
 		var dbName = getRandomName("test-db")
 		buf := new(bytes.Buffer)
-		command.ResetFlags()
+		resetCommandFlags(command)
 		command.SetOut(buf)
 		command.SetErr(buf)
 		command.SetArgs([]string{"database", "create", dbName})
@@ -134,4 +137,100 @@ func TestCreateDatabase(t *testing.T) {
 		require.Contains(t, output, dbName)
 		require.Contains(t, output, tenantName)
 	})
+
+	t.Run("Create db active-tenant", func(t *testing.T) {
+		resetCommandFlags(CreateDatabaseCommand)
+		helperRestoreActiveContext(t)
+		client := setup()
+		defer tearDown(client)
+		var tenantName = getRandomName("test-tenant")
+		helperCreateTenant(t, client, tenantName)
+		viper.Set("active_tenant", tenantName)
+		var dbName = getRandomName("test-db")
+		buf := new(bytes.Buffer)
+		command.SetOut(buf)
+		command.SetErr(buf)
+		command.SetArgs([]string{"db", "c", dbName})
+		_, err := command.ExecuteC()
+		require.NoError(t, err)
+		_, err = client.GetDatabase(context.TODO(), dbName, &tenantName)
+		require.NoError(t, err, "the database must be created in the active tenant")
+	})
+}
+
+func TestGetTenant(t *testing.T) {
+	command := RootCmd
+	client := setup()
+	defer tearDown(client)
+	var tenantName = getRandomName("test-tenant")
+	helperCreateTenant(t, client, tenantName)
+	buf := new(bytes.Buffer)
+	command.SetOut(buf)
+	command.SetErr(buf)
+	command.SetArgs([]string{"tenant", "get", tenantName})
+	_, err := command.ExecuteC()
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), tenantName)
+}
+
+func TestListGetRemoveDatabase(t *testing.T) {
+	command := RootCmd
+	defer resetCommandFlags(ListDatabasesCommand)
+	defer resetCommandFlags(GetDatabaseCommand)
+	defer resetCommandFlags(RmDatabaseCommand)
+	client := setup()
+	defer tearDown(client)
+	var tenantName = getRandomName("test-tenant")
+	helperCreateTenant(t, client, tenantName)
+	var dbName = getRandomName("test-db")
+	_, err := client.CreateDatabase(context.TODO(), dbName, &tenantName)
+	require.NoError(t, err)
+	buf := new(bytes.Buffer)
+	command.SetOut(buf)
+	command.SetErr(buf)
+
+	command.SetArgs([]string{"db", "ls", "-t", tenantName})
+	_, err = command.ExecuteC()
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), dbName)
+
+	buf.Reset()
+	command.SetArgs([]string{"db", "get", dbName, "-t", tenantName})
+	_, err = command.ExecuteC()
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), dbName)
+
+	buf.Reset()
+	command.SetArgs([]string{"db", "rm", dbName, "-t", tenantName, "-f"})
+	_, err = command.ExecuteC()
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), "removed")
+	databases, err := listDatabases(context.TODO(), client, tenantName)
+	require.NoError(t, err)
+	require.Empty(t, databases)
+}
+
+func TestVerifyContextV2Only(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v2/heartbeat":
+			_, _ = w.Write([]byte(`{"nanosecond heartbeat":1}`))
+		case "/api/v2/tenants/acme":
+			_, _ = w.Write([]byte(`{"name":"acme"}`))
+		case "/api/v2/tenants/acme/databases/production":
+			_, _ = w.Write([]byte(`{"name":"production","tenant":"acme"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"NotFound"}`))
+		}
+	}))
+	defer server.Close()
+	client, err := chroma.NewClient(server.URL)
+	require.NoError(t, err)
+	ctx := context.TODO()
+
+	require.NoError(t, verifyContext(ctx, client, "acme", "production"))
+	require.EqualError(t, verifyContext(ctx, client, "acme", "staging"), "database staging does not exist in tenant acme")
+	require.EqualError(t, verifyContext(ctx, client, "other", "production"), "tenant other does not exist")
 }
@@ -0,0 +1,297 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	chroma "github.com/amikos-tech/chroma-go"
+	"github.com/amikos-tech/chroma-go/types"
+)
+
+// diffOptions controls how the records of two collections are compared.
+type diffOptions struct {
+	BatchSize int32
+	// Tolerance is the maximum absolute difference of two embedding values that are considered equal.
+	Tolerance         float64
+	CompareEmbeddings bool
+	// OnBatch is called for every batch of source records with the removed and changed records of the batch.
+	OnBatch func(records []recordItem, differences []recordDiffItem) error
+}
+
+// recordDiffItem is a record that differs between two collections.
+type recordDiffItem struct {
+	ID     string   `json:"id" yaml:"id"`
+	Status string   `json:"status" yaml:"status"`
+	Fields []string `json:"fields,omitempty" yaml:"fields,omitempty"`
+}
+
+// collectionDiffItem is the result of comparing two collections. Records only in the second collection are added,
+// records only in the first collection are removed.
+type collectionDiffItem struct {
+	Source      string           `json:"source" yaml:"source"`
+	Target      string           `json:"target" yaml:"target"`
+	Compared    int              `json:"compared" yaml:"compared"`
+	Added       int              `json:"added" yaml:"added"`
+	Removed     int              `json:"removed" yaml:"removed"`
+	Changed     int              `json:"changed" yaml:"changed"`
+	Differences []recordDiffItem `json:"differences" yaml:"differences"`
+}
+
+const (
+	diffAdded   = "added"
+	diffRemoved = "removed"
+	diffChanged = "changed"
+)
+
+func (d *collectionDiffItem) identical() bool {
+	return len(d.Differences) == 0
+}
+
+func (d *collectionDiffItem) summary() string {
+	if d.identical() {
+		return fmt.Sprintf("%v and %v are identical (%v records)", d.Source, d.Target, d.Compared)
+	}
+	return fmt.Sprintf("%v and %v differ: %v added, %v removed, %v changed", d.Source, d.Target, d.Added, d.Removed, d.Changed)
+}
+
+func (d *collectionDiffItem) add(id string, status string, fields ...string) {
+	d.Differences = append(d.Differences, recordDiffItem{ID: id, Status: status, Fields: fields})
+	switch status {
+	case diffAdded:
+		d.Added++
+	case diffRemoved:
+		d.Removed++
+	case diffChanged:
+		d.Changed++
+	}
+}
+
+// diffCollections compares the records of two collections. The source is read in batches and the records with the same
+// ids are fetched from the target, then the ids of the target are read to find the added records. Only the ids of the
+// source are kept in memory.
+func diffCollections(ctx context.Context, source *chroma.Collection, target *chroma.Collection, options diffOptions) (*collectionDiffItem, error) {
+	var diff = &collectionDiffItem{Differences: make([]recordDiffItem, 0)}
+	var include = []types.QueryEnum{types.IDocuments, types.IMetadatas}
+	if options.CompareEmbeddings {
+		include = append(include, types.IEmbeddings)
+	}
+	var sourceIDs = make(map[string]struct{})
+	err := forEachRecordBatch(ctx, source, options.BatchSize, nil, nil, include, func(records []recordItem) error {
+		var ids = make([]string, 0, len(records))
+		for _, record := range records {
+			ids = append(ids, record.ID)
+			sourceIDs[record.ID] = struct{}{}
+		}
+		result, err := target.GetWithOptions(ctx, types.WithIds(ids), types.WithLimit(int32(len(ids))), types.WithInclude(include...))
+		if err != nil {
+			return err
+		}
+		var targetRecords = make(map[string]recordItem, len(result.Ids))
+		for _, record := range recordItemsFromResult(result) {
+			targetRecords[record.ID] = record
+		}
+		first := len(diff.Differences)
+		for _, record := range records {
+			diff.Compared++
+			targetRecord, ok := targetRecords[record.ID]
+			if !ok {
+				diff.add(record.ID, diffRemoved)
+				continue
+			}
+			fields, err := diffRecords(record, targetRecord, options)
+			if err != nil {
+				return err
+			}
+			if len(fields) > 0 {
+				diff.add(record.ID, diffChanged, fields...)
+			}
+		}
+		if options.OnBatch != nil {
+			return options.OnBatch(records, diff.Differences[first:])
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare collections: %v", err)
+	}
+	err = forEachRecordBatch(ctx, target, options.BatchSize, nil, nil, []types.QueryEnum{}, func(records []recordItem) error {
+		for _, record := range records {
+			if _, ok := sourceIDs[record.ID]; !ok {
+				diff.add(record.ID, diffAdded)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the ids of %v: %v", target.Name, err)
+	}
+	return diff, nil
+}
+
+// diffRecords returns the fields that differ between two records with the same id.
+func diffRecords(a recordItem, b recordItem, options diffOptions) ([]string, error) {
+	var fields []string
+	if documentHash(a.Document) != documentHash(b.Document) {
+		fields = append(fields, documentColumn)
+	}
+	metadataA, err := json.Marshal(a.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	metadataB, err := json.Marshal(b.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(metadataA, metadataB) {
+		fields = append(fields, metadataColumn)
+	}
+	if options.CompareEmbeddings && !embeddingsEqual(a.Embedding, b.Embedding, options.Tolerance) {
+		fields = append(fields, embeddingColumn)
+	}
+	return fields, nil
+}
+
+// documentHash hashes a document. A missing document hashes to zeros, so it differs from an empty document.
+func documentHash(document *string) [sha256.Size]byte {
+	if document == nil {
+		return [sha256.Size]byte{}
+	}
+	return sha256.Sum256([]byte(*document))
+}
+
+func embeddingsEqual(a []float32, b []float32, tolerance float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if math.Abs(float64(a[i])-float64(b[i])) > tolerance {
+			return false
+		}
+	}
+	return true
+}
+
+func getDiffOptionsFromFlags(cmd *cobra.Command) (diffOptions, error) {
+	tolerance, err := cmd.Flags().GetFloat64("tolerance")
+	if err != nil {
+		return diffOptions{}, err
+	}
+	if tolerance < 0 {
+		return diffOptions{}, fmt.Errorf("tolerance must not be negative")
+	}
+	return diffOptions{Tolerance: tolerance, CompareEmbeddings: true}, nil
+}
+
+func diffCollectionsCommand(cmd *cobra.Command, args []string) error {
+	options, err := getDiffOptionsFromFlags(cmd)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	batchSize, err := cmd.Flags().GetInt("batch-size")
+	if err != nil {
+		return err
+	}
+	if batchSize <= 0 {
+		err := fmt.Errorf("batch-size must be greater than 0")
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	options.BatchSize = int32(batchSize)
+	if ignoreEmbeddings, err := cmd.Flags().GetBool("ignore-embeddings"); err != nil {
+		return err
+	} else if ignoreEmbeddings {
+		options.CompareEmbeddings = false
+	}
+	var refs = make([]collectionRef, 0, 2)
+	var collections = make([]*chroma.Collection, 0, 2)
+	for _, arg := range args {
+		ref, err := parseCollectionRef(arg)
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			return err
+		}
+		client, err := getClientForRef(cmd, ref)
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			return err
+		}
+		col, err := getCollection(client, ref.Collection)
+		if err != nil {
+			err = fmt.Errorf("collection %v not found: %v", ref, err)
+			cmd.Printf("%v\n", err)
+			return err
+		}
+		refs = append(refs, ref)
+		collections = append(collections, col)
+	}
+	diff, err := diffCollections(context.TODO(), collections[0], collections[1], options)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	diff.Source, diff.Target = refs[0].String(), refs[1].String()
+	var rows = make([][]string, 0, len(diff.Differences))
+	for _, d := range diff.Differences {
+		rows = append(rows, []string{d.Status, d.ID, strings.Join(d.Fields, ",")})
+	}
+	if !diff.identical() || isStructuredOutput(cmd) {
+		err = printOutput(cmd, &tableOutput{
+			Headers: []string{"STATUS", "ID", "FIELDS"},
+			Rows:    rows,
+			Items:   diff,
+		})
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			return err
+		}
+	}
+	if !isStructuredOutput(cmd) {
+		fmt.Fprintln(cmd.ErrOrStderr(), diff.summary())
+	}
+	if !diff.identical() {
+		return fmt.Errorf("%v", diff.summary())
+	}
+	return nil
+}
+
+var DiffCommand = &cobra.Command{
+	Use:   "diff <collection> <collection>",
+	Short: "Compare the records of two collections",
+	Long: `Compare the records of two collections by id, document, metadata and embedding. The collections can be on
+different servers, tenants or databases and are referenced like in chroma cp. Records only in the second collection are
+reported as added, records only in the first collection as removed. Embedding values are compared with --tolerance.
+The command exits with status 1 if the collections differ.`,
+	Example: `  chroma diff my-collection my-collection-copy
+  chroma diff prod:my-collection local:my-collection --tolerance 1e-4
+  chroma diff prod:my-collection local:my-collection --ignore-embeddings -o json`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		err := diffCollectionsCommand(cmd, args)
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+func addDiffFlags(command *cobra.Command) {
+	command.Flags().Float64("tolerance", 1e-6, "Maximum absolute difference of two embedding values that are considered equal")
+}
+
+func init() {
+	DiffCommand.Flags().StringP("alias", "s", "", "Server alias name. If not provided, the active server will be used.")
+	DiffCommand.Flags().StringP("tenant", "t", "", "Tenant name. If not provided, the active tenant or the server default will be used.")
+	DiffCommand.Flags().StringP("database", "d", "", "Database name. If not provided, the active database or the server default will be used.")
+	DiffCommand.Flags().IntP("batch-size", "b", 1000, "Number of records read from the collections per request")
+	DiffCommand.Flags().Bool("ignore-embeddings", false, "Do not compare the embeddings")
+	addDiffFlags(DiffCommand)
+	RootCmd.AddCommand(DiffCommand)
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/amikos-tech/chroma-go/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffRecords(t *testing.T) {
+	document := "hello"
+	other := "world"
+	record := recordItem{ID: "1", Document: &document, Metadata: map[string]interface{}{"a": 1.0, "b": "x"}, Embedding: []float32{0.1, 0.2}}
+	options := diffOptions{Tolerance: 1e-3, CompareEmbeddings: true}
+
+	fields, err := diffRecords(record, recordItem{ID: "1", Document: &document, Metadata: map[string]interface{}{"b": "x", "a": 1.0}, Embedding: []float32{0.1001, 0.2}}, options)
+	require.NoError(t, err)
+	require.Empty(t, fields)
+
+	fields, err = diffRecords(record, recordItem{ID: "1", Document: &other, Metadata: map[string]interface{}{"a": 2.0}, Embedding: []float32{0.2, 0.2}}, options)
+	require.NoError(t, err)
+	require.Equal(t, []string{documentColumn, metadataColumn, embeddingColumn}, fields)
+
+	fields, err = diffRecords(record, recordItem{ID: "1", Document: &document, Metadata: record.Metadata, Embedding: []float32{0.1}}, diffOptions{})
+	require.NoError(t, err)
+	require.Empty(t, fields, "embeddings are not compared")
+
+	empty := ""
+	fields, err = diffRecords(recordItem{ID: "1", Document: &empty}, recordItem{ID: "1"}, diffOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []string{documentColumn}, fields, "a missing document differs from an empty one")
+}
+
+func TestDiffCommand(t *testing.T) {
+	command := RootCmd
+
+	t.Run("Identical collections", func(t *testing.T) {
+		resetCloneCommandFlags()
+		client := setup()
+		defer tearDown(client)
+		var sourceCollectionName = getRandomName("diff-source")
+		var targetCollectionName = getRandomName("diff-target")
+		helperCreateCollection(t, client, sourceCollectionName)
+		addDummyRecordsToCollection(t, client, sourceCollectionName, 10)
+		buf := new(bytes.Buffer)
+		command.SetOut(buf)
+		command.SetErr(buf)
+		command.SetArgs([]string{"cp", sourceCollectionName, targetCollectionName, "--verify"})
+		_, err := command.ExecuteC()
+		require.NoError(t, err)
+		require.Contains(t, buf.String(), "verified: ")
+
+		buf.Reset()
+		command.SetArgs([]string{"diff", sourceCollectionName, targetCollectionName, "--batch-size", "3"})
+		_, err = command.ExecuteC()
+		require.NoError(t, err)
+		require.Contains(t, buf.String(), "are identical (10 records)")
+	})
+
+	t.Run("Added, removed and changed records", func(t *testing.T) {
+		client := setup()
+		defer tearDown(client)
+		var sourceCollectionName = getRandomName("diff-source")
+		var targetCollectionName = getRandomName("diff-target")
+		helperCreateCollection(t, client, sourceCollectionName)
+		helperCreateCollection(t, client, targetCollectionName)
+		addDummyRecordsToCollection(t, client, sourceCollectionName, 5)
+		addDummyRecordsToCollection(t, client, targetCollectionName, 6)
+		target, err := getCollection(client, targetCollectionName)
+		require.NoError(t, err)
+		_, err = target.Delete(context.TODO(), []string{"id-0"}, nil, nil)
+		require.NoError(t, err)
+		result, err := target.GetWithOptions(context.TODO(), types.WithIds([]string{"id-1"}), types.WithLimit(1), types.WithInclude(types.IDocuments, types.IEmbeddings))
+		require.NoError(t, err)
+		changed := recordItemsFromResult(result)
+		changed[0].Metadata = map[string]interface{}{"changed": true}
+		require.NoError(t, upsertRecords(context.TODO(), target, changed))
+		source, err := getCollection(client, sourceCollectionName)
+		require.NoError(t, err)
+
+		diff, err := diffCollections(context.TODO(), source, target, diffOptions{BatchSize: 2, CompareEmbeddings: true})
+		require.NoError(t, err)
+		require.Equal(t, 5, diff.Compared)
+		require.Equal(t, 1, diff.Added)
+		require.Equal(t, 1, diff.Removed)
+		require.Equal(t, 1, diff.Changed)
+		require.ElementsMatch(t, []recordDiffItem{
+			{ID: "id-0", Status: diffRemoved},
+			{ID: "id-1", Status: diffChanged, Fields: []string{metadataColumn}},
+			{ID: "id-5", Status: diffAdded},
+		}, diff.Differences)
+	})
+}
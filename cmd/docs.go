@@ -0,0 +1,559 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	chroma "github.com/amikos-tech/chroma-go"
+	openapi "github.com/amikos-tech/chroma-go/swagger"
+	"github.com/amikos-tech/chroma-go/types"
+)
+
+// getCollectionForCommand fetches the named collection from the server, tenant and database selected by the command
+// flags. If the command defines --embedding-function the collection is configured to use it.
+func getCollectionForCommand(cmd *cobra.Command, collectionName string) (*chroma.Collection, error) {
+	client, err := getClientForCommand(cmd)
+	if err != nil {
+		return nil, err
+	}
+	col, err := getCollection(client, collectionName)
+	if err != nil {
+		return nil, err
+	}
+	if cmd.Flag("embedding-function") != nil {
+		ef, err := embeddingFunctionForString(cmd.Flags().GetString("embedding-function"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid embedding-function: %v", err)
+		}
+		col.EmbeddingFunction = ef
+	}
+	return col, nil
+}
+
+// parseEmbedding parses a comma separated list of floats into an embedding.
+func parseEmbedding(value string) (*types.Embedding, error) {
+	parts := strings.Split(value, ",")
+	var embedding = make([]float32, 0, len(parts))
+	for _, part := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(part), 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid embedding value %v: %v", part, err)
+		}
+		embedding = append(embedding, float32(f))
+	}
+	return types.NewEmbeddingFromFloat32(embedding), nil
+}
+
+// embeddingValues returns the values of an embedding as float32 regardless of how they were returned by the API.
+func embeddingValues(embedding *types.Embedding) []float32 {
+	if embedding == nil {
+		return nil
+	}
+	if embedding.ArrayOfFloat32 != nil {
+		return *embedding.ArrayOfFloat32
+	}
+	if embedding.ArrayOfInt32 != nil {
+		var values = make([]float32, len(*embedding.ArrayOfInt32))
+		for i, v := range *embedding.ArrayOfInt32 {
+			values[i] = float32(v)
+		}
+		return values
+	}
+	return nil
+}
+
+// parseRecordMetadatas parses the per-record metadata flags. Each value is a comma separated list of key=value pairs.
+// A single value is applied to all records.
+func parseRecordMetadatas(values []string, numRecords int) ([]map[string]interface{}, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	if len(values) != 1 && len(values) != numRecords {
+		return nil, fmt.Errorf("expected 1 or %v metadata values, got %v", numRecords, len(values))
+	}
+	var metadatas = make([]map[string]interface{}, numRecords)
+	for i := range metadatas {
+		value := values[0]
+		if len(values) > 1 {
+			value = values[i]
+		}
+		metadata := make(map[string]interface{})
+		if err := parseMetadataPairs(strings.Split(value, ","), metadata); err != nil {
+			return nil, err
+		}
+		metadatas[i] = metadata
+	}
+	return metadatas, nil
+}
+
+// getIncludes are the fields get returns. Distances only exist for query results.
+var getIncludes = []types.QueryEnum{types.IDocuments, types.IMetadatas, types.IEmbeddings}
+
+// queryIncludes are the fields query returns.
+var queryIncludes = []types.QueryEnum{types.IDocuments, types.IMetadatas, types.IEmbeddings, types.IDistances}
+
+// parseInclude converts the values of the --include flag to query includes. Only the allowed fields are accepted.
+func parseInclude(values []string, allowed []types.QueryEnum) ([]types.QueryEnum, error) {
+	var include = make([]types.QueryEnum, 0, len(values))
+	for _, value := range values {
+		if !slices.Contains(allowed, types.QueryEnum(value)) {
+			var names = make([]string, 0, len(allowed))
+			for _, field := range allowed {
+				names = append(names, string(field))
+			}
+			return nil, fmt.Errorf("invalid include value: %v. must be one of %v", value, strings.Join(names, ", "))
+		}
+		include = append(include, types.QueryEnum(value))
+	}
+	return include, nil
+}
+
+// getRecordsFromFlags reads ids, documents, metadatas and embeddings of the records to write from the command flags.
+func getRecordsFromFlags(cmd *cobra.Command) ([]string, []string, []map[string]interface{}, []*types.Embedding, error) {
+	ids, err := cmd.Flags().GetStringSlice("id")
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil, nil, nil, fmt.Errorf("at least one id is required")
+	}
+	documents, err := cmd.Flags().GetStringArray("document")
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	if len(documents) > 0 && len(documents) != len(ids) {
+		return nil, nil, nil, nil, fmt.Errorf("expected %v documents, got %v", len(ids), len(documents))
+	}
+	metaValues, err := cmd.Flags().GetStringArray("meta")
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	metadatas, err := parseRecordMetadatas(metaValues, len(ids))
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	embeddingFlagValues, err := cmd.Flags().GetStringArray("embedding")
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	if len(embeddingFlagValues) > 0 && len(embeddingFlagValues) != len(ids) {
+		return nil, nil, nil, nil, fmt.Errorf("expected %v embeddings, got %v", len(ids), len(embeddingFlagValues))
+	}
+	var embeddings = make([]*types.Embedding, 0, len(embeddingFlagValues))
+	for _, value := range embeddingFlagValues {
+		embedding, err := parseEmbedding(value)
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+		embeddings = append(embeddings, embedding)
+	}
+	return ids, documents, metadatas, embeddings, nil
+}
+
+// updateRecords updates existing records. Unlike Collection.Modify it does not require an embedding function when
+// only metadata is updated.
+func updateRecords(ctx context.Context, col *chroma.Collection, embeddings []*types.Embedding, metadatas []map[string]interface{}, documents []string, ids []string) error {
+	if len(embeddings) == 0 && len(documents) > 0 {
+		if col.EmbeddingFunction == nil {
+			return fmt.Errorf("an embedding function or embeddings are required when updating documents")
+		}
+		var err error
+		embeddings, err = col.EmbeddingFunction.EmbedDocuments(ctx, documents)
+		if err != nil {
+			return err
+		}
+	}
+	_, _, err := col.ApiClient.DefaultApi.Update(ctx, col.ID).UpdateEmbedding(openapi.UpdateEmbedding{
+		Embeddings: types.ToAPIEmbeddings(embeddings),
+		Metadatas:  metadatas,
+		Documents:  documents,
+		Ids:        ids,
+	}).Execute()
+	return err
+}
+
+// recordItem is the structured representation of a record in the command output.
+type recordItem struct {
+	ID        string                 `json:"id" yaml:"id"`
+	Document  *string                `json:"document,omitempty" yaml:"document,omitempty"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	Embedding []float32              `json:"embedding,omitempty" yaml:"embedding,omitempty"`
+}
+
+// docsResultItem is the structured representation of the result of a write operation.
+type docsResultItem struct {
+	Collection string   `json:"collection" yaml:"collection"`
+	Operation  string   `json:"operation" yaml:"operation"`
+	IDs        []string `json:"ids" yaml:"ids"`
+}
+
+// countItem is the structured representation of the number of records in a collection.
+type countItem struct {
+	Collection string `json:"collection" yaml:"collection"`
+	Count      int32  `json:"count" yaml:"count"`
+}
+
+func printRecords(cmd *cobra.Command, result *chroma.GetResults) error {
+	items := recordItemsFromResult(result)
+	var rows = make([][]string, 0, len(items))
+	for _, item := range items {
+		var document, embedding string
+		if item.Document != nil {
+			document = *item.Document
+		}
+		if item.Embedding != nil {
+			embedding = fmt.Sprintf("%v", item.Embedding)
+		}
+		rows = append(rows, []string{item.ID, document, formatMetadata(item.Metadata), embedding})
+	}
+	return printOutput(cmd, &tableOutput{
+		Headers:     []string{"ID", "DOCUMENT", "METADATA"},
+		WideHeaders: []string{"EMBEDDING"},
+		Rows:        rows,
+		Items:       items,
+	})
+}
+
+func writeDocs(cmd *cobra.Command, args []string, upsert bool) error {
+	ids, documents, metadatas, embeddings, err := getRecordsFromFlags(cmd)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	if len(documents) == 0 && len(embeddings) == 0 {
+		err := fmt.Errorf("either documents or embeddings must be provided")
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	col, err := getCollectionForCommand(cmd, args[0])
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	if len(embeddings) == 0 && col.EmbeddingFunction == nil {
+		err := fmt.Errorf("an embedding function (--embedding-function) is required when no embeddings are provided")
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	if upsert {
+		_, err = col.Upsert(context.TODO(), embeddings, metadatas, documents, ids)
+	} else {
+		_, err = col.Add(context.TODO(), embeddings, metadatas, documents, ids)
+	}
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	message := fmt.Sprintf("Added %v records to collection %v", len(ids), col.Name)
+	item := docsResultItem{Collection: col.Name, Operation: "add", IDs: ids}
+	if upsert {
+		message = fmt.Sprintf("Upserted %v records in collection %v", len(ids), col.Name)
+		item.Operation = "upsert"
+	}
+	if err := printMessage(cmd, message, item); err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	return nil
+}
+
+var AddDocsCommand = &cobra.Command{
+	Use:   "add",
+	Short: "Add records to a collection",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		err := writeDocs(cmd, args, false)
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+var UpsertDocsCommand = &cobra.Command{
+	Use:   "upsert",
+	Short: "Add new or update existing records in a collection",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		err := writeDocs(cmd, args, true)
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+func updateDocs(cmd *cobra.Command, args []string) error {
+	ids, documents, metadatas, embeddings, err := getRecordsFromFlags(cmd)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	if len(documents) == 0 && len(embeddings) == 0 && len(metadatas) == 0 {
+		err := fmt.Errorf("nothing to update. provide documents, metadata or embeddings")
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	col, err := getCollectionForCommand(cmd, args[0])
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	err = updateRecords(context.TODO(), col, embeddings, metadatas, documents, ids)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	err = printMessage(cmd, fmt.Sprintf("Updated %v records in collection %v", len(ids), col.Name), docsResultItem{Collection: col.Name, Operation: "update", IDs: ids})
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	return nil
+}
+
+var UpdateDocsCommand = &cobra.Command{
+	Use:   "update",
+	Short: "Update existing records in a collection",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		err := updateDocs(cmd, args)
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+func getDocs(cmd *cobra.Command, args []string) error {
+	col, err := getCollectionForCommand(cmd, args[0])
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	var options = make([]types.CollectionQueryOption, 0)
+	ids, err := cmd.Flags().GetStringSlice("id")
+	if err != nil {
+		return err
+	}
+	if len(ids) > 0 {
+		options = append(options, types.WithIds(ids))
+	}
+	whereVal, err := cmd.Flags().GetString("where")
+	if err != nil {
+		return err
+	}
+	where, err := parseWhere(whereVal)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	if where != nil {
+		options = append(options, types.WithWhereMap(where))
+	}
+	whereDocumentVal, err := cmd.Flags().GetString("where-document")
+	if err != nil {
+		return err
+	}
+	whereDocument, err := parseWhereDocument(whereDocumentVal)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	if whereDocument != nil {
+		options = append(options, types.WithWhereDocumentMap(whereDocument))
+	}
+	if limit, err := getIntFlagIfChangedWithDefault(cmd, "limit", nil); err != nil {
+		cmd.Printf("invalid limit: %v\n", err)
+		return err
+	} else if limit != nil {
+		options = append(options, types.WithLimit(int32(*limit)))
+	}
+	if offset, err := getIntFlagIfChangedWithDefault(cmd, "offset", nil); err != nil {
+		cmd.Printf("invalid offset: %v\n", err)
+		return err
+	} else if offset != nil {
+		options = append(options, types.WithOffset(int32(*offset)))
+	}
+	includeVal, err := cmd.Flags().GetStringSlice("include")
+	if err != nil {
+		return err
+	}
+	include, err := parseInclude(includeVal, getIncludes)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	if len(include) > 0 {
+		options = append(options, types.WithInclude(include...))
+	}
+	result, err := col.GetWithOptions(context.TODO(), options...)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	if err := printRecords(cmd, result); err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	return nil
+}
+
+var GetDocsCommand = &cobra.Command{
+	Use:     "get",
+	Aliases: []string{"ls"},
+	Short:   "Get records from a collection",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		err := getDocs(cmd, args)
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+func deleteDocs(cmd *cobra.Command, args []string) error {
+	ids, err := cmd.Flags().GetStringSlice("id")
+	if err != nil {
+		return err
+	}
+	whereVal, err := cmd.Flags().GetString("where")
+	if err != nil {
+		return err
+	}
+	where, err := parseWhere(whereVal)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	whereDocumentVal, err := cmd.Flags().GetString("where-document")
+	if err != nil {
+		return err
+	}
+	whereDocument, err := parseWhereDocument(whereDocumentVal)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	if len(ids) == 0 && where == nil && whereDocument == nil {
+		err := fmt.Errorf("at least one of --id, --where or --where-document is required")
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	col, err := getCollectionForCommand(cmd, args[0])
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	deleted, err := col.Delete(context.TODO(), ids, where, whereDocument)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	err = printMessage(cmd, fmt.Sprintf("Deleted %v records from collection %v", len(deleted), col.Name), docsResultItem{Collection: col.Name, Operation: "delete", IDs: deleted})
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	return nil
+}
+
+var DeleteDocsCommand = &cobra.Command{
+	Use:     "delete",
+	Aliases: []string{"rm"},
+	Short:   "Delete records from a collection by id or filter",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		err := deleteDocs(cmd, args)
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+var CountDocsCommand = &cobra.Command{
+	Use:   "count",
+	Short: "Count the records in a collection",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		col, err := getCollectionForCommand(cmd, args[0])
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		count, err := col.Count(context.TODO())
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		err = printMessage(cmd, fmt.Sprintf("%v", count), countItem{Collection: col.Name, Count: count})
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var PeekDocsCommand = &cobra.Command{
+	Use:   "peek",
+	Short: "Show the first records of a collection",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		col, err := getCollectionForCommand(cmd, args[0])
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		limit, err := cmd.Flags().GetInt("limit")
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		result, err := col.GetWithOptions(context.TODO(), types.WithLimit(int32(limit)), types.WithInclude(types.IDocuments, types.IMetadatas))
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		err = printRecords(cmd, result)
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var DocsCommand = &cobra.Command{
+	Use:     "docs",
+	Aliases: []string{"documents", "records"},
+	Short:   "Manage the records of a collection",
+}
+
+func init() {
+	for _, c := range []*cobra.Command{AddDocsCommand, UpsertDocsCommand, UpdateDocsCommand, GetDocsCommand, DeleteDocsCommand, CountDocsCommand, PeekDocsCommand} {
+		c.Flags().StringP("alias", "s", "", "Server alias name. If not provided, the active server will be used.")
+		c.Flags().StringP("tenant", "t", "", "Tenant name. If not provided, the active tenant or the server default will be used.")
+		c.Flags().StringP("database", "d", "", "Database name. If not provided, the active database or the server default will be used.")
+		c.ValidArgs = []string{"collection"}
+		DocsCommand.AddCommand(c)
+	}
+	for _, c := range []*cobra.Command{AddDocsCommand, UpsertDocsCommand, UpdateDocsCommand} {
+		c.Flags().StringSliceP("id", "i", []string{}, "Record id. Can be repeated or comma separated.")
+		c.Flags().StringArray("document", []string{}, "Record document. Repeat once per id.")
+		c.Flags().StringArrayP("meta", "a", []string{}, "Record metadata as comma separated key=value pairs. Repeat once per id or provide once to apply to all records.")
+		c.Flags().StringArray("embedding", []string{}, "Record embedding as comma separated floats. Repeat once per id.")
+		c.Flags().StringP("embedding-function", "e", "", "The name of the embedding function used to embed documents when no embeddings are provided")
+	}
+	for _, c := range []*cobra.Command{GetDocsCommand, DeleteDocsCommand} {
+		c.Flags().StringSliceP("id", "i", []string{}, "Record id. Can be repeated or comma separated.")
+		c.Flags().StringP("where", "w", "", "Metadata filter as JSON or in the compact syntax, e.g. 'age>=30 AND tag in [a,b]'")
+		c.Flags().StringP("where-document", "W", "", "Document filter as JSON or in the compact syntax, e.g. 'contains hello'")
+	}
+	GetDocsCommand.Flags().IntP("limit", "l", 0, "Maximum number of records to return")
+	GetDocsCommand.Flags().Int("offset", 0, "Number of records to skip")
+	GetDocsCommand.Flags().StringSlice("include", []string{string(types.IDocuments), string(types.IMetadatas)}, "Fields to include: documents, metadatas, embeddings")
+	PeekDocsCommand.Flags().IntP("limit", "l", 10, "Number of records to show")
+	RootCmd.AddCommand(DocsCommand)
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amikos-tech/chroma-go/types"
+)
+
+// resetCommandFlags restores all flags of the command to their defaults so that commands can be executed repeatedly.
+func resetCommandFlags(command *cobra.Command) {
+	command.Flags().VisitAll(func(f *pflag.Flag) {
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			var values = make([]string, 0)
+			if defaults := strings.Trim(f.DefValue, "[]"); defaults != "" {
+				values = strings.Split(defaults, ",")
+			}
+			_ = sv.Replace(values)
+		} else {
+			_ = f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	})
+}
+
+func resetDocsCommandFlags() {
+	for _, c := range DocsCommand.Commands() {
+		resetCommandFlags(c)
+	}
+}
+
+func TestParseEmbedding(t *testing.T) {
+	embedding, err := parseEmbedding("0.1, 0.2,3")
+	require.NoError(t, err)
+	require.Equal(t, []float32{0.1, 0.2, 3}, embeddingValues(embedding))
+
+	_, err = parseEmbedding("0.1,abc")
+	require.Error(t, err)
+}
+
+func TestParseRecordMetadatas(t *testing.T) {
+	t.Run("Single value applies to all", func(t *testing.T) {
+		metadatas, err := parseRecordMetadatas([]string{"a=5,b=x"}, 2)
+		require.NoError(t, err)
+		require.Len(t, metadatas, 2)
+		require.Equal(t, int64(5), metadatas[1]["a"])
+		require.Equal(t, "x", metadatas[1]["b"])
+	})
+	t.Run("One per record", func(t *testing.T) {
+		metadatas, err := parseRecordMetadatas([]string{"a=true", "a=1.5"}, 2)
+		require.NoError(t, err)
+		require.Equal(t, true, metadatas[0]["a"])
+		require.Equal(t, float32(1.5), metadatas[1]["a"])
+	})
+	t.Run("Count mismatch", func(t *testing.T) {
+		_, err := parseRecordMetadatas([]string{"a=1", "a=2"}, 3)
+		require.Error(t, err)
+	})
+	t.Run("Invalid pair", func(t *testing.T) {
+		_, err := parseRecordMetadatas([]string{"a"}, 1)
+		require.Error(t, err)
+	})
+}
+
+func TestParseInclude(t *testing.T) {
+	include, err := parseInclude([]string{"documents", "embeddings"}, getIncludes)
+	require.NoError(t, err)
+	require.Equal(t, []types.QueryEnum{types.IDocuments, types.IEmbeddings}, include)
+	_, err = parseInclude([]string{"uris"}, queryIncludes)
+	require.Error(t, err)
+	_, err = parseInclude([]string{"distances"}, getIncludes)
+	require.EqualError(t, err, "invalid include value: distances. must be one of documents, metadatas, embeddings")
+	include, err = parseInclude([]string{"distances"}, queryIncludes)
+	require.NoError(t, err)
+	require.Equal(t, []types.QueryEnum{types.IDistances}, include)
+}
+
+func TestDocsCommands(t *testing.T) {
+	command := RootCmd
+
+	t.Run("Add and get docs", func(t *testing.T) {
+		resetDocsCommandFlags()
+		client := setup()
+		defer tearDown(client)
+		var collectionName = getRandomName("docs-collection")
+		helperCreateCollection(t, client, collectionName)
+		buf := new(bytes.Buffer)
+		command.SetOut(buf)
+		command.SetErr(buf)
+		command.SetArgs([]string{"docs", "add", collectionName, "-i", "id1,id2", "--document", "first doc", "--document", "second doc", "-a", "tag=x", "-e", "hash"})
+		_, err := command.ExecuteC()
+		require.NoError(t, err)
+		require.Contains(t, buf.String(), "Added 2 records")
+		col := assertCollectionExists(t, client, collectionName)
+		count, err := col.Count(context.TODO())
+		require.NoError(t, err)
+		require.Equal(t, int32(2), count)
+
+		resetDocsCommandFlags()
+		buf.Reset()
+		command.SetArgs([]string{"docs", "get", collectionName, "-i", "id2"})
+		_, err = command.ExecuteC()
+		require.NoError(t, err)
+		require.Contains(t, buf.String(), "second doc")
+		require.NotContains(t, buf.String(), "first doc")
+	})
+
+	t.Run("Update metadata and delete docs", func(t *testing.T) {
+		resetDocsCommandFlags()
+		client := setup()
+		defer tearDown(client)
+		var collectionName = getRandomName("docs-collection")
+		helperCreateCollection(t, client, collectionName)
+		addDummyRecordsToCollection(t, client, collectionName, 10)
+		buf := new(bytes.Buffer)
+		command.SetOut(buf)
+		command.SetErr(buf)
+		command.SetArgs([]string{"docs", "update", collectionName, "-i", "id-1", "-a", "updated=true"})
+		_, err := command.ExecuteC()
+		require.NoError(t, err)
+		require.Contains(t, buf.String(), "Updated 1 records")
+
+		resetDocsCommandFlags()
+		buf.Reset()
+		command.SetArgs([]string{"docs", "rm", collectionName, "-w", `{"updated": true}`})
+		_, err = command.ExecuteC()
+		require.NoError(t, err)
+		require.Contains(t, buf.String(), "Deleted 1 records")
+
+		resetDocsCommandFlags()
+		buf.Reset()
+		command.SetArgs([]string{"docs", "count", collectionName})
+		_, err = command.ExecuteC()
+		require.NoError(t, err)
+		require.Equal(t, "9\n", buf.String())
+	})
+}
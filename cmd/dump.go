@@ -0,0 +1,484 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/charmbracelet/huh"
+	"github.com/klauspost/compress/zstd"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	chroma "github.com/amikos-tech/chroma-go"
+	"github.com/amikos-tech/chroma-go/collection"
+	"github.com/amikos-tech/chroma-go/types"
+)
+
+const (
+	// dumpVersion is the version of the dump format written by chroma dump. Dumps with a newer version are rejected
+	// by chroma load.
+	dumpVersion = 1
+
+	EnvChromaDumpPassphrase = "CHROMA_DUMP_PASSPHRASE"
+)
+
+// dumpHeader is the first line of a dump. It is followed by the records of the collection as JSONL. The whole
+// stream is compressed with zstd and encrypted with age.
+type dumpHeader struct {
+	Version    int                    `json:"version"`
+	Collection string                 `json:"collection"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
+
+// dumpResultItem is the structured representation of the result of a dump or a load.
+type dumpResultItem struct {
+	Collection string `json:"collection" yaml:"collection"`
+	File       string `json:"file" yaml:"file"`
+	Encryption string `json:"encryption" yaml:"encryption"`
+	Records    int    `json:"records" yaml:"records"`
+}
+
+// readPassphrase returns the passphrase from --passphrase-file, CHROMA_DUMP_PASSPHRASE or a prompt. A new passphrase
+// has to be entered twice.
+func readPassphrase(cmd *cobra.Command, confirm bool) (string, error) {
+	file, err := cmd.Flags().GetString("passphrase-file")
+	if err != nil {
+		return "", err
+	}
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return "", err
+		}
+		passphrase := strings.TrimRight(string(data), "\r\n")
+		if passphrase == "" {
+			return "", fmt.Errorf("passphrase file %v is empty", file)
+		}
+		return passphrase, nil
+	}
+	if passphrase := os.Getenv(EnvChromaDumpPassphrase); passphrase != "" {
+		return passphrase, nil
+	}
+	if !isInteractive() {
+		return "", fmt.Errorf("a passphrase is required. use --passphrase-file or set %v", EnvChromaDumpPassphrase)
+	}
+	return promptPassphrase("Passphrase", confirm)
+}
+
+// promptPassphrase asks for a passphrase on the terminal, twice if confirm is set.
+func promptPassphrase(title string, confirm bool) (string, error) {
+	var passphrase, confirmation string
+	err := huh.NewInput().Title(title).Password(true).Value(&passphrase).Validate(func(s string) error {
+		if s == "" {
+			return errors.New("the passphrase must not be empty")
+		}
+		return nil
+	}).Run()
+	if err != nil {
+		return "", fmt.Errorf("unable to read the passphrase: %v", err)
+	}
+	if !confirm {
+		return passphrase, nil
+	}
+	err = huh.NewInput().Title("Confirm passphrase").Password(true).Value(&confirmation).Run()
+	if err != nil {
+		return "", fmt.Errorf("unable to read the passphrase: %v", err)
+	}
+	if passphrase != confirmation {
+		return "", fmt.Errorf("the passphrases do not match")
+	}
+	return passphrase, nil
+}
+
+// getDumpRecipients returns the age recipients of a dump and a description of the encryption. Without --recipient
+// or --recipients-file the dump is encrypted with a passphrase.
+func getDumpRecipients(cmd *cobra.Command) ([]age.Recipient, string, error) {
+	keys, err := cmd.Flags().GetStringSlice("recipient")
+	if err != nil {
+		return nil, "", err
+	}
+	files, err := cmd.Flags().GetStringSlice("recipients-file")
+	if err != nil {
+		return nil, "", err
+	}
+	var recipients = make([]age.Recipient, 0, len(keys))
+	for _, key := range keys {
+		recipient, err := age.ParseX25519Recipient(key)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid recipient %v: %v", key, err)
+		}
+		recipients = append(recipients, recipient)
+	}
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, "", err
+		}
+		parsed, err := age.ParseRecipients(f)
+		_ = f.Close()
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid recipients file %v: %v", file, err)
+		}
+		recipients = append(recipients, parsed...)
+	}
+	if len(recipients) > 0 {
+		if cmd.Flags().Changed("passphrase-file") {
+			return nil, "", fmt.Errorf("--passphrase-file cannot be combined with recipients")
+		}
+		return recipients, "recipients", nil
+	}
+	passphrase, err := readPassphrase(cmd, true)
+	if err != nil {
+		return nil, "", err
+	}
+	recipient, err := age.NewScryptRecipient(passphrase)
+	if err != nil {
+		return nil, "", err
+	}
+	return []age.Recipient{recipient}, "passphrase", nil
+}
+
+// getDumpIdentities returns the age identities to decrypt a dump with. Without --identity the passphrase is used.
+func getDumpIdentities(cmd *cobra.Command) ([]age.Identity, string, error) {
+	files, err := cmd.Flags().GetStringSlice("identity")
+	if err != nil {
+		return nil, "", err
+	}
+	if len(files) == 0 {
+		passphrase, err := readPassphrase(cmd, false)
+		if err != nil {
+			return nil, "", err
+		}
+		identity, err := age.NewScryptIdentity(passphrase)
+		if err != nil {
+			return nil, "", err
+		}
+		return []age.Identity{identity}, "passphrase", nil
+	}
+	var identities = make([]age.Identity, 0, len(files))
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, "", err
+		}
+		parsed, err := age.ParseIdentities(f)
+		_ = f.Close()
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid identity file %v: %v", file, err)
+		}
+		identities = append(identities, parsed...)
+	}
+	return identities, "recipients", nil
+}
+
+// writeDump writes the records of a collection to w, compressed and encrypted to the recipients.
+func writeDump(ctx context.Context, w io.Writer, col *chroma.Collection, recipients []age.Recipient, armored bool, batchSize int32) (int, error) {
+	var out = w
+	var armorWriter io.WriteCloser
+	if armored {
+		armorWriter = armor.NewWriter(w)
+		out = armorWriter
+	}
+	encrypted, err := age.Encrypt(out, recipients...)
+	if err != nil {
+		return 0, err
+	}
+	compressed, err := zstd.NewWriter(encrypted)
+	if err != nil {
+		return 0, err
+	}
+	header, err := json.Marshal(dumpHeader{Version: dumpVersion, Collection: col.Name, Metadata: col.Metadata, CreatedAt: time.Now().UTC()})
+	if err != nil {
+		return 0, err
+	}
+	if _, err := compressed.Write(append(header, '\n')); err != nil {
+		return 0, err
+	}
+	writer, err := newRecordWriter(compressed, RecordFormatJSONL, nil, true)
+	if err != nil {
+		return 0, err
+	}
+	var dumped int
+	err = forEachRecordBatch(ctx, col, batchSize, nil, nil, []types.QueryEnum{types.IDocuments, types.IMetadatas, types.IEmbeddings}, func(records []recordItem) error {
+		dumped += len(records)
+		return writer.Write(records)
+	})
+	if err != nil {
+		return dumped, err
+	}
+	if err := writer.Close(); err != nil {
+		return dumped, err
+	}
+	if err := compressed.Close(); err != nil {
+		return dumped, err
+	}
+	if err := encrypted.Close(); err != nil {
+		return dumped, err
+	}
+	if armorWriter != nil {
+		return dumped, armorWriter.Close()
+	}
+	return dumped, nil
+}
+
+// openDump decrypts a dump, armored or not, and returns its header and a reader of the records.
+func openDump(r io.Reader, identities []age.Identity) (*dumpHeader, io.Reader, func(), error) {
+	var in io.Reader = bufio.NewReader(r)
+	if peeked, _ := in.(*bufio.Reader).Peek(len(armor.Header)); string(peeked) == armor.Header {
+		in = armor.NewReader(in)
+	}
+	decrypted, err := age.Decrypt(in, identities...)
+	if err != nil {
+		var noMatch *age.NoIdentityMatchError
+		if errors.As(err, &noMatch) {
+			return nil, nil, nil, fmt.Errorf("unable to decrypt the dump: wrong passphrase or identity")
+		}
+		return nil, nil, nil, fmt.Errorf("unable to decrypt the dump: %v", err)
+	}
+	decompressed, err := zstd.NewReader(decrypted)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	records := bufio.NewReader(decompressed)
+	line, err := records.ReadBytes('\n')
+	if err != nil {
+		decompressed.Close()
+		return nil, nil, nil, fmt.Errorf("invalid dump: %v", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	decoder.UseNumber()
+	var header dumpHeader
+	if err := decoder.Decode(&header); err != nil {
+		decompressed.Close()
+		return nil, nil, nil, fmt.Errorf("invalid dump header: %v", err)
+	}
+	if header.Version < 1 || header.Version > dumpVersion {
+		decompressed.Close()
+		return nil, nil, nil, fmt.Errorf("unsupported dump version %v. upgrade the chroma CLI", header.Version)
+	}
+	var mapping recordMapping
+	for key, value := range header.Metadata {
+		header.Metadata[key] = mapping.metadataValue(value)
+	}
+	return &header, records, decompressed.Close, nil
+}
+
+func dumpCollection(cmd *cobra.Command, args []string) error {
+	file, err := cmd.Flags().GetString("file")
+	if err != nil {
+		return err
+	}
+	armored, err := cmd.Flags().GetBool("armor")
+	if err != nil {
+		return err
+	}
+	batchSize, err := cmd.Flags().GetInt("batch-size")
+	if err != nil {
+		return err
+	}
+	if batchSize <= 0 {
+		err := fmt.Errorf("batch-size must be greater than 0")
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	var out io.Writer = cmd.OutOrStdout()
+	if file == "" || file == "-" {
+		if f, ok := out.(*os.File); ok && term.IsTerminal(int(f.Fd())) && !armored {
+			err := fmt.Errorf("refusing to write an encrypted dump to a terminal. use --file or --armor")
+			cmd.Printf("%v\n", err)
+			return err
+		}
+	}
+	recipients, encryption, err := getDumpRecipients(cmd)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	col, err := getCollectionForCommand(cmd, args[0])
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	var tmp string
+	if file != "" && file != "-" {
+		// the dump is written next to the file and renamed when complete so a failed dump leaves no partial file
+		tmp = file + ".tmp"
+		f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			return err
+		}
+		defer func() {
+			_ = f.Close()
+			_ = os.Remove(tmp)
+		}()
+		out = f
+	}
+	dumped, err := writeDump(context.TODO(), out, col, recipients, armored, int32(batchSize))
+	if err != nil {
+		err = fmt.Errorf("failed to dump collection %v: %v", col.Name, err)
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	if tmp == "" {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Dumped %v records from collection %v\n", dumped, col.Name)
+		return nil
+	}
+	if err := out.(*os.File).Close(); err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	if err := os.Rename(tmp, file); err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	err = printMessage(cmd, fmt.Sprintf("Dumped %v records from collection %v to %v", dumped, col.Name, file), dumpResultItem{Collection: col.Name, File: file, Encryption: encryption, Records: dumped})
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	return nil
+}
+
+func loadDump(cmd *cobra.Command, args []string) error {
+	file := args[0]
+	batchSize, err := cmd.Flags().GetInt("batch-size")
+	if err != nil {
+		return err
+	}
+	if batchSize <= 0 {
+		err := fmt.Errorf("batch-size must be greater than 0")
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	identities, encryption, err := getDumpIdentities(cmd)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	var in = cmd.InOrStdin()
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	header, records, closeDump, err := openDump(in, identities)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	defer closeDump()
+	var collectionName = header.Collection
+	if len(args) > 1 {
+		collectionName = args[1]
+	}
+	client, err := getClientForCommand(cmd)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	ctx := context.TODO()
+	exists, err := collectionExists(client, collectionName)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	var col *chroma.Collection
+	if exists {
+		col, err = getCollection(client, collectionName)
+	} else {
+		col, err = createScopedCollection(ctx, client, collection.WithName(collectionName), collection.WithMetadatas(header.Metadata))
+		if err == nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "created collection %v\n", collectionName)
+		}
+	}
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	loaded, err := restoreCollectionRecords(ctx, col, records, batchSize)
+	if err != nil {
+		err = fmt.Errorf("failed to load records into collection %v after %v records: %v", col.Name, loaded, err)
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	err = printMessage(cmd, fmt.Sprintf("Loaded %v records into collection %v", loaded, col.Name), dumpResultItem{Collection: col.Name, File: file, Encryption: encryption, Records: loaded})
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	return nil
+}
+
+var DumpCommand = &cobra.Command{
+	Use:   "dump <collection>",
+	Short: "Write the records of a collection to an encrypted file",
+	Long: `Write the metadata and records of a collection, including the embeddings, to a zstd compressed file encrypted
+with age. The file is encrypted with a passphrase (read from --passphrase-file, the CHROMA_DUMP_PASSPHRASE env var or
+a prompt) or to the public keys given with --recipient and --recipients-file. Dumps are never written in plaintext.
+Use chroma load to restore a dump.`,
+	Args: cobra.ExactArgs(1),
+	Example: `  chroma dump my-collection -f my-collection.age
+  chroma dump my-collection -f my-collection.age -r age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p
+  CHROMA_DUMP_PASSPHRASE=... chroma dump my-collection --armor > my-collection.age`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := dumpCollection(cmd, args)
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+var LoadCommand = &cobra.Command{
+	Use:   "load <file> [collection]",
+	Short: "Load the records of an encrypted dump into a collection",
+	Long: `Decrypt a file written by chroma dump and upsert its records into a collection. The records are loaded into the
+dumped collection unless another collection is given. Missing collections are created with the dumped metadata.
+Passphrase protected dumps read the passphrase like chroma dump, dumps encrypted to public keys are decrypted with
+the age identity files given with --identity.`,
+	Args: cobra.RangeArgs(1, 2),
+	Example: `  chroma load my-collection.age
+  chroma load my-collection.age my-collection-copy -i ~/.config/age/keys.txt`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := loadDump(cmd, args)
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	DumpCommand.Flags().StringP("alias", "s", "", "Server alias name. If not provided, the active server will be used.")
+	DumpCommand.Flags().StringP("tenant", "t", "", "Tenant name. If not provided, the active tenant or the server default will be used.")
+	DumpCommand.Flags().StringP("database", "d", "", "Database name. If not provided, the active database or the server default will be used.")
+	DumpCommand.Flags().StringP("file", "f", "", "Output file. If not provided or -, the dump is written to stdout.")
+	DumpCommand.Flags().IntP("batch-size", "b", 1000, "Number of records read from the collection per request")
+	DumpCommand.Flags().StringSliceP("recipient", "r", nil, "Encrypt to the age public key. Can be repeated.")
+	DumpCommand.Flags().StringSliceP("recipients-file", "R", nil, "Encrypt to the age public keys listed in the file. Can be repeated.")
+	DumpCommand.Flags().String("passphrase-file", "", "Read the passphrase from the file")
+	DumpCommand.Flags().BoolP("armor", "a", false, "Write an ASCII armored (PEM) dump")
+	RootCmd.AddCommand(DumpCommand)
+
+	LoadCommand.Flags().StringP("alias", "s", "", "Server alias name. If not provided, the active server will be used.")
+	LoadCommand.Flags().StringP("tenant", "t", "", "Tenant name. If not provided, the active tenant or the server default will be used.")
+	LoadCommand.Flags().StringP("database", "d", "", "Database name. If not provided, the active database or the server default will be used.")
+	LoadCommand.Flags().IntP("batch-size", "b", 100, "Number of records upserted per request")
+	LoadCommand.Flags().StringSliceP("identity", "i", nil, "Decrypt with the age identity file. Can be repeated.")
+	LoadCommand.Flags().String("passphrase-file", "", "Read the passphrase from the file")
+	RootCmd.AddCommand(LoadCommand)
+}
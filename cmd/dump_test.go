@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDumpLoad(t *testing.T) {
+	command := RootCmd
+	defer resetCommandFlags(DumpCommand)
+	defer resetCommandFlags(LoadCommand)
+	client := setup()
+	defer tearDown(client)
+	var collectionName = getRandomName("dump")
+	helperCreateCollection(t, client, collectionName)
+	addDummyRecordsToCollection(t, client, collectionName, 12)
+	source, err := getCollection(client, collectionName)
+	require.NoError(t, err)
+	dir := t.TempDir()
+	buf := new(bytes.Buffer)
+	command.SetOut(buf)
+	command.SetErr(buf)
+
+	t.Run("Passphrase", func(t *testing.T) {
+		t.Setenv(EnvChromaDumpPassphrase, "correct horse battery staple")
+		resetCommandFlags(DumpCommand)
+		buf.Reset()
+		file := filepath.Join(dir, "passphrase.age")
+		command.SetArgs([]string{"dump", collectionName, "-f", file, "-b", "5"})
+		_, err := command.ExecuteC()
+		require.NoError(t, err)
+		require.Contains(t, buf.String(), "Dumped 12 records")
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		require.NotContains(t, string(data), "record-1")
+
+		resetCommandFlags(LoadCommand)
+		buf.Reset()
+		command.SetArgs([]string{"load", file, collectionName + "-loaded", "-b", "5"})
+		_, err = command.ExecuteC()
+		require.NoError(t, err)
+		require.Contains(t, buf.String(), "Loaded 12 records")
+		loaded, err := getCollection(client, collectionName+"-loaded")
+		require.NoError(t, err)
+		diff, err := diffCollections(context.TODO(), source, loaded, diffOptions{BatchSize: 100, CompareEmbeddings: true})
+		require.NoError(t, err)
+		require.True(t, diff.identical(), diff.summary())
+
+		t.Setenv(EnvChromaDumpPassphrase, "wrong")
+		resetCommandFlags(LoadCommand)
+		require.NoError(t, LoadCommand.ParseFlags([]string{}))
+		err = loadDump(LoadCommand, []string{file})
+		require.ErrorContains(t, err, "wrong passphrase or identity")
+	})
+
+	t.Run("Recipient", func(t *testing.T) {
+		identity, err := age.GenerateX25519Identity()
+		require.NoError(t, err)
+		identityFile := filepath.Join(dir, "key.txt")
+		require.NoError(t, os.WriteFile(identityFile, []byte(identity.String()+"\n"), 0600))
+		resetCommandFlags(DumpCommand)
+		buf.Reset()
+		file := filepath.Join(dir, "recipient.age")
+		command.SetArgs([]string{"dump", collectionName, "-f", file, "-r", identity.Recipient().String(), "--armor"})
+		_, err = command.ExecuteC()
+		require.NoError(t, err)
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		require.Contains(t, string(data), "-----BEGIN AGE ENCRYPTED FILE-----")
+
+		resetCommandFlags(LoadCommand)
+		buf.Reset()
+		command.SetArgs([]string{"load", file, "-i", identityFile})
+		_, err = command.ExecuteC()
+		require.NoError(t, err)
+		require.Contains(t, buf.String(), "Loaded 12 records into collection "+collectionName)
+	})
+
+	t.Run("Not a dump", func(t *testing.T) {
+		file := filepath.Join(dir, "plain.jsonl")
+		require.NoError(t, os.WriteFile(file, []byte(`{"id":"1"}`+"\n"), 0600))
+		t.Setenv(EnvChromaDumpPassphrase, "correct horse battery staple")
+		resetCommandFlags(LoadCommand)
+		require.NoError(t, LoadCommand.ParseFlags([]string{}))
+		err := loadDump(LoadCommand, []string{file})
+		require.ErrorContains(t, err, "unable to decrypt the dump")
+	})
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/amikos-tech/chroma-go/types"
+)
+
+// exportResultItem is the structured representation of the result of an export.
+type exportResultItem struct {
+	Collection string `json:"collection" yaml:"collection"`
+	File       string `json:"file" yaml:"file"`
+	Format     string `json:"format" yaml:"format"`
+	Records    int    `json:"records" yaml:"records"`
+}
+
+func exportCollection(cmd *cobra.Command, args []string) error {
+	file, err := cmd.Flags().GetString("file")
+	if err != nil {
+		return err
+	}
+	formatVal, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return err
+	}
+	format, err := getRecordFileFormat(formatVal, file)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	batchSize, err := cmd.Flags().GetInt("batch-size")
+	if err != nil {
+		return err
+	}
+	if batchSize <= 0 {
+		err := fmt.Errorf("batch-size must be greater than 0")
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	includeEmbeddings, err := cmd.Flags().GetBool("include-embeddings")
+	if err != nil {
+		return err
+	}
+	whereVal, err := cmd.Flags().GetString("where")
+	if err != nil {
+		return err
+	}
+	where, err := parseWhere(whereVal)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	whereDocumentVal, err := cmd.Flags().GetString("where-document")
+	if err != nil {
+		return err
+	}
+	whereDocument, err := parseWhereDocument(whereDocumentVal)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	col, err := getCollectionForCommand(cmd, args[0])
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	ctx := context.TODO()
+	var metadataKeys []string
+	if format == RecordFormatCSV {
+		metadataKeys, err = collectMetadataKeys(ctx, col, int32(batchSize), where, whereDocument)
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			return err
+		}
+	}
+	var out io.Writer = cmd.OutOrStdout()
+	if file != "" && file != "-" {
+		f, err := os.Create(file)
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+	writer, err := newRecordWriter(out, format, metadataKeys, includeEmbeddings)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	var include = []types.QueryEnum{types.IDocuments, types.IMetadatas}
+	if includeEmbeddings {
+		include = append(include, types.IEmbeddings)
+	}
+	var exported int
+	err = forEachRecordBatch(ctx, col, int32(batchSize), where, whereDocument, include, func(records []recordItem) error {
+		exported += len(records)
+		return writer.Write(records)
+	})
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	if file == "" || file == "-" {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Exported %v records from collection %v\n", exported, col.Name)
+		return nil
+	}
+	err = printMessage(cmd, fmt.Sprintf("Exported %v records from collection %v to %v", exported, col.Name, file), exportResultItem{Collection: col.Name, File: file, Format: string(format), Records: exported})
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	return nil
+}
+
+var ExportCommand = &cobra.Command{
+	Use:   "export",
+	Short: "Export the records of a collection to a JSONL, CSV or Parquet file",
+	Long: `Export the records of a collection to a JSONL, CSV or Parquet file or to stdout. Records are read in batches.
+The format is inferred from the file extension unless --format is given. CSV files have one column per metadata key,
+Parquet files store the metadata as a JSON object.`,
+	Args: cobra.ExactArgs(1),
+	Example: `  chroma export my-collection -f records.jsonl
+  chroma export my-collection -f records.parquet --include-embeddings
+  chroma export my-collection --format csv --where 'source=web' > records.csv`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := exportCollection(cmd, args)
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	ExportCommand.Flags().StringP("alias", "s", "", "Server alias name. If not provided, the active server will be used.")
+	ExportCommand.Flags().StringP("tenant", "t", "", "Tenant name. If not provided, the active tenant or the server default will be used.")
+	ExportCommand.Flags().StringP("database", "d", "", "Database name. If not provided, the active database or the server default will be used.")
+	ExportCommand.Flags().StringP("file", "f", "", "Output file. If not provided or -, the records are written to stdout.")
+	ExportCommand.Flags().String("format", "", "File format: jsonl, csv or parquet. Inferred from the file extension if not provided.")
+	ExportCommand.Flags().IntP("batch-size", "b", 1000, "Number of records read from the collection per request")
+	ExportCommand.Flags().Bool("include-embeddings", false, "Include the embeddings in the export")
+	ExportCommand.Flags().StringP("where", "w", "", "Metadata filter as JSON or in the compact syntax, e.g. 'age>=30 AND tag in [a,b]'")
+	ExportCommand.Flags().StringP("where-document", "W", "", "Document filter as JSON or in the compact syntax, e.g. 'contains hello'")
+	RootCmd.AddCommand(ExportCommand)
+}
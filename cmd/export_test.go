@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportCommand(t *testing.T) {
+	command := RootCmd
+
+	t.Run("Export to JSONL stdout", func(t *testing.T) {
+		resetCommandFlags(ExportCommand)
+		client := setup()
+		defer tearDown(client)
+		var collectionName = getRandomName("export-collection")
+		helperCreateCollection(t, client, collectionName)
+		addDummyRecordsToCollection(t, client, collectionName, 25)
+		out := new(bytes.Buffer)
+		command.SetOut(out)
+		command.SetErr(new(bytes.Buffer))
+		command.SetArgs([]string{"export", collectionName, "-b", "10", "--include-embeddings"})
+		_, err := command.ExecuteC()
+		require.NoError(t, err)
+		var ids = make(map[string]bool)
+		scanner := bufio.NewScanner(out)
+		for scanner.Scan() {
+			var record recordItem
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+			require.NotNil(t, record.Document)
+			require.NotEmpty(t, record.Embedding)
+			ids[record.ID] = true
+		}
+		require.Len(t, ids, 25)
+	})
+
+	t.Run("Export to CSV file", func(t *testing.T) {
+		resetCommandFlags(ExportCommand)
+		client := setup()
+		defer tearDown(client)
+		var collectionName = getRandomName("export-collection")
+		helperCreateCollection(t, client, collectionName)
+		addDummyRecordsToCollection(t, client, collectionName, 5)
+		file := filepath.Join(t.TempDir(), "records.csv")
+		buf := new(bytes.Buffer)
+		command.SetOut(buf)
+		command.SetErr(buf)
+		command.SetArgs([]string{"export", collectionName, "-f", file})
+		_, err := command.ExecuteC()
+		require.NoError(t, err)
+		require.Contains(t, buf.String(), "Exported 5 records")
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		require.Len(t, lines, 6)
+		require.Equal(t, "id,document", lines[0])
+	})
+}
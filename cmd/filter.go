@@ -0,0 +1,327 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// The compact filter syntax compiles expressions such as `age>=30 AND tag in [a,b]` into Chroma's where filters.
+//
+// Metadata filters (--where):
+//
+//	key = value, key == value, key != value, key > value, key >= value, key < value, key <= value
+//	key in [v1, v2], key not in [v1, v2] (or key nin [v1, v2])
+//
+// Document filters (--where-document):
+//
+//	contains "text", not contains "text" (or not_contains "text")
+//
+// Conditions can be combined with AND and OR (case-insensitive) and grouped with parentheses. AND binds tighter than
+// OR. Values are parsed as numbers or booleans when possible, quoted values are always strings.
+
+type filterTokenKind int
+
+const (
+	tokenWord filterTokenKind = iota
+	tokenString
+	tokenOperator
+	tokenLParen
+	tokenRParen
+	tokenLBracket
+	tokenRBracket
+	tokenComma
+	tokenEOF
+)
+
+type filterToken struct {
+	kind  filterTokenKind
+	value string
+	pos   int
+}
+
+func isFilterWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_.:-+$", r)
+}
+
+func tokenizeFilter(input string) ([]filterToken, error) {
+	var tokens = make([]filterToken, 0)
+	runes := []rune(input)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, filterToken{kind: tokenLParen, value: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, filterToken{kind: tokenRParen, value: ")", pos: i})
+			i++
+		case r == '[':
+			tokens = append(tokens, filterToken{kind: tokenLBracket, value: "[", pos: i})
+			i++
+		case r == ']':
+			tokens = append(tokens, filterToken{kind: tokenRBracket, value: "]", pos: i})
+			i++
+		case r == ',':
+			tokens = append(tokens, filterToken{kind: tokenComma, value: ",", pos: i})
+			i++
+		case r == '"' || r == '\'':
+			var sb strings.Builder
+			start := i
+			i++
+			for ; i < len(runes) && runes[i] != r; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				sb.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated string at position %v", start)
+			}
+			i++
+			tokens = append(tokens, filterToken{kind: tokenString, value: sb.String(), pos: start})
+		case strings.ContainsRune("=!<>", r):
+			start := i
+			i++
+			if i < len(runes) && runes[i] == '=' {
+				i++
+			}
+			op := string(runes[start:i])
+			if op == "!" {
+				return nil, fmt.Errorf("invalid operator at position %v", start)
+			}
+			tokens = append(tokens, filterToken{kind: tokenOperator, value: op, pos: start})
+		case isFilterWordRune(r):
+			start := i
+			for i < len(runes) && isFilterWordRune(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, filterToken{kind: tokenWord, value: string(runes[start:i]), pos: start})
+		default:
+			return nil, fmt.Errorf("unexpected character %q at position %v", r, i)
+		}
+	}
+	tokens = append(tokens, filterToken{kind: tokenEOF, pos: len(runes)})
+	return tokens, nil
+}
+
+type filterParser struct {
+	tokens   []filterToken
+	pos      int
+	document bool
+}
+
+func (p *filterParser) peek() filterToken {
+	return p.tokens[p.pos]
+}
+
+func (p *filterParser) next() filterToken {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *filterParser) isKeyword(t filterToken, keyword string) bool {
+	return t.kind == tokenWord && strings.EqualFold(t.value, keyword)
+}
+
+func (p *filterParser) parseExpression() (map[string]interface{}, error) {
+	return p.parseBinary("or", func() (map[string]interface{}, error) {
+		return p.parseBinary("and", p.parseTerm)
+	})
+}
+
+// parseBinary parses operands joined by the given keyword and combines them with the matching $and/$or operator.
+func (p *filterParser) parseBinary(keyword string, operand func() (map[string]interface{}, error)) (map[string]interface{}, error) {
+	first, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	var operands = []interface{}{first}
+	for p.isKeyword(p.peek(), keyword) {
+		p.next()
+		o, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, o)
+	}
+	if len(operands) == 1 {
+		return first, nil
+	}
+	return map[string]interface{}{"$" + keyword: operands}, nil
+}
+
+func (p *filterParser) parseTerm() (map[string]interface{}, error) {
+	t := p.peek()
+	if t.kind == tokenLParen {
+		p.next()
+		expr, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, fmt.Errorf("expected ')' at position %v", closing.pos)
+		}
+		return expr, nil
+	}
+	if p.document {
+		return p.parseDocumentCondition()
+	}
+	return p.parseMetadataCondition()
+}
+
+func (p *filterParser) parseDocumentCondition() (map[string]interface{}, error) {
+	t := p.next()
+	operator := "$contains"
+	switch {
+	case p.isKeyword(t, "not"):
+		if c := p.next(); !p.isKeyword(c, "contains") {
+			return nil, fmt.Errorf("expected 'contains' at position %v", c.pos)
+		}
+		operator = "$not_contains"
+	case p.isKeyword(t, "not_contains"):
+		operator = "$not_contains"
+	case p.isKeyword(t, "contains"):
+	default:
+		return nil, fmt.Errorf("expected 'contains' or 'not contains' at position %v", t.pos)
+	}
+	value := p.next()
+	if value.kind != tokenString && value.kind != tokenWord {
+		return nil, fmt.Errorf("expected text at position %v", value.pos)
+	}
+	return map[string]interface{}{operator: value.value}, nil
+}
+
+func (p *filterParser) parseMetadataCondition() (map[string]interface{}, error) {
+	key := p.next()
+	if key.kind != tokenWord && key.kind != tokenString {
+		return nil, fmt.Errorf("expected metadata key at position %v", key.pos)
+	}
+	t := p.next()
+	var operator string
+	switch {
+	case t.kind == tokenOperator:
+		operator = map[string]string{"=": "$eq", "==": "$eq", "!=": "$ne", ">": "$gt", ">=": "$gte", "<": "$lt", "<=": "$lte"}[t.value]
+		if operator == "" {
+			return nil, fmt.Errorf("invalid operator %v at position %v", t.value, t.pos)
+		}
+	case p.isKeyword(t, "in"):
+		operator = "$in"
+	case p.isKeyword(t, "nin"):
+		operator = "$nin"
+	case p.isKeyword(t, "not"):
+		if in := p.next(); !p.isKeyword(in, "in") {
+			return nil, fmt.Errorf("expected 'in' at position %v", in.pos)
+		}
+		operator = "$nin"
+	default:
+		return nil, fmt.Errorf("expected operator after %v at position %v", key.value, t.pos)
+	}
+	if operator == "$in" || operator == "$nin" {
+		values, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{key.value: map[string]interface{}{operator: values}}, nil
+	}
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{key.value: map[string]interface{}{operator: value}}, nil
+}
+
+func (p *filterParser) parseList() ([]interface{}, error) {
+	if t := p.next(); t.kind != tokenLBracket {
+		return nil, fmt.Errorf("expected '[' at position %v", t.pos)
+	}
+	var values = make([]interface{}, 0)
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+		t := p.next()
+		if t.kind == tokenRBracket {
+			return values, nil
+		}
+		if t.kind != tokenComma {
+			return nil, fmt.Errorf("expected ',' or ']' at position %v", t.pos)
+		}
+	}
+}
+
+func (p *filterParser) parseValue() (interface{}, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenString:
+		return t.value, nil
+	case tokenWord:
+		if strings.EqualFold(t.value, "true") || strings.EqualFold(t.value, "false") {
+			return strings.EqualFold(t.value, "true"), nil
+		}
+		if i, err := strconv.ParseInt(t.value, 10, 64); err == nil {
+			return i, nil
+		}
+		if f, err := strconv.ParseFloat(t.value, 64); err == nil {
+			return f, nil
+		}
+		return t.value, nil
+	default:
+		return nil, fmt.Errorf("expected value at position %v", t.pos)
+	}
+}
+
+func compileFilter(input string, document bool) (map[string]interface{}, error) {
+	tokens, err := tokenizeFilter(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &filterParser{tokens: tokens, document: document}
+	expr, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at position %v", t.value, t.pos)
+	}
+	return expr, nil
+}
+
+// parseFilter parses a filter given either as raw JSON or in the compact filter syntax.
+func parseFilter(value string, document bool) (map[string]interface{}, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	if strings.HasPrefix(value, "{") {
+		var filter map[string]interface{}
+		if err := json.Unmarshal([]byte(value), &filter); err != nil {
+			return nil, fmt.Errorf("invalid filter %v: %v", value, err)
+		}
+		return filter, nil
+	}
+	filter, err := compileFilter(value, document)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter %v: %v", value, err)
+	}
+	return filter, nil
+}
+
+// parseWhere parses a metadata filter given as JSON or in the compact filter syntax.
+func parseWhere(value string) (map[string]interface{}, error) {
+	return parseFilter(value, false)
+}
+
+// parseWhereDocument parses a document filter given as JSON or in the compact filter syntax.
+func parseWhereDocument(value string) (map[string]interface{}, error) {
+	return parseFilter(value, true)
+}
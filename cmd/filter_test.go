@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func requireFilterJSON(t *testing.T, expected string, filter map[string]interface{}) {
+	actual, err := json.Marshal(filter)
+	require.NoError(t, err)
+	require.JSONEq(t, expected, string(actual))
+}
+
+func TestParseWhere(t *testing.T) {
+	t.Run("Single comparison", func(t *testing.T) {
+		where, err := parseWhere("age>=30")
+		require.NoError(t, err)
+		requireFilterJSON(t, `{"age": {"$gte": 30}}`, where)
+	})
+	t.Run("And with in list", func(t *testing.T) {
+		where, err := parseWhere("age>=30 AND tag in [a,b]")
+		require.NoError(t, err)
+		requireFilterJSON(t, `{"$and": [{"age": {"$gte": 30}}, {"tag": {"$in": ["a", "b"]}}]}`, where)
+	})
+	t.Run("Or binds looser than and", func(t *testing.T) {
+		where, err := parseWhere("a = 1 and b != 'x y' or c < 2.5")
+		require.NoError(t, err)
+		requireFilterJSON(t, `{"$or": [{"$and": [{"a": {"$eq": 1}}, {"b": {"$ne": "x y"}}]}, {"c": {"$lt": 2.5}}]}`, where)
+	})
+	t.Run("Parentheses", func(t *testing.T) {
+		where, err := parseWhere("active == true AND (tag not in [x, \"y\"] OR score <= -1)")
+		require.NoError(t, err)
+		requireFilterJSON(t, `{"$and": [{"active": {"$eq": true}}, {"$or": [{"tag": {"$nin": ["x", "y"]}}, {"score": {"$lte": -1}}]}]}`, where)
+	})
+	t.Run("Raw JSON", func(t *testing.T) {
+		where, err := parseWhere(`{"age": {"$gt": 1}}`)
+		require.NoError(t, err)
+		requireFilterJSON(t, `{"age": {"$gt": 1}}`, where)
+	})
+	t.Run("Empty", func(t *testing.T) {
+		where, err := parseWhere("")
+		require.NoError(t, err)
+		require.Nil(t, where)
+	})
+	t.Run("Invalid", func(t *testing.T) {
+		for _, filter := range []string{"age >", "age ! 3", "age in 3", "(age > 3", "age > 3 extra", "tag in [a,", `{"age": `} {
+			_, err := parseWhere(filter)
+			require.Error(t, err, filter)
+		}
+	})
+}
+
+func TestParseWhereDocument(t *testing.T) {
+	t.Run("Contains", func(t *testing.T) {
+		where, err := parseWhereDocument("contains hello")
+		require.NoError(t, err)
+		requireFilterJSON(t, `{"$contains": "hello"}`, where)
+	})
+	t.Run("Combined", func(t *testing.T) {
+		where, err := parseWhereDocument(`contains "hello world" AND not contains bye`)
+		require.NoError(t, err)
+		requireFilterJSON(t, `{"$and": [{"$contains": "hello world"}, {"$not_contains": "bye"}]}`, where)
+	})
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := parseWhereDocument("hello")
+		require.Error(t, err)
+	})
+}
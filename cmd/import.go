@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	chroma "github.com/amikos-tech/chroma-go"
+	"github.com/amikos-tech/chroma-go/collection"
+)
+
+// importResultItem is the structured representation of the result of an import.
+type importResultItem struct {
+	Collection string `json:"collection" yaml:"collection"`
+	File       string `json:"file" yaml:"file"`
+	Format     string `json:"format" yaml:"format"`
+	Records    int    `json:"records" yaml:"records"`
+}
+
+// getImportCollection returns the target collection of an import, creating it with the HNSW flags if --create is set.
+func getImportCollection(cmd *cobra.Command, collectionName string) (*chroma.Collection, error) {
+	create, err := cmd.Flags().GetBool("create")
+	if err != nil {
+		return nil, err
+	}
+	if !create {
+		return getCollectionForCommand(cmd, collectionName)
+	}
+	client, err := getClientForCommand(cmd)
+	if err != nil {
+		return nil, err
+	}
+	ef, err := embeddingFunctionForString(cmd.Flags().GetString("embedding-function"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid embedding-function: %v", err)
+	}
+	options, err := getHNSWOptionsFromFlags(cmd)
+	if err != nil {
+		return nil, err
+	}
+	options = append(options, collection.WithName(collectionName), collection.WithCreateIfNotExist(true))
+	if ef != nil {
+		options = append(options, collection.WithEmbeddingFunction(ef))
+	}
+	col, err := createScopedCollection(context.TODO(), client, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create collection: %v", err)
+	}
+	col.EmbeddingFunction = ef
+	return col, nil
+}
+
+func importRecords(cmd *cobra.Command, args []string) error {
+	collectionName, file := args[0], args[1]
+	formatVal, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return err
+	}
+	format, err := getRecordFileFormat(formatVal, file)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	var mapping = recordMapping{ParseStrings: format == RecordFormatCSV}
+	if mapping.IDColumn, err = cmd.Flags().GetString("id-col"); err != nil {
+		return err
+	}
+	if mapping.DocumentColumn, err = cmd.Flags().GetString("doc-col"); err != nil {
+		return err
+	}
+	if mapping.EmbeddingColumn, err = cmd.Flags().GetString("embedding-col"); err != nil {
+		return err
+	}
+	if cmd.Flags().Changed("meta-cols") {
+		if mapping.MetadataColumns, err = cmd.Flags().GetStringSlice("meta-cols"); err != nil {
+			return err
+		}
+		if mapping.MetadataColumns == nil {
+			mapping.MetadataColumns = []string{}
+		}
+	}
+	idGenerator, err := cmd.Flags().GetString("id-gen")
+	if err != nil {
+		return err
+	}
+	switch IDGenerator(idGenerator) {
+	case IDGeneratorUUID, IDGeneratorULID, IDGeneratorHash:
+	default:
+		err := fmt.Errorf("invalid id-gen: %v. must be one of uuid, ulid, hash", idGenerator)
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	batchSize, err := cmd.Flags().GetInt("import-batch-size")
+	if err != nil {
+		return err
+	}
+	if batchSize <= 0 {
+		err := fmt.Errorf("import-batch-size must be greater than 0")
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	reader, err := openRecordRowReader(file, format, cmd.InOrStdin())
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	defer reader.Close()
+	col, err := getImportCollection(cmd, collectionName)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	ctx := context.TODO()
+	var imported int
+	var batch = make([]recordItem, 0, batchSize)
+	for row := 1; ; row++ {
+		values, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			err = fmt.Errorf("failed to read record %v: %v", row, err)
+			cmd.Printf("%v\n", err)
+			return err
+		}
+		record, err := mapping.record(values)
+		if err != nil {
+			err = fmt.Errorf("invalid record %v: %v", row, err)
+			cmd.Printf("%v\n", err)
+			return err
+		}
+		if strings.TrimSpace(record.ID) == "" {
+			if record.ID, err = generateRecordID(IDGenerator(idGenerator), record); err != nil {
+				err = fmt.Errorf("invalid record %v: %v", row, err)
+				cmd.Printf("%v\n", err)
+				return err
+			}
+		}
+		batch = append(batch, record)
+		if len(batch) == batchSize {
+			if err := upsertRecords(ctx, col, batch); err != nil {
+				err = fmt.Errorf("failed to import records %v-%v: %v", row-len(batch)+1, row, err)
+				cmd.Printf("%v\n", err)
+				return err
+			}
+			imported += len(batch)
+			batch = batch[:0]
+		}
+	}
+	if err := upsertRecords(ctx, col, batch); err != nil {
+		err = fmt.Errorf("failed to import records %v-%v: %v", imported+1, imported+len(batch), err)
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	imported += len(batch)
+	err = printMessage(cmd, fmt.Sprintf("Imported %v records into collection %v", imported, col.Name), importResultItem{Collection: col.Name, File: file, Format: string(format), Records: imported})
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	return nil
+}
+
+var ImportCommand = &cobra.Command{
+	Use:   "import",
+	Short: "Import records from a JSONL, CSV or Parquet file into a collection",
+	Long: `Import records from a JSONL, CSV or Parquet file into a collection. The records are upserted in batches.
+The format is inferred from the file extension unless --format is given. Use - as the file to read JSONL or CSV from
+stdin.
+
+By default the id, document and embedding columns are mapped to the record fields and all other columns become
+metadata. Object values, such as the metadata column written by chroma export, are merged into the metadata. Records
+without an id get a generated one and records without an embedding are embedded with the embedding function.`,
+	Args: cobra.ExactArgs(2),
+	Example: `  chroma import my-collection records.jsonl
+  chroma import my-collection articles.csv --id-col url --doc-col body --meta-cols author,year -e openai
+  chroma import my-collection records.parquet --create --space cosine --id-gen hash`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := importRecords(cmd, args)
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	ImportCommand.Flags().StringP("alias", "s", "", "Server alias name. If not provided, the active server will be used.")
+	ImportCommand.Flags().StringP("tenant", "t", "", "Tenant name. If not provided, the active tenant or the server default will be used.")
+	ImportCommand.Flags().StringP("database", "d", "", "Database name. If not provided, the active database or the server default will be used.")
+	ImportCommand.Flags().String("format", "", "File format: jsonl, csv or parquet. Inferred from the file extension if not provided.")
+	ImportCommand.Flags().String("id-col", idColumn, "Column holding the record ids")
+	ImportCommand.Flags().String("doc-col", documentColumn, "Column holding the documents")
+	ImportCommand.Flags().String("embedding-col", embeddingColumn, "Column holding the embeddings as a list of numbers")
+	ImportCommand.Flags().StringSlice("meta-cols", nil, "Columns to import as metadata. If not provided, all other columns are imported as metadata.")
+	ImportCommand.Flags().String("id-gen", string(IDGeneratorUUID), "Id generator for records without an id: uuid, ulid or hash (SHA-256 of the document)")
+	ImportCommand.Flags().StringP("embedding-function", "e", "", "The name of the embedding function used to embed documents without embeddings")
+	ImportCommand.Flags().IntP("import-batch-size", "z", 100, "Number of records upserted per request")
+	ImportCommand.Flags().Bool("create", false, "Create the collection if it does not exist, using the HNSW flags")
+	addHNSWFlags(ImportCommand)
+	RootCmd.AddCommand(ImportCommand)
+}
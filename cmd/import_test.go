@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportCommand(t *testing.T) {
+	command := RootCmd
+
+	t.Run("Import exported records", func(t *testing.T) {
+		resetCommandFlags(ExportCommand)
+		resetCommandFlags(ImportCommand)
+		client := setup()
+		defer tearDown(client)
+		var collectionName = getRandomName("import-collection")
+		helperCreateCollection(t, client, collectionName)
+		addDummyRecordsToCollection(t, client, collectionName, 15)
+		file := filepath.Join(t.TempDir(), "records.parquet")
+		buf := new(bytes.Buffer)
+		command.SetOut(buf)
+		command.SetErr(buf)
+		command.SetArgs([]string{"export", collectionName, "-f", file, "--include-embeddings"})
+		_, err := command.ExecuteC()
+		require.NoError(t, err)
+
+		var targetName = getRandomName("import-target")
+		command.SetArgs([]string{"import", targetName, file, "--create", "--space", "cosine", "-z", "4"})
+		_, err = command.ExecuteC()
+		require.NoError(t, err)
+		require.Contains(t, buf.String(), "Imported 15 records")
+		col := assertCollectionExists(t, client, targetName)
+		require.Equal(t, "cosine", col.Metadata["hnsw:space"])
+		count, err := col.Count(context.TODO())
+		require.NoError(t, err)
+		require.Equal(t, int32(15), count)
+	})
+
+	t.Run("Import CSV with column mapping", func(t *testing.T) {
+		resetCommandFlags(ImportCommand)
+		client := setup()
+		defer tearDown(client)
+		var collectionName = getRandomName("import-collection")
+		helperCreateCollection(t, client, collectionName)
+		file := filepath.Join(t.TempDir(), "articles.csv")
+		require.NoError(t, os.WriteFile(file, []byte("url,body,author,year\nu1,first article,ann,2020\n,second article,bob,2021\n"), 0644))
+		buf := new(bytes.Buffer)
+		command.SetOut(buf)
+		command.SetErr(buf)
+		command.SetArgs([]string{"import", collectionName, file, "--id-col", "url", "--doc-col", "body", "--meta-cols", "year", "--id-gen", "hash", "-e", "hash"})
+		_, err := command.ExecuteC()
+		require.NoError(t, err)
+		require.Contains(t, buf.String(), "Imported 2 records")
+		col := assertCollectionExists(t, client, collectionName)
+		result, err := col.Get(context.TODO(), nil, nil, []string{"u1"}, nil)
+		require.NoError(t, err)
+		require.Equal(t, []string{"first article"}, result.Documents)
+		require.Equal(t, map[string]interface{}{"year": float64(2020)}, result.Metadatas[0])
+	})
+}
@@ -0,0 +1,553 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The jsonpath output implements the subset of the kubectl JSONPath templates that is useful for the CLI output:
+//
+//	{.items[*].name}                        fields, array wildcards and the * field wildcard
+//	{.items[0].id}, {.items[-1:]}           indexes and slices
+//	{..id}                                  recursive descent
+//	{.items[?(@.count>10)].name}            filters with ==, !=, <, <=, >, >= or existence checks
+//	{range .items[*]}{.name}{"\n"}{end}     iteration
+//	{"\t"}                                  quoted literals
+//
+// Text outside of the braces is printed as is. Multiple results of a single expression are separated by a space.
+
+type jsonPathNode struct {
+	text  string         // literal text
+	path  []jsonPathStep // expression to print
+	body  []jsonPathNode // range body
+	isRng bool
+}
+
+type jsonPathStepKind int
+
+const (
+	stepField jsonPathStepKind = iota
+	stepRecursive
+	stepWildcard
+	stepIndex
+	stepSlice
+	stepFilter
+)
+
+type jsonPathStep struct {
+	kind   jsonPathStepKind
+	name   string
+	index  int
+	start  *int
+	end    *int
+	filter *jsonPathFilter
+}
+
+type jsonPathFilter struct {
+	path     []jsonPathStep
+	operator string
+	value    interface{}
+}
+
+type jsonPath struct {
+	nodes []jsonPathNode
+}
+
+// parseJSONPath parses a kubectl style JSONPath template.
+func parseJSONPath(template string) (*jsonPath, error) {
+	nodes, _, err := parseJSONPathNodes(template, false)
+	if err != nil {
+		return nil, err
+	}
+	return &jsonPath{nodes: nodes}, nil
+}
+
+// parseJSONPathNodes parses nodes until the end of the template or, inside a range, until the matching {end}.
+func parseJSONPathNodes(template string, inRange bool) ([]jsonPathNode, string, error) {
+	var nodes = make([]jsonPathNode, 0)
+	for len(template) > 0 {
+		open := strings.IndexByte(template, '{')
+		if open < 0 {
+			nodes = append(nodes, jsonPathNode{text: template})
+			break
+		}
+		if open > 0 {
+			nodes = append(nodes, jsonPathNode{text: template[:open]})
+		}
+		closing, err := findJSONPathClose(template, open)
+		if err != nil {
+			return nil, "", err
+		}
+		expr := strings.TrimSpace(template[open+1 : closing])
+		template = template[closing+1:]
+		switch {
+		case expr == "end":
+			if !inRange {
+				return nil, "", fmt.Errorf("invalid jsonpath: unexpected {end}")
+			}
+			return nodes, template, nil
+		case strings.HasPrefix(expr, "range "):
+			path, err := parseJSONPathExpression(strings.TrimSpace(strings.TrimPrefix(expr, "range ")))
+			if err != nil {
+				return nil, "", err
+			}
+			body, rest, err := parseJSONPathNodes(template, true)
+			if err != nil {
+				return nil, "", err
+			}
+			nodes = append(nodes, jsonPathNode{path: path, body: body, isRng: true})
+			template = rest
+		case strings.HasPrefix(expr, "\"") || strings.HasPrefix(expr, "'"):
+			text, err := unquoteJSONPathLiteral(expr)
+			if err != nil {
+				return nil, "", fmt.Errorf("invalid jsonpath literal %v: %v", expr, err)
+			}
+			nodes = append(nodes, jsonPathNode{text: text})
+		default:
+			path, err := parseJSONPathExpression(expr)
+			if err != nil {
+				return nil, "", err
+			}
+			nodes = append(nodes, jsonPathNode{path: path})
+		}
+	}
+	if inRange {
+		return nil, "", fmt.Errorf("invalid jsonpath: missing {end}")
+	}
+	return nodes, "", nil
+}
+
+// findJSONPathClose returns the position of the brace closing the expression opened at open, skipping quoted strings.
+func findJSONPathClose(template string, open int) (int, error) {
+	var quote byte
+	for i := open + 1; i < len(template); i++ {
+		c := template[i]
+		switch {
+		case quote != 0 && c == '\\':
+			i++
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote != 0:
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '}':
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid jsonpath: unclosed expression at position %v", open)
+}
+
+func unquoteJSONPathLiteral(value string) (string, error) {
+	if strings.HasPrefix(value, "'") {
+		if len(value) < 2 || !strings.HasSuffix(value, "'") {
+			return "", fmt.Errorf("unterminated string")
+		}
+		return value[1 : len(value)-1], nil
+	}
+	return strconv.Unquote(value)
+}
+
+// parseJSONPathExpression parses a path such as .items[*].metadata.name.
+func parseJSONPathExpression(expr string) ([]jsonPathStep, error) {
+	var steps = make([]jsonPathStep, 0)
+	rest := strings.TrimPrefix(expr, "$")
+	if rest == "" || rest == "." || rest == "@" {
+		return steps, nil
+	}
+	rest = strings.TrimPrefix(rest, "@")
+	for len(rest) > 0 {
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			name, remaining := readJSONPathName(rest[2:])
+			if name == "" {
+				return nil, fmt.Errorf("invalid jsonpath %v: expected a field name after '..'", expr)
+			}
+			steps = append(steps, jsonPathStep{kind: stepRecursive, name: name})
+			rest = remaining
+		case strings.HasPrefix(rest, ".*"):
+			steps = append(steps, jsonPathStep{kind: stepWildcard})
+			rest = rest[2:]
+		case strings.HasPrefix(rest, "."):
+			name, remaining := readJSONPathName(rest[1:])
+			if name == "" {
+				if remaining == "" {
+					return steps, nil
+				}
+				return nil, fmt.Errorf("invalid jsonpath %v: expected a field name after '.'", expr)
+			}
+			steps = append(steps, jsonPathStep{kind: stepField, name: name})
+			rest = remaining
+		case strings.HasPrefix(rest, "["):
+			end := findJSONPathBracketClose(rest)
+			if end < 0 {
+				return nil, fmt.Errorf("invalid jsonpath %v: unclosed '['", expr)
+			}
+			step, err := parseJSONPathBracket(rest[1:end])
+			if err != nil {
+				return nil, fmt.Errorf("invalid jsonpath %v: %v", expr, err)
+			}
+			steps = append(steps, step)
+			rest = rest[end+1:]
+		default:
+			name, remaining := readJSONPathName(rest)
+			if name == "" {
+				return nil, fmt.Errorf("invalid jsonpath %v: unexpected %q", expr, rest)
+			}
+			steps = append(steps, jsonPathStep{kind: stepField, name: name})
+			rest = remaining
+		}
+	}
+	return steps, nil
+}
+
+func readJSONPathName(value string) (string, string) {
+	i := 0
+	for i < len(value) && value[i] != '.' && value[i] != '[' {
+		i++
+	}
+	return value[:i], value[i:]
+}
+
+func findJSONPathBracketClose(value string) int {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote != 0:
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func parseJSONPathBracket(content string) (jsonPathStep, error) {
+	content = strings.TrimSpace(content)
+	switch {
+	case content == "*":
+		return jsonPathStep{kind: stepWildcard}, nil
+	case strings.HasPrefix(content, "?(") && strings.HasSuffix(content, ")"):
+		filter, err := parseJSONPathFilter(strings.TrimSpace(content[2 : len(content)-1]))
+		if err != nil {
+			return jsonPathStep{}, err
+		}
+		return jsonPathStep{kind: stepFilter, filter: filter}, nil
+	case strings.HasPrefix(content, "'") || strings.HasPrefix(content, "\""):
+		name, err := unquoteJSONPathLiteral(content)
+		if err != nil {
+			return jsonPathStep{}, err
+		}
+		return jsonPathStep{kind: stepField, name: name}, nil
+	case strings.Contains(content, ":"):
+		bounds := strings.SplitN(content, ":", 2)
+		step := jsonPathStep{kind: stepSlice}
+		for i, bound := range bounds {
+			bound = strings.TrimSpace(bound)
+			if bound == "" {
+				continue
+			}
+			n, err := strconv.Atoi(bound)
+			if err != nil {
+				return jsonPathStep{}, fmt.Errorf("invalid slice bound %v", bound)
+			}
+			if i == 0 {
+				step.start = &n
+			} else {
+				step.end = &n
+			}
+		}
+		return step, nil
+	default:
+		n, err := strconv.Atoi(content)
+		if err != nil {
+			return jsonPathStep{}, fmt.Errorf("invalid index %v", content)
+		}
+		return jsonPathStep{kind: stepIndex, index: n}, nil
+	}
+}
+
+func parseJSONPathFilter(expr string) (*jsonPathFilter, error) {
+	if !strings.HasPrefix(expr, "@") {
+		return nil, fmt.Errorf("invalid filter %v: must start with @", expr)
+	}
+	for _, operator := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		i := strings.Index(expr, operator)
+		if i < 0 {
+			continue
+		}
+		path, err := parseJSONPathExpression(strings.TrimSpace(expr[:i]))
+		if err != nil {
+			return nil, err
+		}
+		raw := strings.TrimSpace(expr[i+len(operator):])
+		var value interface{}
+		if strings.HasPrefix(raw, "'") || strings.HasPrefix(raw, "\"") {
+			value, err = unquoteJSONPathLiteral(raw)
+			if err != nil {
+				return nil, fmt.Errorf("invalid filter value %v: %v", raw, err)
+			}
+		} else if err := json.Unmarshal([]byte(raw), &value); err != nil {
+			return nil, fmt.Errorf("invalid filter value %v", raw)
+		}
+		return &jsonPathFilter{path: path, operator: operator, value: value}, nil
+	}
+	path, err := parseJSONPathExpression(expr)
+	if err != nil {
+		return nil, err
+	}
+	return &jsonPathFilter{path: path}, nil
+}
+
+// Execute writes the template applied to data, which must consist of JSON compatible values.
+func (j *jsonPath) Execute(w io.Writer, data interface{}) error {
+	var buf bytes.Buffer
+	if err := executeJSONPathNodes(&buf, j.nodes, data, data); err != nil {
+		return err
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func executeJSONPathNodes(w *bytes.Buffer, nodes []jsonPathNode, root interface{}, current interface{}) error {
+	for _, node := range nodes {
+		switch {
+		case node.isRng:
+			for _, value := range evalJSONPath(node.path, root, current) {
+				if err := executeJSONPathNodes(w, node.body, root, value); err != nil {
+					return err
+				}
+			}
+		case node.path != nil:
+			values := evalJSONPath(node.path, root, current)
+			for i, value := range values {
+				if i > 0 {
+					w.WriteString(" ")
+				}
+				text, err := formatJSONPathValue(value)
+				if err != nil {
+					return err
+				}
+				w.WriteString(text)
+			}
+		default:
+			w.WriteString(node.text)
+		}
+	}
+	return nil
+}
+
+func formatJSONPathValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case map[string]interface{}, []interface{}:
+		data, err := json.Marshal(v)
+		return string(data), err
+	default:
+		return fmt.Sprintf("%v", v), nil
+	}
+}
+
+// evalJSONPath returns the values selected by the path. Missing keys and out of range indexes select nothing.
+func evalJSONPath(steps []jsonPathStep, root interface{}, current interface{}) []interface{} {
+	var values = []interface{}{current}
+	for _, step := range steps {
+		var next = make([]interface{}, 0)
+		for _, value := range values {
+			next = append(next, applyJSONPathStep(step, root, value)...)
+		}
+		values = next
+	}
+	return values
+}
+
+func applyJSONPathStep(step jsonPathStep, root interface{}, value interface{}) []interface{} {
+	switch step.kind {
+	case stepField:
+		if m, ok := value.(map[string]interface{}); ok {
+			if v, ok := m[step.name]; ok {
+				return []interface{}{v}
+			}
+		}
+		return nil
+	case stepRecursive:
+		var result = make([]interface{}, 0)
+		collectJSONPathRecursive(step.name, value, &result)
+		return result
+	case stepWildcard:
+		switch v := value.(type) {
+		case []interface{}:
+			return v
+		case map[string]interface{}:
+			var keys = make([]string, 0, len(v))
+			for k := range v {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			var result = make([]interface{}, 0, len(keys))
+			for _, k := range keys {
+				result = append(result, v[k])
+			}
+			return result
+		}
+		return nil
+	case stepIndex:
+		list, ok := value.([]interface{})
+		if !ok {
+			return nil
+		}
+		i := step.index
+		if i < 0 {
+			i += len(list)
+		}
+		if i < 0 || i >= len(list) {
+			return nil
+		}
+		return []interface{}{list[i]}
+	case stepSlice:
+		list, ok := value.([]interface{})
+		if !ok {
+			return nil
+		}
+		start, end := 0, len(list)
+		if step.start != nil {
+			start = clampJSONPathIndex(*step.start, len(list))
+		}
+		if step.end != nil {
+			end = clampJSONPathIndex(*step.end, len(list))
+		}
+		if start >= end {
+			return nil
+		}
+		return list[start:end]
+	case stepFilter:
+		list, ok := value.([]interface{})
+		if !ok {
+			return nil
+		}
+		var result = make([]interface{}, 0)
+		for _, element := range list {
+			if matchJSONPathFilter(step.filter, root, element) {
+				result = append(result, element)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+func clampJSONPathIndex(i int, length int) int {
+	if i < 0 {
+		i += length
+	}
+	if i < 0 {
+		return 0
+	}
+	if i > length {
+		return length
+	}
+	return i
+}
+
+func collectJSONPathRecursive(name string, value interface{}, result *[]interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if found, ok := v[name]; ok {
+			*result = append(*result, found)
+		}
+		var keys = make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			collectJSONPathRecursive(name, v[k], result)
+		}
+	case []interface{}:
+		for _, element := range v {
+			collectJSONPathRecursive(name, element, result)
+		}
+	}
+}
+
+func matchJSONPathFilter(filter *jsonPathFilter, root interface{}, element interface{}) bool {
+	values := evalJSONPath(filter.path, root, element)
+	if filter.operator == "" {
+		return len(values) > 0
+	}
+	for _, value := range values {
+		if compareJSONPathValues(value, filter.operator, filter.value) {
+			return true
+		}
+	}
+	return false
+}
+
+func compareJSONPathValues(left interface{}, operator string, right interface{}) bool {
+	lf, lok := left.(float64)
+	rf, rok := right.(float64)
+	if lok && rok {
+		switch operator {
+		case "==":
+			return lf == rf
+		case "!=":
+			return lf != rf
+		case "<":
+			return lf < rf
+		case "<=":
+			return lf <= rf
+		case ">":
+			return lf > rf
+		case ">=":
+			return lf >= rf
+		}
+		return false
+	}
+	ls, lok := left.(string)
+	rs, rok := right.(string)
+	if lok && rok {
+		switch operator {
+		case "==":
+			return ls == rs
+		case "!=":
+			return ls != rs
+		case "<":
+			return ls < rs
+		case "<=":
+			return ls <= rs
+		case ">":
+			return ls > rs
+		case ">=":
+			return ls >= rs
+		}
+		return false
+	}
+	lb, lok := left.(bool)
+	rb, rok := right.(bool)
+	if lok && rok {
+		switch operator {
+		case "==":
+			return lb == rb
+		case "!=":
+			return lb != rb
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONPath(t *testing.T) {
+	var data interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{"items": [
+		{"name": "first", "id": "1", "count": 5, "metadata": {"hnsw:space": "l2"}},
+		{"name": "second", "id": "2", "count": 20, "metadata": {"hnsw:space": "cosine"}},
+		{"name": "third", "id": "3", "count": 0}
+	]}`), &data))
+
+	var tests = []struct {
+		template string
+		expected string
+	}{
+		{`{.items[*].id}`, "1 2 3"},
+		{`{.items[0].name}`, "first"},
+		{`{.items[-1].name}`, "third"},
+		{`{.items[1:].name}`, "second third"},
+		{`{.items[?(@.count>=5)].name}`, "first second"},
+		{`{.items[?(@.name=="second")].id}`, "2"},
+		{`{.items[?(@.metadata)].name}`, "first second"},
+		{`{.items[0].metadata['hnsw:space']}`, "l2"},
+		{`{..count}`, "5 20 0"},
+		{`{range .items[*]}{.name}{"\t"}{.count}{"\n"}{end}`, "first\t5\nsecond\t20\nthird\t0\n"},
+		{`ids: {$.items[*].id}`, "ids: 1 2 3"},
+		{`{.items[0].missing}`, ""},
+		{`{.items[0].metadata}`, `{"hnsw:space":"l2"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			path, err := parseJSONPath(tt.template)
+			require.NoError(t, err)
+			buf := new(bytes.Buffer)
+			require.NoError(t, path.Execute(buf, data))
+			require.Equal(t, tt.expected, buf.String())
+		})
+	}
+
+	t.Run("Invalid templates", func(t *testing.T) {
+		for _, template := range []string{`{.items[0}`, `{.items[*].name`, `{range .items[*]}{.name}`, `{end}`, `{.items[x]}`} {
+			_, err := parseJSONPath(template)
+			require.Error(t, err, template)
+		}
+	})
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	clitypes "github.com/amikos-tech/chroma-cli/chroma/types"
+	"github.com/spf13/viper"
+)
+
+// TestMain runs the tests with a config file in a temporary home directory, so tests that change servers or the
+// active context never touch the config of the user running them. The config has the local server used by setup
+// as active server.
+func TestMain(m *testing.M) {
+	home, err := os.MkdirTemp("", "chroma-cli-test-")
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	code := func() int {
+		defer os.RemoveAll(home)
+		if err := os.Setenv("HOME", home); err != nil {
+			fmt.Println(err)
+			return 1
+		}
+		file := filepath.Join(home, ".chroma", "config.yaml")
+		if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+			fmt.Println(err)
+			return 1
+		}
+		config := fmt.Sprintf(`version: %v
+active_server: local
+servers:
+  local:
+    host: localhost
+    port: 8000
+    tenant: %v
+    database: %v
+`, clitypes.ConfigVersion, DefaultTenant, DefaultDatabase)
+		if err := os.WriteFile(file, []byte(config), 0600); err != nil {
+			fmt.Println(err)
+			return 1
+		}
+		viper.SetConfigFile(file)
+		viper.SetConfigType("yaml")
+		if err := viper.ReadInConfig(); err != nil {
+			fmt.Println(err)
+			return 1
+		}
+		return m.Run()
+	}()
+	os.Exit(code)
+}
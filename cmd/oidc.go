@@ -0,0 +1,290 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/amikos-tech/chroma-cli/chroma/secrets"
+	clitypes "github.com/amikos-tech/chroma-cli/chroma/types"
+	"github.com/amikos-tech/chroma-cli/chroma/utils"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+const EnvChromaOIDCClientSecret = "CHROMA_OIDC_CLIENT_SECRET"
+
+const (
+	// oidcRefreshMargin is how long before their expiry cached access tokens are refreshed.
+	oidcRefreshMargin = time.Minute
+	// oidcTimeout bounds each request to the identity provider.
+	oidcTimeout = 30 * time.Second
+)
+
+// oidcSession is the cached result of an OIDC login. It is stored like any other credentials, see storeSecret.
+type oidcSession struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	Expiry       time.Time `json:"expiry,omitempty"`
+	// ClientSecret is kept for the client credentials flow, which gets a new token instead of refreshing it.
+	ClientSecret string `json:"client_secret,omitempty"`
+}
+
+// expiresSoon reports whether the access token has to be refreshed before it is used.
+func (s *oidcSession) expiresSoon() bool {
+	return s.AccessToken == "" || (!s.Expiry.IsZero() && time.Until(s.Expiry) < oidcRefreshMargin)
+}
+
+func (s *oidcSession) update(token *oauth2.Token) {
+	s.AccessToken, s.Expiry = token.AccessToken, token.Expiry
+	if token.RefreshToken != "" {
+		s.RefreshToken = token.RefreshToken
+	}
+}
+
+func parseOIDCSession(secret string) (*oidcSession, error) {
+	var session oidcSession
+	if secret == "" {
+		return &session, nil
+	}
+	if err := json.Unmarshal([]byte(secret), &session); err != nil {
+		return nil, fmt.Errorf("the cached OIDC tokens are corrupt: %v", err)
+	}
+	return &session, nil
+}
+
+func (s *oidcSession) String() string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
+
+// oidcProvider is the subset of the OpenID provider metadata the CLI uses.
+type oidcProvider struct {
+	Issuer                      string `json:"issuer"`
+	TokenEndpoint               string `json:"token_endpoint"`
+	DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+}
+
+// oidcContext returns a context whose requests to the identity provider time out after oidcTimeout.
+func oidcContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Timeout: oidcTimeout})
+}
+
+// discoverOIDC reads the endpoints of the issuer from its OpenID configuration.
+func discoverOIDC(ctx context.Context, issuer string) (*oidcProvider, error) {
+	issuer = strings.TrimSuffix(issuer, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := (&http.Client{Timeout: oidcTimeout}).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to discover the OIDC issuer %v: %v", issuer, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to discover the OIDC issuer %v: %v", issuer, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to discover the OIDC issuer %v: status %v", issuer, resp.StatusCode)
+	}
+	var provider oidcProvider
+	if err := json.Unmarshal(body, &provider); err != nil {
+		return nil, fmt.Errorf("invalid OpenID configuration of %v: %v", issuer, err)
+	}
+	if strings.TrimSuffix(provider.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("the OpenID configuration of %v is for another issuer: %v", issuer, provider.Issuer)
+	}
+	if provider.TokenEndpoint == "" {
+		return nil, fmt.Errorf("the OIDC issuer %v has no token endpoint", issuer)
+	}
+	return &provider, nil
+}
+
+func oidcAuthParams(config *clitypes.OIDCConfig) []oauth2.AuthCodeOption {
+	if config.Audience == "" {
+		return nil
+	}
+	return []oauth2.AuthCodeOption{oauth2.SetAuthURLParam("audience", config.Audience)}
+}
+
+// oidcDeviceLogin runs the device authorization flow. prompt is called with the verification URL and the code the
+// user has to enter.
+func oidcDeviceLogin(ctx context.Context, config *clitypes.OIDCConfig, prompt func(verificationURL string, userCode string)) (*oidcSession, error) {
+	ctx = oidcContext(ctx)
+	provider, err := discoverOIDC(ctx, config.Issuer)
+	if err != nil {
+		return nil, err
+	}
+	if provider.DeviceAuthorizationEndpoint == "" {
+		return nil, fmt.Errorf("the OIDC issuer %v does not support the device flow, use --flow %v", config.Issuer, clitypes.OIDCFlowClientCredentials)
+	}
+	conf := &oauth2.Config{
+		ClientID: config.ClientID,
+		Endpoint: oauth2.Endpoint{TokenURL: provider.TokenEndpoint, DeviceAuthURL: provider.DeviceAuthorizationEndpoint, AuthStyle: oauth2.AuthStyleInParams},
+		Scopes:   config.Scopes,
+	}
+	deviceAuth, err := conf.DeviceAuth(ctx, oidcAuthParams(config)...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to start the device login: %v", oidcError(err))
+	}
+	verificationURL := deviceAuth.VerificationURIComplete
+	if verificationURL == "" {
+		verificationURL = deviceAuth.VerificationURI
+	}
+	prompt(verificationURL, deviceAuth.UserCode)
+	token, err := conf.DeviceAccessToken(ctx, deviceAuth, oidcAuthParams(config)...)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, fmt.Errorf("the device login expired before it was approved")
+		}
+		return nil, fmt.Errorf("the device login failed: %v", oidcError(err))
+	}
+	var session oidcSession
+	session.update(token)
+	return &session, nil
+}
+
+// oidcClientCredentialsToken gets a token with the client credentials grant.
+func oidcClientCredentialsToken(ctx context.Context, config *clitypes.OIDCConfig, clientSecret string) (*oauth2.Token, error) {
+	ctx = oidcContext(ctx)
+	provider, err := discoverOIDC(ctx, config.Issuer)
+	if err != nil {
+		return nil, err
+	}
+	conf := &clientcredentials.Config{
+		ClientID:     config.ClientID,
+		ClientSecret: clientSecret,
+		TokenURL:     provider.TokenEndpoint,
+		Scopes:       config.Scopes,
+	}
+	if config.Audience != "" {
+		conf.EndpointParams = url.Values{"audience": {config.Audience}}
+	}
+	token, err := conf.Token(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("the client credentials login failed: %v", oidcError(err))
+	}
+	return token, nil
+}
+
+// oidcClientCredentialsLogin runs the client credentials flow.
+func oidcClientCredentialsLogin(ctx context.Context, config *clitypes.OIDCConfig, clientSecret string) (*oidcSession, error) {
+	token, err := oidcClientCredentialsToken(ctx, config, clientSecret)
+	if err != nil {
+		return nil, err
+	}
+	var session = oidcSession{ClientSecret: clientSecret}
+	session.update(token)
+	return &session, nil
+}
+
+// refreshOIDCSession gets a new access token with the refresh token or, for the client credentials flow, the client
+// secret of the session.
+func refreshOIDCSession(ctx context.Context, config *clitypes.OIDCConfig, session *oidcSession) error {
+	if config.Flow == clitypes.OIDCFlowClientCredentials {
+		token, err := oidcClientCredentialsToken(ctx, config, session.ClientSecret)
+		if err != nil {
+			return err
+		}
+		session.update(token)
+		return nil
+	}
+	if session.RefreshToken == "" {
+		return fmt.Errorf("the access token expired and the issuer did not return a refresh token")
+	}
+	ctx = oidcContext(ctx)
+	provider, err := discoverOIDC(ctx, config.Issuer)
+	if err != nil {
+		return err
+	}
+	conf := &oauth2.Config{
+		ClientID: config.ClientID,
+		Endpoint: oauth2.Endpoint{TokenURL: provider.TokenEndpoint, AuthStyle: oauth2.AuthStyleInParams},
+		Scopes:   config.Scopes,
+	}
+	token, err := conf.TokenSource(ctx, &oauth2.Token{RefreshToken: session.RefreshToken}).Token()
+	if err != nil {
+		return fmt.Errorf("unable to refresh the access token: %v", oidcError(err))
+	}
+	session.update(token)
+	return nil
+}
+
+// oidcError shortens the errors of the identity provider to their error code and description.
+func oidcError(err error) error {
+	var retrieveErr *oauth2.RetrieveError
+	if errors.As(err, &retrieveErr) && retrieveErr.ErrorCode != "" {
+		if retrieveErr.ErrorDescription != "" {
+			return fmt.Errorf("%v: %v", retrieveErr.ErrorCode, retrieveErr.ErrorDescription)
+		}
+		return errors.New(retrieveErr.ErrorCode)
+	}
+	return err
+}
+
+// oidcAccessToken returns the cached access token of a server, refreshing it first if it is about to expire. The
+// refreshed tokens are written back to where they are stored.
+func oidcAccessToken(alias string, auth *clitypes.AuthConfig) (string, error) {
+	if auth.OIDC == nil {
+		return "", fmt.Errorf("auth type oidc requires an oidc block with issuer and client_id")
+	}
+	secret, err := resolveSecret(auth)
+	if err != nil {
+		return "", err
+	}
+	session, err := parseOIDCSession(secret)
+	if err != nil {
+		return "", err
+	}
+	if session.AccessToken == "" {
+		return "", fmt.Errorf("not logged in to %v. run chroma auth login --oidc %v", alias, alias)
+	}
+	if !session.expiresSoon() {
+		return session.AccessToken, nil
+	}
+	if err := refreshOIDCSession(context.Background(), auth.OIDC, session); err != nil {
+		return "", fmt.Errorf("%v. run chroma auth login --oidc %v", err, alias)
+	}
+	if err := saveOIDCSession(alias, auth, session); err != nil {
+		return "", fmt.Errorf("unable to store the refreshed tokens of %v: %v", alias, err)
+	}
+	return session.AccessToken, nil
+}
+
+// saveOIDCSession replaces the cached tokens of a server. Tokens kept in the config file are only written if the
+// server is configured, a server that is not saved yet keeps them in memory.
+func saveOIDCSession(alias string, auth *clitypes.AuthConfig, session *oidcSession) error {
+	if auth.SecretRef != "" {
+		name, key, err := secrets.ParseRef(auth.SecretRef)
+		if err != nil {
+			return err
+		}
+		backend, err := getSecretBackend(name)
+		if err != nil {
+			return err
+		}
+		return backend.Set(key, session.String())
+	}
+	previous := auth.Token
+	auth.Token = session.String()
+	servers, err := utils.GetServers()
+	if err != nil {
+		return err
+	}
+	serverConfig, ok := servers[alias]
+	if !ok || serverConfig.Auth == nil || serverConfig.Auth.Token != previous {
+		return nil
+	}
+	serverConfig.Auth.Token = auth.Token
+	servers[alias] = serverConfig
+	return utils.SetServers(servers)
+}
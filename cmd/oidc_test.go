@@ -0,0 +1,266 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	clitypes "github.com/amikos-tech/chroma-cli/chroma/types"
+	"github.com/amikos-tech/chroma-cli/chroma/utils"
+	"github.com/stretchr/testify/require"
+)
+
+// oidcStandIn is a stand-in OIDC issuer with the device flow, the client credentials grant and refresh tokens, and a
+// Chroma server that accepts the access tokens it issues.
+type oidcStandIn struct {
+	Issuer string
+	Host   string
+	Port   int
+
+	mu            sync.Mutex
+	issued        int
+	accessTokens  map[string]bool
+	refreshTokens map[string]bool
+	pendingPolls  int
+}
+
+func helperOIDCStandIn(t *testing.T) *oidcStandIn {
+	s := &oidcStandIn{accessTokens: make(map[string]bool), refreshTokens: make(map[string]bool), pendingPolls: 1}
+	issuer := httptest.NewServer(http.HandlerFunc(s.serveIssuer))
+	t.Cleanup(issuer.Close)
+	s.Issuer = issuer.URL
+	chromaServer := httptest.NewServer(http.HandlerFunc(s.serveChroma))
+	t.Cleanup(chromaServer.Close)
+	u, err := url.Parse(chromaServer.URL)
+	require.NoError(t, err)
+	s.Host = u.Hostname()
+	s.Port, err = strconv.Atoi(u.Port())
+	require.NoError(t, err)
+	return s
+}
+
+func (s *oidcStandIn) writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func (s *oidcStandIn) issue(w http.ResponseWriter, withRefreshToken bool) {
+	s.issued++
+	var response = map[string]interface{}{
+		"access_token": fmt.Sprintf("access-%v", s.issued),
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+	}
+	s.accessTokens[response["access_token"].(string)] = true
+	if withRefreshToken {
+		response["refresh_token"] = fmt.Sprintf("refresh-%v", s.issued)
+		s.refreshTokens[response["refresh_token"].(string)] = true
+	}
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+func (s *oidcStandIn) serveIssuer(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch r.URL.Path {
+	case "/.well-known/openid-configuration":
+		s.writeJSON(w, http.StatusOK, map[string]string{
+			"issuer":                        s.Issuer,
+			"token_endpoint":                s.Issuer + "/token",
+			"device_authorization_endpoint": s.Issuer + "/device",
+		})
+	case "/device":
+		if r.FormValue("client_id") != "chroma-cli" {
+			s.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_client"})
+			return
+		}
+		s.writeJSON(w, http.StatusOK, map[string]interface{}{
+			"device_code":      "device-code",
+			"user_code":        "ABCD-EFGH",
+			"verification_uri": s.Issuer + "/activate",
+			"expires_in":       60,
+			"interval":         1,
+		})
+	case "/token":
+		switch r.FormValue("grant_type") {
+		case "urn:ietf:params:oauth:grant-type:device_code":
+			if r.FormValue("device_code") != "device-code" {
+				s.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+				return
+			}
+			if s.pendingPolls > 0 {
+				s.pendingPolls--
+				s.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "authorization_pending"})
+				return
+			}
+			s.issue(w, true)
+		case "client_credentials":
+			clientID, clientSecret, ok := r.BasicAuth()
+			if !ok {
+				clientID, clientSecret = r.FormValue("client_id"), r.FormValue("client_secret")
+			}
+			if clientID != "ci" || clientSecret != "ci-secret" {
+				s.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client", "error_description": "bad client secret"})
+				return
+			}
+			s.issue(w, false)
+		case "refresh_token":
+			if !s.refreshTokens[r.FormValue("refresh_token")] {
+				s.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+				return
+			}
+			delete(s.refreshTokens, r.FormValue("refresh_token"))
+			s.issue(w, true)
+		default:
+			s.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (s *oidcStandIn) serveChroma(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r.URL.Path != "/api/v2/auth/identity" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if !s.accessTokens[strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")] {
+		s.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+	s.writeJSON(w, http.StatusOK, map[string]interface{}{"user_id": "alice", "tenant": "acme", "databases": []string{"production"}})
+}
+
+func TestOIDCLogin(t *testing.T) {
+	command := RootCmd
+	defer resetCommandFlags(LoginCommand)
+	helperRestoreServers(t)
+	vault := helperSecretsVault(t)
+	standIn := helperOIDCStandIn(t)
+	buf := new(bytes.Buffer)
+	command.SetOut(buf)
+	command.SetErr(buf)
+
+	t.Run("Device flow", func(t *testing.T) {
+		alias := strings.ToLower(getRandomName("oidc"))
+		require.NoError(t, utils.SetServer(alias, clitypes.ServerConfig{Host: standIn.Host, Port: standIn.Port}))
+		resetCommandFlags(LoginCommand)
+		buf.Reset()
+		command.SetArgs([]string{"auth", "login", alias, "--oidc", "--issuer", standIn.Issuer, "--client-id", "chroma-cli", "--secret-backend", "file"})
+		_, err := command.ExecuteC()
+		require.NoError(t, err)
+		require.Contains(t, buf.String(), "open "+standIn.Issuer+"/activate and enter the code ABCD-EFGH")
+		require.Contains(t, buf.String(), "Logged in to '"+alias+"' as alice (tenant acme)")
+		serverConfig, err := utils.GetServer(alias)
+		require.NoError(t, err)
+		require.Equal(t, AuthTypeOIDC, serverConfig.Auth.Type)
+		require.Equal(t, "file:"+alias, serverConfig.Auth.SecretRef)
+		require.Equal(t, clitypes.OIDCConfig{Issuer: standIn.Issuer, ClientID: "chroma-cli", Flow: clitypes.OIDCFlowDevice, Scopes: []string{"openid", "offline_access"}}, *serverConfig.Auth.OIDC)
+		secret, err := vault.Get(alias)
+		require.NoError(t, err)
+		session, err := parseOIDCSession(secret)
+		require.NoError(t, err)
+		require.NotEmpty(t, session.RefreshToken)
+
+		client, err := getClient(alias, "", "")
+		require.NoError(t, err)
+		require.Equal(t, "Bearer "+session.AccessToken, client.ApiClient.GetConfig().DefaultHeader["Authorization"])
+
+		t.Run("Refresh before expiry", func(t *testing.T) {
+			session.Expiry = time.Now().Add(30 * time.Second)
+			require.NoError(t, vault.Set(alias, session.String()))
+			client, err := getClient(alias, "", "")
+			require.NoError(t, err)
+			secret, err := vault.Get(alias)
+			require.NoError(t, err)
+			refreshed, err := parseOIDCSession(secret)
+			require.NoError(t, err)
+			require.NotEqual(t, session.AccessToken, refreshed.AccessToken)
+			require.NotEqual(t, session.RefreshToken, refreshed.RefreshToken)
+			require.True(t, refreshed.Expiry.After(time.Now().Add(time.Hour-time.Minute)))
+			require.Equal(t, "Bearer "+refreshed.AccessToken, client.ApiClient.GetConfig().DefaultHeader["Authorization"])
+			_, err = verifyCredentials(context.Background(), client)
+			require.NoError(t, err)
+		})
+
+		t.Run("Rejected refresh token", func(t *testing.T) {
+			require.NoError(t, vault.Set(alias, (&oidcSession{AccessToken: "expired", RefreshToken: "revoked", Expiry: time.Now().Add(-time.Minute)}).String()))
+			_, err := getClient(alias, "", "")
+			require.ErrorContains(t, err, "invalid_grant")
+			require.ErrorContains(t, err, "run chroma auth login --oidc "+alias)
+		})
+	})
+
+	t.Run("Client credentials", func(t *testing.T) {
+		alias := strings.ToLower(getRandomName("oidc"))
+		require.NoError(t, utils.SetServer(alias, clitypes.ServerConfig{Host: standIn.Host, Port: standIn.Port}))
+		args := []string{"auth", "login", alias, "--oidc", "--flow", "client-credentials", "--issuer", standIn.Issuer, "--client-id", "ci", "--secret-backend", "plain"}
+
+		t.Setenv(EnvChromaOIDCClientSecret, "wrong-secret")
+		resetCommandFlags(LoginCommand)
+		buf.Reset()
+		require.NoError(t, LoginCommand.ParseFlags(args[3:]))
+		err := login(LoginCommand, []string{alias})
+		require.ErrorContains(t, err, "invalid_client: bad client secret")
+
+		t.Setenv(EnvChromaOIDCClientSecret, "ci-secret")
+		resetCommandFlags(LoginCommand)
+		buf.Reset()
+		command.SetArgs(args)
+		_, err = command.ExecuteC()
+		require.NoError(t, err)
+		serverConfig, err := utils.GetServer(alias)
+		require.NoError(t, err)
+		require.Equal(t, clitypes.OIDCFlowClientCredentials, serverConfig.Auth.OIDC.Flow)
+		require.Empty(t, serverConfig.Auth.OIDC.Scopes)
+		session, err := parseOIDCSession(serverConfig.Auth.Token)
+		require.NoError(t, err)
+		require.Equal(t, "ci-secret", session.ClientSecret)
+		require.Empty(t, session.RefreshToken)
+
+		// the client credentials flow gets a new token and the tokens in the config file are replaced
+		session.Expiry = time.Now().Add(-time.Second)
+		serverConfig.Auth.Token = session.String()
+		require.NoError(t, utils.SetServer(alias, serverConfig))
+		client, err := getClient(alias, "", "")
+		require.NoError(t, err)
+		serverConfig, err = utils.GetServer(alias)
+		require.NoError(t, err)
+		refreshed, err := parseOIDCSession(serverConfig.Auth.Token)
+		require.NoError(t, err)
+		require.NotEqual(t, session.AccessToken, refreshed.AccessToken)
+		require.Equal(t, "Bearer "+refreshed.AccessToken, client.ApiClient.GetConfig().DefaultHeader["Authorization"])
+	})
+
+	t.Run("Not logged in", func(t *testing.T) {
+		alias := strings.ToLower(getRandomName("oidc"))
+		require.NoError(t, utils.SetServer(alias, clitypes.ServerConfig{
+			Host: standIn.Host,
+			Port: standIn.Port,
+			Auth: &clitypes.AuthConfig{Type: AuthTypeOIDC, OIDC: &clitypes.OIDCConfig{Issuer: standIn.Issuer, ClientID: "chroma-cli", Flow: clitypes.OIDCFlowDevice}},
+		}))
+		_, err := getClient(alias, "", "")
+		require.ErrorContains(t, err, "not logged in to "+alias)
+	})
+
+	t.Run("Missing issuer", func(t *testing.T) {
+		alias := strings.ToLower(getRandomName("oidc"))
+		require.NoError(t, utils.SetServer(alias, clitypes.ServerConfig{Host: standIn.Host, Port: standIn.Port}))
+		resetCommandFlags(LoginCommand)
+		require.NoError(t, LoginCommand.ParseFlags([]string{"--oidc", "--client-id", "chroma-cli"}))
+		err := login(LoginCommand, []string{alias})
+		require.ErrorContains(t, err, "--issuer and --client-id are required")
+	})
+}
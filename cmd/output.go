@@ -0,0 +1,249 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+type OutputFormat string
+
+const (
+	OutputTable OutputFormat = "table"
+	OutputWide  OutputFormat = "wide"
+	OutputJSON  OutputFormat = "json"
+	OutputYAML  OutputFormat = "yaml"
+	OutputCSV   OutputFormat = "csv"
+	// OutputGoTemplate and OutputJSONPath take the template after an equals sign, e.g. -o jsonpath='{.items[*].id}'.
+	OutputGoTemplate     OutputFormat = "go-template"
+	OutputGoTemplateFile OutputFormat = "go-template-file"
+	OutputJSONPath       OutputFormat = "jsonpath"
+	OutputJSONPathFile   OutputFormat = "jsonpath-file"
+)
+
+const outputFormats = "table, wide, json, yaml, csv, go-template=..., go-template-file=..., jsonpath=..., jsonpath-file=..."
+
+// maxCellWidth is the maximum width of a table cell in the (non-wide) table output.
+const maxCellWidth = 60
+
+// tableOutput is the result of a command. Rows are rendered for the table, wide and csv formats, Items are marshalled
+// for the structured formats. Each row holds one cell per header followed by one cell per wide header.
+type tableOutput struct {
+	Headers     []string
+	WideHeaders []string
+	Rows        [][]string
+	Items       interface{}
+}
+
+func getOutputFormat(cmd *cobra.Command) (OutputFormat, error) {
+	format, _, err := getOutputTemplate(cmd)
+	return format, err
+}
+
+// getOutputTemplate returns the output format together with the template of the go-template and jsonpath formats.
+// The template files are read and returned as the go-template and jsonpath formats respectively.
+func getOutputTemplate(cmd *cobra.Command) (OutputFormat, string, error) {
+	value := string(OutputTable)
+	if f := cmd.Flag("output"); f != nil && f.Value.String() != "" {
+		value = f.Value.String()
+	}
+	name, template, hasTemplate := strings.Cut(value, "=")
+	switch format := OutputFormat(strings.ToLower(name)); format {
+	case OutputTable, OutputWide, OutputJSON, OutputYAML, OutputCSV:
+		if hasTemplate {
+			break
+		}
+		return format, "", nil
+	case OutputGoTemplate, OutputJSONPath:
+		if template == "" {
+			return "", "", fmt.Errorf("%v output requires a template, e.g. -o %v='...'", format, format)
+		}
+		return format, template, nil
+	case OutputGoTemplateFile, OutputJSONPathFile:
+		if template == "" {
+			return "", "", fmt.Errorf("%v output requires a file, e.g. -o %v=template.txt", format, format)
+		}
+		data, err := os.ReadFile(template)
+		if err != nil {
+			return "", "", err
+		}
+		if format == OutputGoTemplateFile {
+			return OutputGoTemplate, string(data), nil
+		}
+		return OutputJSONPath, string(data), nil
+	}
+	return "", "", fmt.Errorf("invalid output format: %v. must be one of %v", value, outputFormats)
+}
+
+func getNoHeaders(cmd *cobra.Command) bool {
+	if f := cmd.Flag("no-headers"); f != nil {
+		return f.Value.String() == "true"
+	}
+	return false
+}
+
+// isStructuredOutput returns true if the command output is meant to be consumed by other programs.
+func isStructuredOutput(cmd *cobra.Command) bool {
+	format, err := getOutputFormat(cmd)
+	return err == nil && format != OutputTable && format != OutputWide
+}
+
+func printStructured(cmd *cobra.Command, format OutputFormat, items interface{}) error {
+	switch format {
+	case OutputGoTemplate, OutputJSONPath:
+		_, tmpl, err := getOutputTemplate(cmd)
+		if err != nil {
+			return err
+		}
+		return printTemplate(cmd, format, tmpl, items)
+	case OutputJSON:
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(items)
+	case OutputYAML:
+		encoder := yaml.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent(2)
+		if err := encoder.Encode(items); err != nil {
+			return err
+		}
+		return encoder.Close()
+	default:
+		return fmt.Errorf("unsupported structured output format: %v", format)
+	}
+}
+
+// printOutput renders the command output in the format selected with --output.
+func printOutput(cmd *cobra.Command, out *tableOutput) error {
+	format, err := getOutputFormat(cmd)
+	if err != nil {
+		return err
+	}
+	noHeaders := getNoHeaders(cmd)
+	switch format {
+	case OutputJSON, OutputYAML, OutputGoTemplate, OutputJSONPath:
+		return printStructured(cmd, format, out.Items)
+	case OutputCSV:
+		w := csv.NewWriter(cmd.OutOrStdout())
+		if !noHeaders {
+			if err := w.Write(append(append([]string{}, out.Headers...), out.WideHeaders...)); err != nil {
+				return err
+			}
+		}
+		for _, row := range out.Rows {
+			if err := w.Write(row); err != nil {
+				return err
+			}
+		}
+		w.Flush()
+		return w.Error()
+	default:
+		columns := len(out.Headers)
+		headers := out.Headers
+		if format == OutputWide {
+			columns += len(out.WideHeaders)
+			headers = append(append([]string{}, out.Headers...), out.WideHeaders...)
+		}
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 3, ' ', 0)
+		if !noHeaders {
+			fmt.Fprintln(w, strings.Join(headers, "\t"))
+		}
+		for _, row := range out.Rows {
+			var cells = make([]string, columns)
+			for i := range cells {
+				if i < len(row) {
+					cells[i] = formatCell(row[i], format == OutputWide)
+				}
+			}
+			fmt.Fprintln(w, strings.Join(cells, "\t"))
+		}
+		return w.Flush()
+	}
+}
+
+// printMessage prints a human-readable message for the table formats and the given item for the other formats.
+func printMessage(cmd *cobra.Command, message string, item interface{}) error {
+	format, err := getOutputFormat(cmd)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case OutputJSON, OutputYAML, OutputGoTemplate, OutputJSONPath:
+		return printStructured(cmd, format, item)
+	default:
+		_, err := fmt.Fprintln(cmd.OutOrStdout(), message)
+		return err
+	}
+}
+
+// printTemplate executes a go-template against the typed items, so fields are referenced by their Go names
+// ({{range .}}{{.Name}}{{end}}), and a jsonpath template against the JSON representation of the items, with lists
+// wrapped in an items field ({.items[*].name}).
+func printTemplate(cmd *cobra.Command, format OutputFormat, tmpl string, items interface{}) error {
+	if format == OutputGoTemplate {
+		t, err := template.New("output").Option("missingkey=zero").Parse(tmpl)
+		if err != nil {
+			return fmt.Errorf("invalid go-template: %v", err)
+		}
+		return t.Execute(cmd.OutOrStdout(), items)
+	}
+	path, err := parseJSONPath(tmpl)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	if list, ok := value.([]interface{}); ok {
+		value = map[string]interface{}{"items": list}
+	}
+	return path.Execute(cmd.OutOrStdout(), value)
+}
+
+// formatCell makes a value fit into a single table cell.
+func formatCell(value string, wide bool) string {
+	value = strings.NewReplacer("\n", " ", "\r", " ", "\t", " ").Replace(value)
+	if !wide && len([]rune(value)) > maxCellWidth {
+		return string([]rune(value)[:maxCellWidth-3]) + "..."
+	}
+	return value
+}
+
+// formatMetadata renders metadata as sorted key=value pairs.
+func formatMetadata(metadata map[string]interface{}) string {
+	var keys = make([]string, 0, len(metadata))
+	for k := range metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var pairs = make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, fmt.Sprintf("%v=%v", k, metadata[k]))
+	}
+	return strings.Join(pairs, ",")
+}
+
+// formatOptional renders a value or an empty string if the value is nil.
+func formatOptional(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+func init() {
+	RootCmd.PersistentFlags().StringP("output", "o", string(OutputTable), "Output format. One of: "+outputFormats)
+	RootCmd.PersistentFlags().Bool("no-headers", false, "Do not print headers in table and csv output")
+}
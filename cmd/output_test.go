@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func helperOutputCommand(t *testing.T, args ...string) (*cobra.Command, *bytes.Buffer) {
+	command := &cobra.Command{Use: "test"}
+	command.Flags().StringP("output", "o", string(OutputTable), "")
+	command.Flags().Bool("no-headers", false, "")
+	require.NoError(t, command.ParseFlags(args))
+	buf := new(bytes.Buffer)
+	command.SetOut(buf)
+	return command, buf
+}
+
+func TestPrintOutput(t *testing.T) {
+	type item struct {
+		Name  string `json:"name" yaml:"name"`
+		Count int    `json:"count" yaml:"count"`
+	}
+	out := &tableOutput{
+		Headers:     []string{"NAME", "COUNT"},
+		WideHeaders: []string{"EXTRA"},
+		Rows:        [][]string{{"first", "1", "x"}, {"second", "2", "y"}},
+		Items:       []item{{Name: "first", Count: 1}, {Name: "second", Count: 2}},
+	}
+
+	t.Run("Table", func(t *testing.T) {
+		command, buf := helperOutputCommand(t)
+		require.NoError(t, printOutput(command, out))
+		require.Equal(t, "NAME     COUNT\nfirst    1\nsecond   2\n", buf.String())
+	})
+
+	t.Run("Wide without headers", func(t *testing.T) {
+		command, buf := helperOutputCommand(t, "-o", "wide", "--no-headers")
+		require.NoError(t, printOutput(command, out))
+		require.Equal(t, "first    1   x\nsecond   2   y\n", buf.String())
+	})
+
+	t.Run("CSV", func(t *testing.T) {
+		command, buf := helperOutputCommand(t, "-o", "csv")
+		require.NoError(t, printOutput(command, out))
+		require.Equal(t, "NAME,COUNT,EXTRA\nfirst,1,x\nsecond,2,y\n", buf.String())
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		command, buf := helperOutputCommand(t, "-o", "json")
+		require.NoError(t, printOutput(command, out))
+		require.JSONEq(t, `[{"name":"first","count":1},{"name":"second","count":2}]`, buf.String())
+	})
+
+	t.Run("YAML", func(t *testing.T) {
+		command, buf := helperOutputCommand(t, "-o", "yaml")
+		require.NoError(t, printOutput(command, out))
+		require.Equal(t, "- name: first\n  count: 1\n- name: second\n  count: 2\n", buf.String())
+	})
+
+	t.Run("Go template", func(t *testing.T) {
+		command, buf := helperOutputCommand(t, "-o", `go-template={{range .}}{{.Name}}={{.Count}}{{"\n"}}{{end}}`)
+		require.NoError(t, printOutput(command, out))
+		require.Equal(t, "first=1\nsecond=2\n", buf.String())
+	})
+
+	t.Run("JSONPath", func(t *testing.T) {
+		command, buf := helperOutputCommand(t, "-o", "jsonpath={.items[*].name}")
+		require.NoError(t, printOutput(command, out))
+		require.Equal(t, "first second", buf.String())
+	})
+
+	t.Run("JSONPath single item", func(t *testing.T) {
+		command, buf := helperOutputCommand(t, "-o", "jsonpath={.name}")
+		require.NoError(t, printMessage(command, "Collection created: test", collectionItem{Name: "test"}))
+		require.Equal(t, "test", buf.String())
+	})
+
+	t.Run("Missing template", func(t *testing.T) {
+		command, _ := helperOutputCommand(t, "-o", "jsonpath")
+		require.Error(t, printOutput(command, out))
+	})
+
+	t.Run("Invalid format", func(t *testing.T) {
+		command, _ := helperOutputCommand(t, "-o", "xml")
+		require.Error(t, printOutput(command, out))
+	})
+}
+
+func TestPrintMessage(t *testing.T) {
+	command, buf := helperOutputCommand(t)
+	require.NoError(t, printMessage(command, "Collection created: test", collectionItem{Name: "test"}))
+	require.Equal(t, "Collection created: test\n", buf.String())
+
+	command, buf = helperOutputCommand(t, "-o", "json")
+	require.NoError(t, printMessage(command, "Collection created: test", collectionItem{Name: "test"}))
+	require.Contains(t, buf.String(), `"name": "test"`)
+}
+
+func TestFormatCell(t *testing.T) {
+	long := "a very long document that does not fit into a single table cell without being truncated"
+	require.Len(t, []rune(formatCell(long, false)), maxCellWidth)
+	require.Equal(t, long, formatCell(long, true))
+	require.Equal(t, "line one line two", formatCell("line one\nline two", false))
+}
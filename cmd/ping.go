@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// serverStatus is the result of a health check of a server.
+type serverStatus struct {
+	Alias     string  `json:"alias" yaml:"alias"`
+	URL       string  `json:"url,omitempty" yaml:"url,omitempty"`
+	Up        bool    `json:"up" yaml:"up"`
+	Version   string  `json:"version,omitempty" yaml:"version,omitempty"`
+	LatencyMS float64 `json:"latency_ms,omitempty" yaml:"latency_ms,omitempty"`
+	Error     string  `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+func (s serverStatus) state() string {
+	if s.Up {
+		return "up"
+	}
+	return "down"
+}
+
+func (s serverStatus) latency() string {
+	if !s.Up {
+		return ""
+	}
+	return fmt.Sprintf("%.1fms", s.LatencyMS)
+}
+
+// pingServer calls the heartbeat and version endpoints of a server. The latency is the round trip time of the
+// heartbeat. Errors are reported in the status.
+func pingServer(ctx context.Context, alias string, timeout time.Duration) serverStatus {
+	var status = serverStatus{Alias: alias}
+	client, err := getClient(alias, "", "")
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	if servers := client.ApiClient.GetConfig().Servers; len(servers) > 0 {
+		status.URL = servers[0].URL
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	start := time.Now()
+	if _, err := client.Heartbeat(ctx); err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	status.LatencyMS = float64(time.Since(start).Microseconds()) / 1000
+	status.Up = true
+	if status.Version, err = client.Version(ctx); err != nil {
+		status.Error = err.Error()
+	}
+	return status
+}
+
+// pingServers checks the servers concurrently. The statuses are returned in the order of the aliases.
+func pingServers(ctx context.Context, aliases []string, timeout time.Duration) []serverStatus {
+	var statuses = make([]serverStatus, len(aliases))
+	var wg sync.WaitGroup
+	for i, alias := range aliases {
+		wg.Add(1)
+		go func(i int, alias string) {
+			defer wg.Done()
+			statuses[i] = pingServer(ctx, alias, timeout)
+		}(i, alias)
+	}
+	wg.Wait()
+	return statuses
+}
+
+func pingCommand(cmd *cobra.Command, args []string) error {
+	timeout, err := cmd.Flags().GetDuration("timeout")
+	if err != nil {
+		return err
+	}
+	var aliases = args
+	if len(aliases) == 0 {
+		active := viper.GetString("active_server")
+		if active == "" {
+			err := fmt.Errorf("no active server. name the servers to ping")
+			cmd.Printf("%v\n", err)
+			return err
+		}
+		aliases = []string{active}
+	}
+	statuses := pingServers(context.TODO(), aliases, timeout)
+	var rows = make([][]string, 0, len(statuses))
+	var down int
+	for _, status := range statuses {
+		if !status.Up {
+			down++
+		}
+		rows = append(rows, []string{status.Alias, status.state(), status.Version, status.latency(), status.URL, status.Error})
+	}
+	err = printOutput(cmd, &tableOutput{
+		Headers:     []string{"ALIAS", "STATUS", "VERSION", "LATENCY"},
+		WideHeaders: []string{"URL", "ERROR"},
+		Rows:        rows,
+		Items:       statuses,
+	})
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	if down > 0 {
+		return fmt.Errorf("%v of %v servers are down", down, len(statuses))
+	}
+	return nil
+}
+
+var PingCommand = &cobra.Command{
+	Use:   "ping [alias...]",
+	Short: "Check that servers respond",
+	Long: `Call the heartbeat and version endpoints of the given servers, or the active server, and report the round trip
+time. The servers are checked concurrently. Exits with a non-zero status if a server is down.`,
+	Example: `  chroma server ping
+  chroma server ping local prod --timeout 2s`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := pingCommand(cmd, args)
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	PingCommand.Flags().Duration("timeout", 5*time.Second, "Time to wait for each server to respond")
+	serverCmd.AddCommand(PingCommand)
+}
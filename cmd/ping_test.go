@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPingServers(t *testing.T) {
+	active := viper.GetString("active_server")
+	statuses := pingServers(context.TODO(), []string{active, "does-not-exist"}, time.Second)
+	require.Len(t, statuses, 2)
+	require.True(t, statuses[0].Up, statuses[0].Error)
+	require.NotEmpty(t, statuses[0].Version)
+	require.Equal(t, active, statuses[0].Alias)
+	require.False(t, statuses[1].Up)
+	require.Contains(t, statuses[1].Error, "does not exist")
+}
+
+func TestPingCommand(t *testing.T) {
+	command := RootCmd
+	defer resetCommandFlags(RootCmd)
+	defer resetCommandFlags(PingCommand)
+	defer resetCommandFlags(ListCommand)
+	buf := new(bytes.Buffer)
+	command.SetOut(buf)
+	command.SetErr(buf)
+
+	t.Run("Ping the active server", func(t *testing.T) {
+		buf.Reset()
+		command.SetArgs([]string{"server", "ping"})
+		_, err := command.ExecuteC()
+		require.NoError(t, err)
+		require.Contains(t, buf.String(), "up")
+	})
+
+	t.Run("Ping an unknown server", func(t *testing.T) {
+		resetCommandFlags(PingCommand)
+		require.NoError(t, PingCommand.ParseFlags([]string{}))
+		err := pingCommand(PingCommand, []string{"does-not-exist"})
+		require.ErrorContains(t, err, "1 of 1 servers are down")
+	})
+
+	t.Run("List servers with status", func(t *testing.T) {
+		buf.Reset()
+		command.SetArgs([]string{"server", "ls", "--status", "-o", "json"})
+		_, err := command.ExecuteC()
+		require.NoError(t, err)
+		var items []serverItem
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &items))
+		require.NotEmpty(t, items)
+		for _, item := range items {
+			require.NotNil(t, item.Status)
+			if item.Active {
+				require.True(t, item.Status.Up)
+			}
+		}
+	})
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+)
+
+const progressBarWidth = 30
+
+// progressBar renders a single line progress bar with the throughput and the estimated time left. It only renders
+// when it writes to a terminal so redirected output and logs are not flooded with updates.
+type progressBar struct {
+	mu       sync.Mutex
+	w        io.Writer
+	label    string
+	total    int
+	done     int
+	start    time.Time
+	rendered time.Time
+	enabled  bool
+	now      func() time.Time
+}
+
+func newProgressBar(w io.Writer, label string, total int) *progressBar {
+	enabled := false
+	if f, ok := w.(*os.File); ok {
+		enabled = term.IsTerminal(int(f.Fd()))
+	}
+	return &progressBar{w: w, label: label, total: total, start: time.Now(), enabled: enabled, now: time.Now}
+}
+
+// Add increases the number of processed items and redraws the bar at most ten times a second.
+func (p *progressBar) Add(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done += n
+	if !p.enabled {
+		return
+	}
+	if now := p.now(); now.Sub(p.rendered) >= 100*time.Millisecond || p.done >= p.total {
+		p.rendered = now
+		_, _ = fmt.Fprintf(p.w, "\r%v\x1b[K", p.render())
+	}
+}
+
+// Finish ends the progress line.
+func (p *progressBar) Finish() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.enabled {
+		_, _ = fmt.Fprintf(p.w, "\r%v\x1b[K\n", p.render())
+	}
+}
+
+func (p *progressBar) render() string {
+	var ratio float64
+	if p.total > 0 {
+		ratio = float64(p.done) / float64(p.total)
+	}
+	if ratio > 1 {
+		ratio = 1
+	}
+	filled := int(ratio * progressBarWidth)
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", progressBarWidth-filled)
+	elapsed := p.now().Sub(p.start)
+	var rate float64
+	if elapsed > 0 {
+		rate = float64(p.done) / elapsed.Seconds()
+	}
+	eta := "--"
+	if rate > 0 && p.total >= p.done {
+		eta = time.Duration(float64(p.total-p.done) / rate * float64(time.Second)).Round(time.Second).String()
+	}
+	return fmt.Sprintf("%v [%v] %v/%v %3.0f%% %.0f records/s ETA %v", p.label, bar, p.done, p.total, ratio*100, rate, eta)
+}
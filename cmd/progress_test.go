@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgressBar(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start.Add(10 * time.Second)
+	buf := new(bytes.Buffer)
+	progress := &progressBar{w: buf, label: "copying", total: 400, start: start, enabled: true, now: func() time.Time { return now }}
+	progress.Add(100)
+	require.Equal(t, "\rcopying [=======                       ] 100/400  25% 10 records/s ETA 30s\x1b[K", buf.String())
+
+	disabled := newProgressBar(new(bytes.Buffer), "copying", 10)
+	disabled.Add(10)
+	disabled.Finish()
+	require.Equal(t, 0, disabled.w.(*bytes.Buffer).Len())
+}
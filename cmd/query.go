@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	chroma "github.com/amikos-tech/chroma-go"
+	openapi "github.com/amikos-tech/chroma-go/swagger"
+	"github.com/amikos-tech/chroma-go/types"
+)
+
+// readEmbeddingsFile reads query embeddings from a JSON file containing either a single embedding or a list of
+// embeddings.
+func readEmbeddingsFile(path string) ([]*types.Embedding, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var embeddings [][]float32
+	if err := json.Unmarshal(data, &embeddings); err != nil {
+		var embedding []float32
+		if err := json.Unmarshal(data, &embedding); err != nil {
+			return nil, fmt.Errorf("invalid embeddings file %v. expected a JSON list of numbers or a list of lists", path)
+		}
+		embeddings = [][]float32{embedding}
+	}
+	return types.NewEmbeddingsFromFloat32(embeddings), nil
+}
+
+// queryCollection runs a nearest neighbour query. Unlike Collection.QueryWithOptions it does not require an embedding
+// function when only embeddings are provided.
+func queryCollection(ctx context.Context, col *chroma.Collection, queryTexts []string, queryEmbeddings []*types.Embedding, nResults int32, where map[string]interface{}, whereDocument map[string]interface{}, include []types.QueryEnum) (*openapi.QueryResult, error) {
+	var embeddings = make([]*types.Embedding, 0, len(queryTexts)+len(queryEmbeddings))
+	if len(queryTexts) > 0 {
+		if col.EmbeddingFunction == nil {
+			return nil, fmt.Errorf("an embedding function (--embedding-function) is required to query with texts")
+		}
+		textEmbeddings, err := col.EmbeddingFunction.EmbedDocuments(ctx, queryTexts)
+		if err != nil {
+			return nil, err
+		}
+		embeddings = append(embeddings, textEmbeddings...)
+	}
+	embeddings = append(embeddings, queryEmbeddings...)
+	if len(embeddings) == 0 {
+		return nil, fmt.Errorf("at least one query text or embedding is required")
+	}
+	if len(include) == 0 {
+		include = []types.QueryEnum{types.IDocuments, types.IMetadatas, types.IDistances}
+	}
+	var apiInclude = make([]openapi.IncludeInner, len(include))
+	for i, v := range include {
+		value := string(v)
+		apiInclude[i] = openapi.IncludeInner{String: &value}
+	}
+	result, _, err := col.ApiClient.DefaultApi.GetNearestNeighbors(ctx, col.ID).QueryEmbedding(openapi.QueryEmbedding{
+		Where:           where,
+		WhereDocument:   whereDocument,
+		NResults:        &nResults,
+		Include:         apiInclude,
+		QueryEmbeddings: types.ToAPIEmbeddings(embeddings),
+	}).Execute()
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// queryResultItem is the structured representation of the results of a single query.
+type queryResultItem struct {
+	Query   string           `json:"query" yaml:"query"`
+	Results []queryMatchItem `json:"results" yaml:"results"`
+}
+
+// queryMatchItem is a single nearest neighbour of a query.
+type queryMatchItem struct {
+	recordItem `yaml:",inline"`
+	Distance   *float32 `json:"distance,omitempty" yaml:"distance,omitempty"`
+}
+
+func printQueryResult(cmd *cobra.Command, labels []string, result *openapi.QueryResult) error {
+	var items = make([]queryResultItem, 0, len(result.Ids))
+	var rows = make([][]string, 0)
+	for q, ids := range result.Ids {
+		label := fmt.Sprintf("#%v", q+1)
+		if q < len(labels) {
+			label = labels[q]
+		}
+		item := queryResultItem{Query: label, Results: make([]queryMatchItem, 0, len(ids))}
+		for i, id := range ids {
+			match := queryMatchItem{recordItem: recordItem{ID: id}}
+			var distance, document, embedding string
+			if q < len(result.Distances) && i < len(result.Distances[q]) {
+				match.Distance = &result.Distances[q][i]
+				distance = fmt.Sprintf("%v", result.Distances[q][i])
+			}
+			if q < len(result.Documents) && i < len(result.Documents[q]) {
+				match.Document = &result.Documents[q][i]
+				document = result.Documents[q][i]
+			}
+			if q < len(result.Metadatas) && i < len(result.Metadatas[q]) {
+				match.Metadata = result.Metadatas[q][i]
+			}
+			if q < len(result.Embeddings) && i < len(result.Embeddings[q]) {
+				match.Embedding = embeddingValues(types.NewEmbeddingFromAPI(result.Embeddings[q][i]))
+				embedding = fmt.Sprintf("%v", match.Embedding)
+			}
+			item.Results = append(item.Results, match)
+			rows = append(rows, []string{label, strconv.Itoa(i + 1), id, distance, document, formatMetadata(match.Metadata), embedding})
+		}
+		items = append(items, item)
+	}
+	return printOutput(cmd, &tableOutput{
+		Headers:     []string{"QUERY", "RANK", "ID", "DISTANCE", "DOCUMENT", "METADATA"},
+		WideHeaders: []string{"EMBEDDING"},
+		Rows:        rows,
+		Items:       items,
+	})
+}
+
+func queryDocs(cmd *cobra.Command, args []string) error {
+	collectionName := args[0]
+	queryTexts := args[1:]
+	var queryEmbeddings = make([]*types.Embedding, 0)
+	embeddingFlagValues, err := cmd.Flags().GetStringArray("embedding")
+	if err != nil {
+		return err
+	}
+	for _, value := range embeddingFlagValues {
+		embedding, err := parseEmbedding(value)
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			return err
+		}
+		queryEmbeddings = append(queryEmbeddings, embedding)
+	}
+	if embeddingsFile, err := cmd.Flags().GetString("embedding-file"); err != nil {
+		return err
+	} else if embeddingsFile != "" {
+		fileEmbeddings, err := readEmbeddingsFile(embeddingsFile)
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			return err
+		}
+		queryEmbeddings = append(queryEmbeddings, fileEmbeddings...)
+	}
+	nResults, err := cmd.Flags().GetInt("n-results")
+	if err != nil {
+		return err
+	}
+	whereVal, err := cmd.Flags().GetString("where")
+	if err != nil {
+		return err
+	}
+	where, err := parseWhere(whereVal)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	whereDocumentVal, err := cmd.Flags().GetString("where-document")
+	if err != nil {
+		return err
+	}
+	whereDocument, err := parseWhereDocument(whereDocumentVal)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	includeVal, err := cmd.Flags().GetStringSlice("include")
+	if err != nil {
+		return err
+	}
+	include, err := parseInclude(includeVal, queryIncludes)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	col, err := getCollectionForCommand(cmd, collectionName)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	result, err := queryCollection(context.TODO(), col, queryTexts, queryEmbeddings, int32(nResults), where, whereDocument, include)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	if err := printQueryResult(cmd, queryTexts, result); err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	return nil
+}
+
+var QueryCommand = &cobra.Command{
+	Use:   "query",
+	Short: "Query a collection for the nearest neighbours of texts or embeddings",
+	Args:  cobra.MinimumNArgs(1),
+	Example: `  chroma query my-collection "what is chroma?" -e openai -k 5
+  chroma query my-collection "hello" -e hash --where 'age>=30 AND tag in [a,b]' --where-document 'contains world'
+  chroma query my-collection --embedding-file query.json --include documents,distances`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := queryDocs(cmd, args)
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	QueryCommand.Flags().StringP("alias", "s", "", "Server alias name. If not provided, the active server will be used.")
+	QueryCommand.Flags().StringP("tenant", "t", "", "Tenant name. If not provided, the active tenant or the server default will be used.")
+	QueryCommand.Flags().StringP("database", "d", "", "Database name. If not provided, the active database or the server default will be used.")
+	QueryCommand.Flags().StringP("embedding-function", "e", "", "The name of the embedding function used to embed the query texts")
+	QueryCommand.Flags().StringArray("embedding", []string{}, "Query embedding as comma separated floats. Can be repeated.")
+	QueryCommand.Flags().String("embedding-file", "", "JSON file with a query embedding or a list of query embeddings")
+	QueryCommand.Flags().IntP("n-results", "k", 10, "Number of results to return per query")
+	QueryCommand.Flags().StringSlice("include", []string{string(types.IDocuments), string(types.IMetadatas), string(types.IDistances)}, "Fields to include: documents, metadatas, embeddings, distances")
+	QueryCommand.Flags().StringP("where", "w", "", "Metadata filter as JSON or in the compact syntax, e.g. 'age>=30 AND tag in [a,b]'")
+	QueryCommand.Flags().StringP("where-document", "W", "", "Document filter as JSON or in the compact syntax, e.g. 'contains hello'")
+	RootCmd.AddCommand(QueryCommand)
+}
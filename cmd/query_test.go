@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryCommand(t *testing.T) {
+	command := RootCmd
+
+	t.Run("Query with text and filter", func(t *testing.T) {
+		resetCommandFlags(QueryCommand)
+		client := setup()
+		defer tearDown(client)
+		var collectionName = getRandomName("query-collection")
+		helperCreateCollection(t, client, collectionName)
+		addDummyRecordsToCollection(t, client, collectionName, 10)
+		buf := new(bytes.Buffer)
+		command.SetOut(buf)
+		command.SetErr(buf)
+		defer resetCommandFlags(RootCmd)
+		command.SetArgs([]string{"query", collectionName, "record-1", "-e", "hash", "-k", "1", "-o", "json"})
+		_, err := command.ExecuteC()
+		require.NoError(t, err)
+		var results []queryResultItem
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &results))
+		require.Len(t, results, 1)
+		require.Equal(t, "record-1", results[0].Query)
+		require.Len(t, results[0].Results, 1)
+		require.Equal(t, "id-1", results[0].Results[0].ID)
+		require.NotNil(t, results[0].Results[0].Distance)
+		require.Equal(t, float32(0), *results[0].Results[0].Distance)
+	})
+
+	t.Run("Query with where document filter", func(t *testing.T) {
+		resetCommandFlags(QueryCommand)
+		client := setup()
+		defer tearDown(client)
+		var collectionName = getRandomName("query-collection")
+		helperCreateCollection(t, client, collectionName)
+		addDummyRecordsToCollection(t, client, collectionName, 10)
+		buf := new(bytes.Buffer)
+		command.SetOut(buf)
+		command.SetErr(buf)
+		command.SetArgs([]string{"query", collectionName, "record-1", "-e", "hash", "-W", "contains record-2"})
+		_, err := command.ExecuteC()
+		require.NoError(t, err)
+		output := buf.String()
+		require.Contains(t, output, "QUERY")
+		require.Contains(t, output, "id-2")
+		require.NotContains(t, output, "id-1 ")
+	})
+}
@@ -0,0 +1,659 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	chroma "github.com/amikos-tech/chroma-go"
+	"github.com/amikos-tech/chroma-go/types"
+	"github.com/google/uuid"
+	"github.com/oklog/ulid/v2"
+	"github.com/parquet-go/parquet-go"
+)
+
+type RecordFileFormat string
+
+const (
+	RecordFormatJSONL   RecordFileFormat = "jsonl"
+	RecordFormatCSV     RecordFileFormat = "csv"
+	RecordFormatParquet RecordFileFormat = "parquet"
+)
+
+// The reserved columns of the record files. Metadata keys colliding with them are prefixed with metadataColumnPrefix in
+// the CSV files.
+const (
+	idColumn             = "id"
+	documentColumn       = "document"
+	metadataColumn       = "metadata"
+	embeddingColumn      = "embedding"
+	metadataColumnPrefix = "metadata."
+)
+
+// getRecordFileFormat returns the explicitly requested format or the format matching the file extension. JSONL is used
+// when neither is known.
+func getRecordFileFormat(format string, path string) (RecordFileFormat, error) {
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".csv":
+			return RecordFormatCSV, nil
+		case ".parquet", ".pq":
+			return RecordFormatParquet, nil
+		default:
+			return RecordFormatJSONL, nil
+		}
+	}
+	switch f := RecordFileFormat(strings.ToLower(format)); f {
+	case RecordFormatJSONL, RecordFormatCSV, RecordFormatParquet:
+		return f, nil
+	case "ndjson":
+		return RecordFormatJSONL, nil
+	default:
+		return "", fmt.Errorf("invalid format: %v. must be one of jsonl, csv, parquet", format)
+	}
+}
+
+// forEachRecordBatch pages through the records of a collection matching the filters and calls fn with each batch.
+func forEachRecordBatch(ctx context.Context, col *chroma.Collection, batchSize int32, where map[string]interface{}, whereDocument map[string]interface{}, include []types.QueryEnum, fn func(records []recordItem) error) error {
+	if batchSize <= 0 {
+		return fmt.Errorf("batch size must be greater than 0")
+	}
+	for offset := int32(0); ; offset += batchSize {
+		var options = []types.CollectionQueryOption{types.WithLimit(batchSize), types.WithOffset(offset), types.WithInclude(include...)}
+		if where != nil {
+			options = append(options, types.WithWhereMap(where))
+		}
+		if whereDocument != nil {
+			options = append(options, types.WithWhereDocumentMap(whereDocument))
+		}
+		result, err := col.GetWithOptions(ctx, options...)
+		if err != nil {
+			return err
+		}
+		if len(result.Ids) == 0 {
+			return nil
+		}
+		if err := fn(recordItemsFromResult(result)); err != nil {
+			return err
+		}
+		if int32(len(result.Ids)) < batchSize {
+			return nil
+		}
+	}
+}
+
+func recordItemsFromResult(result *chroma.GetResults) []recordItem {
+	var items = make([]recordItem, 0, len(result.Ids))
+	for i, id := range result.Ids {
+		item := recordItem{ID: id}
+		if i < len(result.Documents) {
+			document := result.Documents[i]
+			item.Document = &document
+		}
+		if i < len(result.Metadatas) {
+			item.Metadata = result.Metadatas[i]
+		}
+		if i < len(result.Embeddings) && result.Embeddings[i] != nil && result.Embeddings[i].IsDefined() {
+			item.Embedding = embeddingValues(result.Embeddings[i])
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// recordWriter writes records to a record file.
+type recordWriter interface {
+	Write(records []recordItem) error
+	Close() error
+}
+
+// newRecordWriter creates a writer for the given format. The CSV writer needs the metadata keys upfront as they are
+// flattened to columns.
+func newRecordWriter(w io.Writer, format RecordFileFormat, metadataKeys []string, includeEmbeddings bool) (recordWriter, error) {
+	switch format {
+	case RecordFormatJSONL:
+		return &jsonlRecordWriter{encoder: json.NewEncoder(w)}, nil
+	case RecordFormatCSV:
+		return newCSVRecordWriter(w, metadataKeys, includeEmbeddings)
+	case RecordFormatParquet:
+		return &parquetRecordWriter{writer: parquet.NewGenericWriter[parquetRecord](w)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported format: %v", format)
+	}
+}
+
+type jsonlRecordWriter struct {
+	encoder *json.Encoder
+}
+
+func (j *jsonlRecordWriter) Write(records []recordItem) error {
+	for _, record := range records {
+		if err := j.encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (j *jsonlRecordWriter) Close() error {
+	return nil
+}
+
+type csvRecordWriter struct {
+	writer            *csv.Writer
+	metadataKeys      []string
+	includeEmbeddings bool
+}
+
+// csvMetadataColumn returns the CSV column of a metadata key.
+func csvMetadataColumn(key string) string {
+	switch key {
+	case idColumn, documentColumn, embeddingColumn:
+		return metadataColumnPrefix + key
+	}
+	return key
+}
+
+func newCSVRecordWriter(w io.Writer, metadataKeys []string, includeEmbeddings bool) (*csvRecordWriter, error) {
+	c := &csvRecordWriter{writer: csv.NewWriter(w), metadataKeys: metadataKeys, includeEmbeddings: includeEmbeddings}
+	var header = []string{idColumn, documentColumn}
+	for _, key := range metadataKeys {
+		header = append(header, csvMetadataColumn(key))
+	}
+	if includeEmbeddings {
+		header = append(header, embeddingColumn)
+	}
+	return c, c.writer.Write(header)
+}
+
+func (c *csvRecordWriter) Write(records []recordItem) error {
+	for _, record := range records {
+		var row = []string{record.ID, ""}
+		if record.Document != nil {
+			row[1] = *record.Document
+		}
+		for _, key := range c.metadataKeys {
+			row = append(row, formatOptional(record.Metadata[key]))
+		}
+		if c.includeEmbeddings {
+			var embedding string
+			if record.Embedding != nil {
+				data, err := json.Marshal(record.Embedding)
+				if err != nil {
+					return err
+				}
+				embedding = string(data)
+			}
+			row = append(row, embedding)
+		}
+		if err := c.writer.Write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *csvRecordWriter) Close() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+// parquetRecord is the schema of the parquet record files. Metadata is stored as a JSON object as its keys and value
+// types differ between records.
+type parquetRecord struct {
+	ID        string    `parquet:"id"`
+	Document  *string   `parquet:"document,optional"`
+	Metadata  *string   `parquet:"metadata,optional"`
+	Embedding []float32 `parquet:"embedding,list"`
+}
+
+type parquetRecordWriter struct {
+	writer *parquet.GenericWriter[parquetRecord]
+}
+
+func (p *parquetRecordWriter) Write(records []recordItem) error {
+	var rows = make([]parquetRecord, 0, len(records))
+	for _, record := range records {
+		row := parquetRecord{ID: record.ID, Document: record.Document, Embedding: record.Embedding}
+		if record.Metadata != nil {
+			data, err := json.Marshal(record.Metadata)
+			if err != nil {
+				return err
+			}
+			metadata := string(data)
+			row.Metadata = &metadata
+		}
+		rows = append(rows, row)
+	}
+	_, err := p.writer.Write(rows)
+	return err
+}
+
+func (p *parquetRecordWriter) Close() error {
+	return p.writer.Close()
+}
+
+// collectMetadataKeys returns the sorted metadata keys of all records matching the filters.
+func collectMetadataKeys(ctx context.Context, col *chroma.Collection, batchSize int32, where map[string]interface{}, whereDocument map[string]interface{}) ([]string, error) {
+	var seen = make(map[string]bool)
+	err := forEachRecordBatch(ctx, col, batchSize, where, whereDocument, []types.QueryEnum{types.IMetadatas}, func(records []recordItem) error {
+		for _, record := range records {
+			for key := range record.Metadata {
+				seen[key] = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	var keys = make([]string, 0, len(seen))
+	for key := range seen {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// recordRowReader reads the rows of a record file as column to value maps. Next returns io.EOF after the last row.
+type recordRowReader interface {
+	Next() (map[string]interface{}, error)
+	Close() error
+}
+
+// openRecordRowReader opens a record file. JSONL and CSV can be read from stdin with the path -.
+func openRecordRowReader(path string, format RecordFileFormat, stdin io.Reader) (recordRowReader, error) {
+	if path == "-" {
+		if format == RecordFormatParquet {
+			return nil, fmt.Errorf("parquet files cannot be read from stdin")
+		}
+		return newRecordRowReader(io.NopCloser(stdin), format)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if format == RecordFormatParquet {
+		info, err := f.Stat()
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		pf, err := parquet.OpenFile(f, info.Size())
+		if err != nil {
+			_ = f.Close()
+			return nil, fmt.Errorf("invalid parquet file %v: %v", path, err)
+		}
+		return &parquetRowReader{file: f, reader: parquet.NewReader(pf)}, nil
+	}
+	return newRecordRowReader(f, format)
+}
+
+func newRecordRowReader(r io.ReadCloser, format RecordFileFormat) (recordRowReader, error) {
+	switch format {
+	case RecordFormatJSONL:
+		decoder := json.NewDecoder(r)
+		decoder.UseNumber()
+		return &jsonlRowReader{closer: r, decoder: decoder}, nil
+	case RecordFormatCSV:
+		reader := csv.NewReader(r)
+		header, err := reader.Read()
+		if err != nil {
+			_ = r.Close()
+			if err == io.EOF {
+				return nil, fmt.Errorf("the CSV file has no header")
+			}
+			return nil, err
+		}
+		return &csvRowReader{closer: r, reader: reader, header: header}, nil
+	default:
+		_ = r.Close()
+		return nil, fmt.Errorf("unsupported format: %v", format)
+	}
+}
+
+type jsonlRowReader struct {
+	closer  io.Closer
+	decoder *json.Decoder
+}
+
+func (j *jsonlRowReader) Next() (map[string]interface{}, error) {
+	var row map[string]interface{}
+	if err := j.decoder.Decode(&row); err != nil {
+		return nil, err
+	}
+	return row, nil
+}
+
+func (j *jsonlRowReader) Close() error {
+	return j.closer.Close()
+}
+
+// csvRowReader returns the cells as strings. Empty cells are omitted.
+type csvRowReader struct {
+	closer io.Closer
+	reader *csv.Reader
+	header []string
+}
+
+func (c *csvRowReader) Next() (map[string]interface{}, error) {
+	cells, err := c.reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	var row = make(map[string]interface{}, len(cells))
+	for i, cell := range cells {
+		if i < len(c.header) && cell != "" {
+			row[c.header[i]] = cell
+		}
+	}
+	return row, nil
+}
+
+func (c *csvRowReader) Close() error {
+	return c.closer.Close()
+}
+
+type parquetRowReader struct {
+	file   io.Closer
+	reader *parquet.Reader
+}
+
+func (p *parquetRowReader) Next() (map[string]interface{}, error) {
+	var row = make(map[string]interface{})
+	if err := p.reader.Read(&row); err != nil {
+		return nil, err
+	}
+	for k, v := range row {
+		row[k] = unwrapParquetList(v)
+	}
+	return row, nil
+}
+
+func (p *parquetRowReader) Close() error {
+	_ = p.reader.Close()
+	return p.file.Close()
+}
+
+// unwrapParquetList converts the {"list": [{"element": v}]} representation of parquet lists to plain lists.
+func unwrapParquetList(value interface{}) interface{} {
+	m, ok := value.(map[string]interface{})
+	if !ok || len(m) != 1 {
+		return value
+	}
+	list, ok := m["list"].([]interface{})
+	if !ok {
+		return value
+	}
+	var values = make([]interface{}, 0, len(list))
+	for _, element := range list {
+		if e, ok := element.(map[string]interface{}); ok && len(e) == 1 {
+			if v, ok := e["element"]; ok {
+				values = append(values, unwrapParquetList(v))
+				continue
+			}
+		}
+		values = append(values, unwrapParquetList(element))
+	}
+	return values
+}
+
+// recordMapping maps the columns of a record file to the record fields. All columns that are not mapped to the id,
+// document or embedding are metadata unless MetadataColumns is set. Object values, and the JSON objects of the
+// metadata column, are merged into the metadata.
+type recordMapping struct {
+	IDColumn        string
+	DocumentColumn  string
+	EmbeddingColumn string
+	MetadataColumns []string
+	// ParseStrings parses string metadata values as booleans and numbers where possible, e.g. for CSV files.
+	ParseStrings bool
+}
+
+func (m *recordMapping) record(row map[string]interface{}) (recordItem, error) {
+	var record recordItem
+	if v, ok := row[m.IDColumn]; ok && v != nil {
+		record.ID = formatOptional(v)
+	}
+	if v, ok := row[m.DocumentColumn]; ok && v != nil {
+		document := formatOptional(v)
+		record.Document = &document
+	}
+	if v, ok := row[m.EmbeddingColumn]; ok && v != nil {
+		embedding, err := parseEmbeddingValue(v)
+		if err != nil {
+			return record, fmt.Errorf("invalid %v: %v", m.EmbeddingColumn, err)
+		}
+		if len(embedding) > 0 {
+			record.Embedding = embedding
+		}
+	}
+	columns := m.MetadataColumns
+	if columns == nil {
+		for column := range row {
+			if column != m.IDColumn && column != m.DocumentColumn && column != m.EmbeddingColumn {
+				columns = append(columns, column)
+			}
+		}
+		sort.Strings(columns)
+	}
+	for _, column := range columns {
+		v, ok := row[column]
+		if !ok || v == nil {
+			continue
+		}
+		if s, ok := v.(string); ok && column == metadataColumn && strings.HasPrefix(strings.TrimSpace(s), "{") {
+			decoder := json.NewDecoder(strings.NewReader(s))
+			decoder.UseNumber()
+			var object map[string]interface{}
+			if err := decoder.Decode(&object); err != nil {
+				return record, fmt.Errorf("invalid %v: %v", column, err)
+			}
+			v = object
+		}
+		if record.Metadata == nil {
+			record.Metadata = make(map[string]interface{})
+		}
+		if object, ok := v.(map[string]interface{}); ok {
+			for key, value := range object {
+				if value != nil {
+					record.Metadata[key] = m.metadataValue(value)
+				}
+			}
+			continue
+		}
+		record.Metadata[strings.TrimPrefix(column, metadataColumnPrefix)] = m.metadataValue(v)
+	}
+	return record, nil
+}
+
+// metadataValue converts a value to one of the metadata types supported by Chroma (string, int, float and bool).
+func (m *recordMapping) metadataValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		if m.ParseStrings {
+			return parseMetadataValue(v)
+		}
+		return v
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	case bool, int, int32, int64, float32, float64:
+		return v
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(data)
+	}
+}
+
+// parseEmbeddingValue parses an embedding given as a list of numbers, a JSON list or comma separated floats.
+func parseEmbeddingValue(value interface{}) ([]float32, error) {
+	switch v := value.(type) {
+	case string:
+		v = strings.TrimSpace(v)
+		if v == "" {
+			return nil, nil
+		}
+		if strings.HasPrefix(v, "[") {
+			var embedding []float32
+			if err := json.Unmarshal([]byte(v), &embedding); err != nil {
+				return nil, err
+			}
+			return embedding, nil
+		}
+		embedding, err := parseEmbedding(v)
+		if err != nil {
+			return nil, err
+		}
+		return embeddingValues(embedding), nil
+	case []float32:
+		return v, nil
+	case []interface{}:
+		var embedding = make([]float32, len(v))
+		for i, e := range v {
+			switch n := e.(type) {
+			case json.Number:
+				f, err := n.Float64()
+				if err != nil {
+					return nil, err
+				}
+				embedding[i] = float32(f)
+			case float64:
+				embedding[i] = float32(n)
+			case float32:
+				embedding[i] = n
+			case int64:
+				embedding[i] = float32(n)
+			case int32:
+				embedding[i] = float32(n)
+			case int:
+				embedding[i] = float32(n)
+			default:
+				return nil, fmt.Errorf("expected a number, got %v", e)
+			}
+		}
+		return embedding, nil
+	default:
+		return nil, fmt.Errorf("expected a list of numbers, got %v", value)
+	}
+}
+
+type IDGenerator string
+
+const (
+	IDGeneratorUUID IDGenerator = "uuid"
+	IDGeneratorULID IDGenerator = "ulid"
+	IDGeneratorHash IDGenerator = "hash"
+)
+
+// generateRecordID returns a new id for a record. The hash generator returns the SHA-256 of the document, or of the
+// embedding if there is no document, so importing the same content twice results in the same records.
+func generateRecordID(generator IDGenerator, record recordItem) (string, error) {
+	switch generator {
+	case IDGeneratorUUID:
+		return uuid.NewString(), nil
+	case IDGeneratorULID:
+		return ulid.Make().String(), nil
+	case IDGeneratorHash:
+		var content []byte
+		switch {
+		case record.Document != nil:
+			content = []byte(*record.Document)
+		case record.Embedding != nil:
+			data, err := json.Marshal(record.Embedding)
+			if err != nil {
+				return "", err
+			}
+			content = data
+		default:
+			return "", fmt.Errorf("cannot generate a content hash id for a record without document and embedding")
+		}
+		sum := sha256.Sum256(content)
+		return hex.EncodeToString(sum[:]), nil
+	default:
+		return "", fmt.Errorf("invalid id generator: %v. must be one of uuid, ulid, hash", generator)
+	}
+}
+
+// upsertRecords upserts records into a collection. Records without embeddings are embedded with the embedding function
+// of the collection. Chroma takes documents for all records of an upsert or none, so records with and without documents
+// are upserted separately and records without a document do not get an empty one.
+func upsertRecords(ctx context.Context, col *chroma.Collection, records []recordItem) error {
+	var withDocuments = make([]recordItem, 0, len(records))
+	var withoutDocuments = make([]recordItem, 0)
+	for _, record := range records {
+		if record.Document != nil {
+			withDocuments = append(withDocuments, record)
+		} else {
+			withoutDocuments = append(withoutDocuments, record)
+		}
+	}
+	for _, batch := range [][]recordItem{withDocuments, withoutDocuments} {
+		if err := upsertRecordBatch(ctx, col, batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// upsertRecordBatch upserts records that all have a document or all have none.
+func upsertRecordBatch(ctx context.Context, col *chroma.Collection, records []recordItem) error {
+	if len(records) == 0 {
+		return nil
+	}
+	var embeddings = make([]*types.Embedding, len(records))
+	var ids = make([]string, len(records))
+	var metadatas = make([]map[string]interface{}, len(records))
+	var documents []string
+	var toEmbed []string
+	var toEmbedIndexes []int
+	for i, record := range records {
+		ids[i] = record.ID
+		metadatas[i] = record.Metadata
+		if record.Document != nil {
+			if documents == nil {
+				documents = make([]string, len(records))
+			}
+			documents[i] = *record.Document
+		}
+		if record.Embedding != nil {
+			embeddings[i] = types.NewEmbeddingFromFloat32(record.Embedding)
+			continue
+		}
+		if record.Document == nil {
+			return fmt.Errorf("record %v has neither a document nor an embedding", record.ID)
+		}
+		toEmbed = append(toEmbed, *record.Document)
+		toEmbedIndexes = append(toEmbedIndexes, i)
+	}
+	if len(toEmbed) > 0 {
+		if col.EmbeddingFunction == nil {
+			return fmt.Errorf("an embedding function (--embedding-function) is required to embed records without embeddings")
+		}
+		embedded, err := col.EmbeddingFunction.EmbedDocuments(ctx, toEmbed)
+		if err != nil {
+			return err
+		}
+		for i, embedding := range embedded {
+			embeddings[toEmbedIndexes[i]] = embedding
+		}
+	}
+	_, err := col.Upsert(ctx, embeddings, metadatas, documents, ids)
+	return err
+}
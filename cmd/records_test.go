@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/require"
+)
+
+func helperRecords() []recordItem {
+	first := "first, doc"
+	return []recordItem{
+		{ID: "id1", Document: &first, Metadata: map[string]interface{}{"tag": "x", "id": "meta-id"}, Embedding: []float32{0.5, 1}},
+		{ID: "id2", Metadata: map[string]interface{}{"count": 2}},
+	}
+}
+
+func TestGetRecordFileFormat(t *testing.T) {
+	var tests = []struct {
+		format   string
+		path     string
+		expected RecordFileFormat
+	}{
+		{"", "records.csv", RecordFormatCSV},
+		{"", "records.PARQUET", RecordFormatParquet},
+		{"", "records.jsonl", RecordFormatJSONL},
+		{"", "", RecordFormatJSONL},
+		{"csv", "records.jsonl", RecordFormatCSV},
+		{"ndjson", "", RecordFormatJSONL},
+	}
+	for _, tt := range tests {
+		format, err := getRecordFileFormat(tt.format, tt.path)
+		require.NoError(t, err)
+		require.Equal(t, tt.expected, format)
+	}
+	_, err := getRecordFileFormat("xml", "")
+	require.Error(t, err)
+}
+
+func TestRecordWriters(t *testing.T) {
+	t.Run("JSONL", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		w, err := newRecordWriter(buf, RecordFormatJSONL, nil, true)
+		require.NoError(t, err)
+		require.NoError(t, w.Write(helperRecords()))
+		require.NoError(t, w.Close())
+		require.Equal(t, `{"id":"id1","document":"first, doc","metadata":{"id":"meta-id","tag":"x"},"embedding":[0.5,1]}
+{"id":"id2","metadata":{"count":2}}
+`, buf.String())
+	})
+
+	t.Run("CSV", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		w, err := newRecordWriter(buf, RecordFormatCSV, []string{"count", "id", "tag"}, true)
+		require.NoError(t, err)
+		require.NoError(t, w.Write(helperRecords()))
+		require.NoError(t, w.Close())
+		require.Equal(t, `id,document,count,metadata.id,tag,embedding
+id1,"first, doc",,meta-id,x,"[0.5,1]"
+id2,,2,,,
+`, buf.String())
+	})
+
+	t.Run("Parquet", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		w, err := newRecordWriter(buf, RecordFormatParquet, nil, true)
+		require.NoError(t, err)
+		require.NoError(t, w.Write(helperRecords()))
+		require.NoError(t, w.Close())
+		rows, err := parquet.Read[parquetRecord](bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		require.NoError(t, err)
+		require.Len(t, rows, 2)
+		require.Equal(t, "id1", rows[0].ID)
+		require.Equal(t, "first, doc", *rows[0].Document)
+		require.JSONEq(t, `{"id":"meta-id","tag":"x"}`, *rows[0].Metadata)
+		require.Equal(t, []float32{0.5, 1}, rows[0].Embedding)
+		require.Nil(t, rows[1].Document)
+		require.JSONEq(t, `{"count":2}`, *rows[1].Metadata)
+	})
+}
+
+func TestRecordMapping(t *testing.T) {
+	t.Run("Default mapping", func(t *testing.T) {
+		m := &recordMapping{IDColumn: idColumn, DocumentColumn: documentColumn, EmbeddingColumn: embeddingColumn}
+		record, err := m.record(map[string]interface{}{
+			"id":        "id1",
+			"document":  "doc",
+			"embedding": []interface{}{json.Number("0.5"), 1.0},
+			"metadata":  map[string]interface{}{"count": json.Number("3"), "score": json.Number("0.5")},
+			"tag":       "x",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "id1", record.ID)
+		require.Equal(t, "doc", *record.Document)
+		require.Equal(t, []float32{0.5, 1}, record.Embedding)
+		require.Equal(t, map[string]interface{}{"count": int64(3), "score": 0.5, "tag": "x"}, record.Metadata)
+	})
+
+	t.Run("Custom columns", func(t *testing.T) {
+		m := &recordMapping{IDColumn: "url", DocumentColumn: "body", EmbeddingColumn: "vector", MetadataColumns: []string{"year"}, ParseStrings: true}
+		record, err := m.record(map[string]interface{}{"url": "u1", "body": "text", "vector": "[1,2]", "year": "2020", "ignored": "x"})
+		require.NoError(t, err)
+		require.Equal(t, "u1", record.ID)
+		require.Equal(t, "text", *record.Document)
+		require.Equal(t, []float32{1, 2}, record.Embedding)
+		require.Equal(t, map[string]interface{}{"year": int64(2020)}, record.Metadata)
+	})
+
+	t.Run("Prefixed and JSON metadata columns", func(t *testing.T) {
+		m := &recordMapping{IDColumn: idColumn, DocumentColumn: documentColumn, EmbeddingColumn: embeddingColumn}
+		record, err := m.record(map[string]interface{}{"id": "id1", "metadata.id": "meta-id", "metadata": `{"n": 1}`})
+		require.NoError(t, err)
+		require.Equal(t, map[string]interface{}{"id": "meta-id", "n": int64(1)}, record.Metadata)
+	})
+
+	t.Run("Invalid embedding", func(t *testing.T) {
+		m := &recordMapping{IDColumn: idColumn, DocumentColumn: documentColumn, EmbeddingColumn: embeddingColumn}
+		_, err := m.record(map[string]interface{}{"id": "id1", "embedding": []interface{}{"x"}})
+		require.Error(t, err)
+	})
+}
+
+func TestRecordRowReaders(t *testing.T) {
+	t.Run("CSV", func(t *testing.T) {
+		r, err := newRecordRowReader(io.NopCloser(strings.NewReader("id,document,tag\nid1,\"a, b\",x\nid2,,\n")), RecordFormatCSV)
+		require.NoError(t, err)
+		row, err := r.Next()
+		require.NoError(t, err)
+		require.Equal(t, map[string]interface{}{"id": "id1", "document": "a, b", "tag": "x"}, row)
+		row, err = r.Next()
+		require.NoError(t, err)
+		require.Equal(t, map[string]interface{}{"id": "id2"}, row)
+		_, err = r.Next()
+		require.Equal(t, io.EOF, err)
+	})
+
+	t.Run("Parquet round trip", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "records.parquet")
+		f, err := os.Create(file)
+		require.NoError(t, err)
+		w, err := newRecordWriter(f, RecordFormatParquet, nil, true)
+		require.NoError(t, err)
+		require.NoError(t, w.Write(helperRecords()))
+		require.NoError(t, w.Close())
+		require.NoError(t, f.Close())
+		r, err := openRecordRowReader(file, RecordFormatParquet, nil)
+		require.NoError(t, err)
+		defer r.Close()
+		m := &recordMapping{IDColumn: idColumn, DocumentColumn: documentColumn, EmbeddingColumn: embeddingColumn}
+		row, err := r.Next()
+		require.NoError(t, err)
+		record, err := m.record(row)
+		require.NoError(t, err)
+		require.Equal(t, helperRecords()[0], record)
+		row, err = r.Next()
+		require.NoError(t, err)
+		record, err = m.record(row)
+		require.NoError(t, err)
+		require.Equal(t, recordItem{ID: "id2", Metadata: map[string]interface{}{"count": int64(2)}}, record)
+	})
+}
+
+func TestGenerateRecordID(t *testing.T) {
+	document := "hello"
+	id, err := generateRecordID(IDGeneratorHash, recordItem{Document: &document})
+	require.NoError(t, err)
+	require.Equal(t, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", id)
+	id, err = generateRecordID(IDGeneratorUUID, recordItem{})
+	require.NoError(t, err)
+	require.Len(t, id, 36)
+	id, err = generateRecordID(IDGeneratorULID, recordItem{})
+	require.NoError(t, err)
+	require.Len(t, id, 26)
+	_, err = generateRecordID(IDGeneratorHash, recordItem{})
+	require.Error(t, err)
+}
+
+func TestUpsertRecordsMixedDocuments(t *testing.T) {
+	client := setup()
+	defer tearDown(client)
+	var collectionName = getRandomName("upsert-mixed")
+	col := helperCreateCollection(t, client, collectionName)
+	document := "a document"
+	records := []recordItem{
+		{ID: "with-document", Document: &document, Embedding: []float32{0.1, 0.2}},
+		{ID: "without-document", Metadata: map[string]interface{}{"a": "b"}, Embedding: []float32{0.3, 0.4}},
+	}
+	require.NoError(t, upsertRecords(context.TODO(), col, records))
+
+	// chroma-go decodes missing documents as empty strings, so the documents are read from the API directly
+	body, err := json.Marshal(map[string]interface{}{"ids": []string{"with-document", "without-document"}, "include": []string{"documents"}})
+	require.NoError(t, err)
+	endpoint := client.ApiClient.GetConfig().Servers[0].URL + "/api/v1/collections/" + col.ID + "/get"
+	resp, err := http.Post(endpoint, "application/json", bytes.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var result struct {
+		IDs       []string  `json:"ids"`
+		Documents []*string `json:"documents"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	var documents = make(map[string]*string)
+	for i, id := range result.IDs {
+		documents[id] = result.Documents[i]
+	}
+	require.Len(t, documents, 2)
+	require.NotNil(t, documents["with-document"])
+	require.Equal(t, document, *documents["with-document"])
+	require.Nil(t, documents["without-document"], "a record without a document must not get an empty one")
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	chroma "github.com/amikos-tech/chroma-go"
+)
+
+// collectionRef identifies a collection, optionally on another server, tenant or database than the command defaults.
+// The supported forms are:
+//
+//	collection
+//	alias:collection
+//	alias:tenant/database/collection
+//	alias/tenant/database/collection
+//	tenant/database/collection
+type collectionRef struct {
+	Alias      string
+	Tenant     string
+	Database   string
+	Collection string
+}
+
+func parseCollectionRef(value string) (collectionRef, error) {
+	var ref collectionRef
+	path := value
+	if alias, rest, found := strings.Cut(value, ":"); found {
+		if alias == "" {
+			return ref, fmt.Errorf("invalid collection reference %v: empty server alias", value)
+		}
+		ref.Alias = alias
+		path = rest
+	}
+	parts := strings.Split(path, "/")
+	for _, part := range parts {
+		if part == "" {
+			return ref, fmt.Errorf("invalid collection reference %v: empty path element", value)
+		}
+	}
+	switch {
+	case len(parts) == 1:
+		ref.Collection = parts[0]
+	case len(parts) == 3:
+		ref.Tenant, ref.Database, ref.Collection = parts[0], parts[1], parts[2]
+	case len(parts) == 4 && ref.Alias == "":
+		ref.Alias, ref.Tenant, ref.Database, ref.Collection = parts[0], parts[1], parts[2], parts[3]
+	default:
+		return ref, fmt.Errorf("invalid collection reference %v. expected collection, alias:collection, alias:tenant/database/collection or alias/tenant/database/collection", value)
+	}
+	return ref, nil
+}
+
+func (r collectionRef) String() string {
+	var sb strings.Builder
+	if r.Alias != "" {
+		sb.WriteString(r.Alias + ":")
+	}
+	if r.Tenant != "" || r.Database != "" {
+		sb.WriteString(r.Tenant + "/" + r.Database + "/")
+	}
+	sb.WriteString(r.Collection)
+	return sb.String()
+}
+
+// getClientForRef returns a client for the server, tenant and database of the reference. The parts the reference does
+// not specify are taken from the --alias, --tenant and --database flags of the command.
+func getClientForRef(cmd *cobra.Command, ref collectionRef) (*chroma.Client, error) {
+	alias, tenant, database := ref.Alias, ref.Tenant, ref.Database
+	if f := cmd.Flag("alias"); f != nil && f.Changed && alias == "" {
+		alias = f.Value.String()
+	}
+	if f := cmd.Flag("tenant"); f != nil && f.Changed && tenant == "" {
+		tenant = f.Value.String()
+	}
+	if f := cmd.Flag("database"); f != nil && f.Changed && database == "" {
+		database = f.Value.String()
+	}
+	return getClient(alias, tenant, database)
+}
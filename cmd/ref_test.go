@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCollectionRef(t *testing.T) {
+	var tests = []struct {
+		value    string
+		expected collectionRef
+	}{
+		{"my-collection", collectionRef{Collection: "my-collection"}},
+		{"prod:my-collection", collectionRef{Alias: "prod", Collection: "my-collection"}},
+		{"prod:acme/db1/my-collection", collectionRef{Alias: "prod", Tenant: "acme", Database: "db1", Collection: "my-collection"}},
+		{"prod/acme/db1/my-collection", collectionRef{Alias: "prod", Tenant: "acme", Database: "db1", Collection: "my-collection"}},
+		{"acme/db1/my-collection", collectionRef{Tenant: "acme", Database: "db1", Collection: "my-collection"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			ref, err := parseCollectionRef(tt.value)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, ref)
+		})
+	}
+
+	for _, value := range []string{"", ":my-collection", "prod:", "db1/my-collection", "prod:a/b/c/d", "a//c"} {
+		t.Run("Invalid "+value, func(t *testing.T) {
+			_, err := parseCollectionRef(value)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestCollectionRefString(t *testing.T) {
+	for _, value := range []string{"my-collection", "prod:my-collection", "prod:acme/db1/my-collection", "acme/db1/my-collection"} {
+		ref, err := parseCollectionRef(value)
+		require.NoError(t, err)
+		require.Equal(t, value, ref.String())
+	}
+}
@@ -0,0 +1,424 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	chroma "github.com/amikos-tech/chroma-go"
+	"github.com/amikos-tech/chroma-go/collection"
+)
+
+const (
+	restoreCreate    = "create"
+	restoreOverwrite = "overwrite"
+	restoreConflict  = "conflict"
+)
+
+// restoreItem is a collection restored from a backup archive.
+type restoreItem struct {
+	Source      string `json:"source" yaml:"source"`
+	Destination string `json:"destination" yaml:"destination"`
+	Records     int    `json:"records" yaml:"records"`
+	Action      string `json:"action" yaml:"action"`
+
+	collection  backupCollection
+	destination collectionRef
+	col         *chroma.Collection
+}
+
+// parseRenames parses old=new collection name mappings.
+func parseRenames(renames []string) (map[string]string, error) {
+	var result = make(map[string]string, len(renames))
+	for _, rename := range renames {
+		parts := strings.SplitN(rename, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid rename %v. should be old=new", rename)
+		}
+		result[parts[0]] = parts[1]
+	}
+	return result, nil
+}
+
+// planRestore resolves the destination of every collection in the manifest and checks whether it already exists.
+func planRestore(cmd *cobra.Command, manifest *backupManifest) ([]*restoreItem, error) {
+	alias, err := cmd.Flags().GetString("alias")
+	if err != nil {
+		return nil, err
+	}
+	if alias == "" {
+		alias = viper.GetString("active_server")
+	}
+	renameVals, err := cmd.Flags().GetStringSlice("rename")
+	if err != nil {
+		return nil, err
+	}
+	renames, err := parseRenames(renameVals)
+	if err != nil {
+		return nil, err
+	}
+	names, err := cmd.Flags().GetStringSlice("collection")
+	if err != nil {
+		return nil, err
+	}
+	var selected = make(map[string]bool)
+	for _, name := range names {
+		selected[name] = false
+	}
+	overwrite, err := cmd.Flags().GetBool("overwrite")
+	if err != nil {
+		return nil, err
+	}
+	var plan = make([]*restoreItem, 0, len(manifest.Collections))
+	for _, c := range manifest.Collections {
+		if _, ok := selected[c.Name]; len(names) > 0 && !ok {
+			continue
+		}
+		selected[c.Name] = true
+		destination := collectionRef{Alias: alias, Tenant: c.Tenant, Database: c.Database, Collection: c.Name}
+		if f := cmd.Flag("tenant"); f.Changed {
+			destination.Tenant = f.Value.String()
+		}
+		if f := cmd.Flag("database"); f.Changed {
+			destination.Database = f.Value.String()
+		}
+		if name, ok := renames[c.Name]; ok {
+			destination.Collection = name
+		}
+		client, err := getClient(destination.Alias, destination.Tenant, destination.Database)
+		if err != nil {
+			return nil, err
+		}
+		// a database that does not exist yet is created by the restore
+		exists, err := collectionExists(client, destination.Collection)
+		if err != nil {
+			exists = false
+		}
+		var action = restoreCreate
+		if exists && overwrite {
+			action = restoreOverwrite
+		} else if exists {
+			action = restoreConflict
+		}
+		plan = append(plan, &restoreItem{
+			Source:      c.ref().String(),
+			Destination: destination.String(),
+			Records:     c.total(),
+			Action:      action,
+			collection:  c,
+			destination: destination,
+		})
+	}
+	for _, name := range names {
+		if !selected[name] {
+			return nil, fmt.Errorf("collection %v is not in the backup", name)
+		}
+	}
+	return plan, nil
+}
+
+// ensureDatabase creates the tenant and database of the client if they do not exist.
+func ensureDatabase(ctx context.Context, client *chroma.Client) error {
+	if _, err := client.GetTenant(ctx, client.Tenant); err != nil {
+		if _, err := client.CreateTenant(ctx, client.Tenant); err != nil {
+			return fmt.Errorf("failed to create tenant %v: %v", client.Tenant, err)
+		}
+	}
+	if _, err := client.GetDatabase(ctx, client.Database, &client.Tenant); err != nil {
+		if _, err := client.CreateDatabase(ctx, client.Database, &client.Tenant); err != nil {
+			return fmt.Errorf("failed to create database %v: %v", client.Database, err)
+		}
+	}
+	return nil
+}
+
+// restoreCollectionRecords upserts the JSONL records of a backup file into a collection in batches.
+func restoreCollectionRecords(ctx context.Context, col *chroma.Collection, r io.Reader, batchSize int) (int, error) {
+	reader, err := newRecordRowReader(io.NopCloser(r), RecordFormatJSONL)
+	if err != nil {
+		return 0, err
+	}
+	mapping := recordMapping{IDColumn: idColumn, DocumentColumn: documentColumn, EmbeddingColumn: embeddingColumn}
+	var restored int
+	var batch = make([]recordItem, 0, batchSize)
+	for {
+		row, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return restored, err
+		}
+		record, err := mapping.record(row)
+		if err != nil {
+			return restored, err
+		}
+		batch = append(batch, record)
+		if len(batch) == batchSize {
+			if err := upsertRecords(ctx, col, batch); err != nil {
+				return restored, err
+			}
+			restored += len(batch)
+			batch = batch[:0]
+		}
+	}
+	if err := upsertRecords(ctx, col, batch); err != nil {
+		return restored, err
+	}
+	return restored + len(batch), nil
+}
+
+// backupArchive is a verified backup archive.
+type backupArchive struct {
+	path     string
+	manifest *backupManifest
+}
+
+// resolveBackupChain returns the archives needed to restore a backup, starting with the full backup an incremental
+// backup is based on. Base archives are looked up in the catalog and next to the archive.
+func resolveBackupChain(archive string, manifest *backupManifest) ([]backupArchive, error) {
+	var chain = []backupArchive{{path: archive, manifest: manifest}}
+	catalog, err := loadBackupCatalog()
+	if err != nil {
+		catalog = &backupCatalog{}
+	}
+	for current := manifest; current.Type == backupTypeIncremental; {
+		var path string
+		if entry := catalog.find(current.Base); entry != nil {
+			path = entry.File
+		}
+		if _, err := os.Stat(path); path == "" || err != nil {
+			path = filepath.Join(filepath.Dir(chain[0].path), current.BaseFile)
+		}
+		base, err := verifyBackupArchive(path)
+		if err != nil {
+			return nil, fmt.Errorf("base backup %v: %v", current.Base, err)
+		}
+		if base.ID != current.Base {
+			return nil, fmt.Errorf("base backup %v: %v contains backup %v", current.Base, path, base.ID)
+		}
+		for _, part := range chain {
+			if part.manifest.ID == base.ID {
+				return nil, fmt.Errorf("backup chain of %v contains a cycle", archive)
+			}
+		}
+		chain = append([]backupArchive{{path: path, manifest: base}}, chain...)
+		current = base
+	}
+	return chain, nil
+}
+
+// restoreDeletedRecords deletes the records listed in a deleted file of an incremental backup.
+func restoreDeletedRecords(ctx context.Context, col *chroma.Collection, r io.Reader, batchSize int) (int, error) {
+	var deleted int
+	var ids = make([]string, 0, batchSize)
+	flush := func() error {
+		if len(ids) == 0 {
+			return nil
+		}
+		if _, err := col.Delete(ctx, ids, nil, nil); err != nil {
+			return err
+		}
+		deleted += len(ids)
+		ids = ids[:0]
+		return nil
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if id := scanner.Text(); id != "" {
+			ids = append(ids, id)
+		}
+		if len(ids) == batchSize {
+			if err := flush(); err != nil {
+				return deleted, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return deleted, err
+	}
+	return deleted, flush()
+}
+
+// applyBackupArchive restores the records of one archive of a backup chain into the planned collections.
+func applyBackupArchive(cmd *cobra.Command, part backupArchive, items map[string]*restoreItem, batchSize int) error {
+	ctx := context.TODO()
+	var records = make(map[string]*restoreItem)
+	var deletes = make(map[string]*restoreItem)
+	for _, c := range part.manifest.Collections {
+		item, ok := items[c.key()]
+		if !ok {
+			continue
+		}
+		records[c.File] = item
+		if c.DeletedFile != "" {
+			deletes[c.DeletedFile] = item
+		}
+	}
+	_, err := readBackupArchive(part.path, func(_ *backupManifest, name string, r io.Reader) error {
+		if item, ok := records[name]; ok {
+			restored, err := restoreCollectionRecords(ctx, item.col, r, batchSize)
+			if err != nil {
+				return fmt.Errorf("failed to restore %v after %v records: %v", item.Destination, restored, err)
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "restored %v: %v records\n", item.Destination, restored)
+		}
+		if item, ok := deletes[name]; ok {
+			deleted, err := restoreDeletedRecords(ctx, item.col, r, batchSize)
+			if err != nil {
+				return fmt.Errorf("failed to delete records from %v: %v", item.Destination, err)
+			}
+			if deleted > 0 {
+				fmt.Fprintf(cmd.ErrOrStderr(), "deleted %v records from %v\n", deleted, item.Destination)
+			}
+		}
+		return nil
+	})
+	return err
+}
+
+func restoreBackup(cmd *cobra.Command, args []string) error {
+	archive := args[0]
+	dryRun, err := cmd.Flags().GetBool("dry-run")
+	if err != nil {
+		return err
+	}
+	batchSize, err := cmd.Flags().GetInt("batch-size")
+	if err != nil {
+		return err
+	}
+	if batchSize <= 0 {
+		err := fmt.Errorf("batch-size must be greater than 0")
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	manifest, err := verifyBackupArchive(archive)
+	if err != nil {
+		err = fmt.Errorf("cannot restore %v: %v", archive, err)
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	chain, err := resolveBackupChain(archive, manifest)
+	if err != nil {
+		err = fmt.Errorf("cannot restore %v: %v", archive, err)
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	if len(chain) > 1 {
+		var ids = make([]string, 0, len(chain))
+		for _, part := range chain {
+			ids = append(ids, part.manifest.ID)
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "restoring backup chain %v\n", strings.Join(ids, " -> "))
+	}
+	plan, err := planRestore(cmd, manifest)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	var conflicts []string
+	for _, item := range plan {
+		if item.Action == restoreConflict {
+			conflicts = append(conflicts, item.Destination)
+		}
+	}
+	if dryRun {
+		if err := printRestorePlan(cmd, plan); err != nil {
+			cmd.Printf("%v\n", err)
+			return err
+		}
+		cmd.Printf("dry run: nothing was restored\n")
+		return nil
+	}
+	if len(conflicts) > 0 {
+		err := fmt.Errorf("destination collections already exist: %v. use --overwrite to replace them or --rename to restore under a different name", strings.Join(conflicts, ", "))
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	ctx := context.TODO()
+	var items = make(map[string]*restoreItem, len(plan))
+	for _, item := range plan {
+		client, err := getClient(item.destination.Alias, item.destination.Tenant, item.destination.Database)
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			return err
+		}
+		if err := ensureDatabase(ctx, client); err != nil {
+			cmd.Printf("%v\n", err)
+			return err
+		}
+		if item.Action == restoreOverwrite {
+			if err := deleteScopedCollection(ctx, client, item.destination.Collection); err != nil {
+				cmd.Printf("%v\n", err)
+				return err
+			}
+		}
+		item.col, err = createScopedCollection(ctx, client, collection.WithName(item.destination.Collection), collection.WithMetadatas(item.collection.Metadata))
+		if err != nil {
+			err = fmt.Errorf("failed to create %v: %v", item.Destination, err)
+			cmd.Printf("%v\n", err)
+			return err
+		}
+		items[item.collection.key()] = item
+	}
+	for _, part := range chain {
+		if err := applyBackupArchive(cmd, part, items, batchSize); err != nil {
+			cmd.Printf("%v\n", err)
+			return err
+		}
+	}
+	return printRestorePlan(cmd, plan)
+}
+
+func printRestorePlan(cmd *cobra.Command, plan []*restoreItem) error {
+	var rows = make([][]string, 0, len(plan))
+	for _, item := range plan {
+		rows = append(rows, []string{item.Source, item.Destination, strconv.Itoa(item.Records), item.Action})
+	}
+	return printOutput(cmd, &tableOutput{
+		Headers: []string{"SOURCE", "DESTINATION", "RECORDS", "ACTION"},
+		Rows:    rows,
+		Items:   plan,
+	})
+}
+
+var RestoreCommand = &cobra.Command{
+	Use:   "restore <archive>",
+	Short: "Restore collections from a backup archive",
+	Long: `Restore the collections of an archive created with chroma backup. The checksums of the archive are verified
+before anything is written. An incremental backup is restored together with the backups it is based on, which are
+looked up in the backup catalog and in the directory of the archive. Collections are restored to the tenant and database they were backed up from unless
+--tenant or --database is given, missing tenants and databases are created. Existing collections are only replaced
+with --overwrite. Use --dry-run to see what would be restored.`,
+	Example: `  chroma restore my-database.tar.zst --dry-run
+  chroma restore my-database.tar.zst -s staging -t acme -d restored
+  chroma restore my-database.tar.zst -c my-collection --rename my-collection=my-collection-restored`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		err := restoreBackup(cmd, args)
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	RestoreCommand.Flags().StringP("alias", "s", "", "Server alias name. If not provided, the active server will be used.")
+	RestoreCommand.Flags().StringP("tenant", "t", "", "Restore into this tenant instead of the tenant of the backup")
+	RestoreCommand.Flags().StringP("database", "d", "", "Restore into this database instead of the database of the backup")
+	RestoreCommand.Flags().StringSliceP("collection", "c", []string{}, "Restore only the given collections. Can be repeated.")
+	RestoreCommand.Flags().StringSlice("rename", []string{}, "Restore a collection under a different name, e.g. old=new. Can be repeated.")
+	RestoreCommand.Flags().Bool("overwrite", false, "Replace destination collections that already exist")
+	RestoreCommand.Flags().Bool("dry-run", false, "Show what would be restored without writing anything")
+	RestoreCommand.Flags().IntP("batch-size", "b", 100, "Number of records written per request")
+	RootCmd.AddCommand(RestoreCommand)
+}
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/amikos-tech/chroma-cli/chroma/secrets"
+	clitypes "github.com/amikos-tech/chroma-cli/chroma/types"
+	"github.com/mitchellh/go-homedir"
+	"github.com/spf13/viper"
+)
+
+const EnvChromaSecretsPassphrase = "CHROMA_SECRETS_PASSPHRASE"
+
+// secretBackends caches the backends of the command so the passphrase of the vault file is asked once.
+var secretBackends = make(map[string]secrets.Backend)
+
+// defaultSecretBackend returns the backend new credentials are stored in, configured with secrets.backend.
+func defaultSecretBackend() string {
+	if backend := viper.GetString("secrets.backend"); backend != "" {
+		return backend
+	}
+	return secrets.BackendKeyring
+}
+
+// secretsVaultFile returns the encrypted file of the file backend, by default next to the config file.
+func secretsVaultFile() string {
+	if file := viper.GetString("secrets.file"); file != "" {
+		if expanded, err := homedir.Expand(file); err == nil {
+			return expanded
+		}
+		return file
+	}
+	return filepath.Join(filepath.Dir(viper.ConfigFileUsed()), "secrets.age")
+}
+
+func getSecretBackend(name string) (secrets.Backend, error) {
+	var cacheKey = name
+	if name == secrets.BackendFile {
+		cacheKey = secrets.Ref(name, secretsVaultFile())
+	}
+	if backend, ok := secretBackends[cacheKey]; ok {
+		return backend, nil
+	}
+	backend, err := secrets.New(name, secrets.Options{
+		VaultFile: secretsVaultFile(),
+		Passphrase: func(confirm bool) (string, error) {
+			if passphrase := os.Getenv(EnvChromaSecretsPassphrase); passphrase != "" {
+				return passphrase, nil
+			}
+			if !isInteractive() {
+				return "", fmt.Errorf("the passphrase of the secrets file is required. set %v", EnvChromaSecretsPassphrase)
+			}
+			return promptPassphrase("Secrets passphrase", confirm)
+		},
+		Command: viper.GetString("secrets.command"),
+	})
+	if err != nil {
+		return nil, err
+	}
+	secretBackends[cacheKey] = backend
+	return backend, nil
+}
+
+// resolveSecret returns the credentials of a server, reading them from the secret backend if they are not stored in
+// the config file.
+func resolveSecret(auth *clitypes.AuthConfig) (string, error) {
+	if auth.SecretRef == "" {
+		return auth.Token, nil
+	}
+	name, key, err := secrets.ParseRef(auth.SecretRef)
+	if err != nil {
+		return "", err
+	}
+	backend, err := getSecretBackend(name)
+	if err != nil {
+		return "", err
+	}
+	secret, err := backend.Get(key)
+	if errors.Is(err, secrets.ErrNotFound) {
+		return "", fmt.Errorf("the credentials %v are missing from the %v secret backend", auth.SecretRef, name)
+	}
+	return secret, err
+}
+
+// storeSecret stores the credentials of the server in the backend and references them from the auth config. The
+// plain backend keeps them in the config file.
+func storeSecret(alias string, backendName string, auth *clitypes.AuthConfig, secret string) error {
+	if backendName == secrets.BackendPlain {
+		auth.Token, auth.SecretRef = secret, ""
+		return nil
+	}
+	backend, err := getSecretBackend(backendName)
+	if err != nil {
+		return err
+	}
+	if err := backend.Set(alias, secret); err != nil {
+		return fmt.Errorf("%v. choose another secret backend with --secret-backend", err)
+	}
+	auth.Token, auth.SecretRef = "", secrets.Ref(backendName, alias)
+	return nil
+}
+
+// deleteSecret removes the credentials referenced by the auth config from their backend. Missing credentials are
+// ignored.
+func deleteSecret(auth *clitypes.AuthConfig) error {
+	if auth == nil || auth.SecretRef == "" {
+		return nil
+	}
+	name, key, err := secrets.ParseRef(auth.SecretRef)
+	if err != nil {
+		return err
+	}
+	backend, err := getSecretBackend(name)
+	if err != nil {
+		return err
+	}
+	if err := backend.Delete(key); err != nil && !errors.Is(err, secrets.ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// describeCredentials returns the secret reference or the redacted credentials of a server.
+func describeCredentials(auth *clitypes.AuthConfig) string {
+	if auth == nil {
+		return ""
+	}
+	if auth.SecretRef != "" {
+		return auth.SecretRef
+	}
+	if auth.Type == clitypes.AuthTypeOIDC {
+		if auth.Token == "" {
+			return "not logged in"
+		}
+		return "tokens in the config file"
+	}
+	return redactSecret(auth.Type, auth.Token)
+}
@@ -1,13 +1,19 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/amikos-tech/chroma-cli/chroma/secrets"
+	clitypes "github.com/amikos-tech/chroma-cli/chroma/types"
 	"github.com/amikos-tech/chroma-cli/chroma/utils"
 	"github.com/charmbracelet/huh"
-	"github.com/go-playground/validator/v10"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -20,18 +26,7 @@ const (
 )
 
 func validateHost(host string) error {
-	if host == "" {
-		return fmt.Errorf("host cannot be empty")
-	}
-
-	v := validator.New()
-	hostErr := v.Var(host, "hostname")
-	ipErr := v.Var(host, "ip4_addr")
-	if hostErr != nil && ipErr != nil {
-		return fmt.Errorf("invalid host: %v", host)
-	}
-
-	return nil
+	return clitypes.ValidateHost(host)
 }
 
 func getPort(changed bool) (int, error) {
@@ -168,81 +163,57 @@ var AddCommand = &cobra.Command{
 		//	}
 		// }
 		// if confirm {
-		var servers = viper.GetStringMap("servers")
-		var setActive = false
-		if len(servers) == 0 {
-			servers = make(map[string]interface{})
-			setActive = true
+		servers, err := utils.GetServers()
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			os.Exit(1)
 		}
+		var setActive = len(servers) == 0
 		if !Overwrite {
 			if _, ok := servers[alias]; ok {
 				cmd.Printf("Server with alias %v already exists! \n", alias)
 				os.Exit(1)
 			}
 		}
-		servers[alias] = map[string]interface{}{
-			"host":     host,
-			"port":     actualPort,
-			"secure":   Secure,
-			"tenant":   tenant,
-			"database": database,
-		}
-		var envConfigProvided = false
-		var _authType = AuthTypeNone
-		var _authToken string
-		if os.Getenv(EnvChromaAPIToken) != "" {
-			envConfigProvided = true
-			_authType = AuthTypeToken
-			_authToken = os.Getenv(EnvChromaAPIToken)
-		}
-		if os.Getenv(EnvChromaXAPIToken) != "" {
-			envConfigProvided = true
-			_authType = AuthTypeXToken
-			_authToken = os.Getenv(EnvChromaXAPIToken)
-		}
-		if os.Getenv(EnvChromaBasicAuth) != "" {
-			envConfigProvided = true
-			_authType = AuthTypeBasic
-			_authToken = os.Getenv(EnvChromaBasicAuth)
-		}
-		fmt.Printf("login: %v\n", cmd.Flags().Changed("login"))
-		fmt.Printf("envConfigProvided: %v\n", envConfigProvided)
-		if !envConfigProvided && cmd.Flags().Changed("login") {
-			err := huh.NewSelect[AuthType]().
-				Title("Authorization Type").
-				Options(
-					huh.NewOption("Basic", AuthTypeBasic),
-					huh.NewOption("Token (Authorization)", AuthTypeToken),
-					huh.NewOption("Token (X-Chroma-Token)", AuthTypeXToken),
-				).
-				Value(&_authType).Run()
-			if err != nil {
-				return // TODO fix this
-			}
-			if _authType == AuthTypeBasic {
-				err := huh.NewInput().Value(&_authToken).Title("Basic Auth").Placeholder("username:password").Run()
-				if err != nil {
-					cmd.Printf("unable to get basic auth: %v\n", err)
-					os.Exit(1)
-				}
-			} else {
-				err := huh.NewInput().Value(&_authToken).Title("Token").Placeholder("token").Run()
-				if err != nil {
-					cmd.Printf("unable to get token: %v\n", err)
-					os.Exit(1)
-				}
-			}
+		var server = clitypes.ServerConfig{
+			Host:     host,
+			Port:     actualPort,
+			Secure:   Secure,
+			Tenant:   tenant,
+			Database: database,
+		}
+		if _, err := applyTLSFlags(cmd, &server); err != nil {
+			cmd.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		if err := server.Validate(); err != nil {
+			cmd.Printf("invalid server config: %v\n", err)
+			os.Exit(1)
+		}
+		if err := checkTLSOptions(cmd, alias, server); err != nil {
+			cmd.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		_authType, _authToken, err := readCredentials("", cmd.Flags().Changed("login"))
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			os.Exit(1)
 		}
 		if _authType != AuthTypeNone {
-			var _authInfo = make(map[string]string)
-			_authInfo["type"] = string(_authType)
-			_authInfo["token"] = _authToken
-			servers[alias].(map[string]interface{})["auth"] = _authInfo
+			backend, _ := cmd.Flags().GetString("secret-backend")
+			if backend == "" {
+				backend = defaultSecretBackend()
+			}
+			server.Auth = &clitypes.AuthConfig{Type: _authType}
+			if err := storeSecret(alias, backend, server.Auth, _authToken); err != nil {
+				cmd.Printf("unable to store the credentials: %v\n", err)
+				os.Exit(1)
+			}
 		}
-		viper.Set("servers", servers)
-		err := viper.WriteConfig()
+		servers[alias] = server
+		err = utils.SetServers(servers)
 		if err != nil {
-			cmd.Printf("unable to write to config file: %v\n", err)
+			cmd.Printf("%v\n", err)
 			os.Exit(1)
 		}
 		if setActive {
@@ -252,22 +223,27 @@ var AddCommand = &cobra.Command{
 				os.Exit(1)
 			}
 		}
-		cmd.Printf("Server '%v:%v' (secure=%v) successfully added!\n", host, actualPort, Secure)
+		err = printMessage(cmd, fmt.Sprintf("Server '%v:%v' (secure=%v) successfully added!", host, actualPort, Secure), serverItemFromConfig(alias, server))
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			os.Exit(1)
+		}
 		//}
 	},
 }
 
 var ForceDelete bool
 var RmCommand = &cobra.Command{
-	Use:     "remove",
+	Use:     "remove <alias>",
 	Aliases: []string{"rm"},
-	Short:   "Add new or Update existing Chroma server",
+	Short:   "Remove a Chroma server",
 	Args:    cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		alias := args[0]
-		var servers = viper.GetStringMap("servers")
-		if servers == nil {
-			servers = make(map[string]interface{})
+		servers, err := utils.GetServers()
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			os.Exit(1)
 		}
 		if _, ok := servers[alias]; ok {
 			confirm := ForceDelete
@@ -286,18 +262,27 @@ var RmCommand = &cobra.Command{
 				cmd.Printf("Operation aborted!\n")
 				os.Exit(0)
 			}
+			if err := deleteSecret(servers[alias].Auth); err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "unable to remove the credentials of %v: %v\n", alias, err)
+			}
 			delete(servers, alias)
-			if viper.GetString("active") == alias {
-				viper.Set("active", "")
+			if viper.GetString("active_server") == alias {
+				// the active tenant and database belong to the removed server
+				viper.Set("active_server", "")
+				viper.Set("active_tenant", "")
+				viper.Set("active_db", "")
 				cmd.Println(alias, "was the active server. You will need to set a new active server.")
 			}
-			viper.Set("servers", servers)
-			err := viper.WriteConfig()
+			err := utils.SetServers(servers)
 			if err != nil {
-				cmd.Printf("unable to write to config file: %v\n", err)
+				cmd.Printf("%v\n", err)
+				os.Exit(1)
+			}
+			err = printMessage(cmd, fmt.Sprintf("Server '%v' successfully removed!", alias), serverItem{Alias: alias})
+			if err != nil {
+				cmd.Printf("%v\n", err)
 				os.Exit(1)
 			}
-			cmd.Printf("Server '%v' successfully removed!\n", alias)
 		} else {
 			cmd.Printf("Server with alias %v does not exist! \n", alias)
 			os.Exit(1)
@@ -305,89 +290,495 @@ var RmCommand = &cobra.Command{
 	},
 }
 
+func showServer(cmd *cobra.Command, args []string) error {
+	alias := viper.GetString("active_server")
+	if len(args) > 0 {
+		alias = args[0]
+	}
+	// misconfigured entries are shown as they are so they can be fixed
+	servers, err := utils.GetServers()
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	serverConfig, ok := servers[alias]
+	if !ok {
+		err := fmt.Errorf("server with alias %v does not exist", alias)
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	item := serverItemFromConfig(alias, serverConfig)
+	item.URL = serverConfig.URL()
+	item.Credentials = describeCredentials(serverConfig.Auth)
+	var rows = [][]string{
+		{"Alias", item.Alias},
+		{"URL", item.URL},
+		{"Host", item.Host},
+		{"Port", item.Port},
+		{"Secure", strconv.FormatBool(item.Secure)},
+		{"Tenant", item.Tenant},
+		{"Database", item.Database},
+		{"Auth", strings.TrimSpace(item.AuthType + " " + item.Credentials)},
+	}
+	rows = append(rows, tlsRows(serverConfig.TLS)...)
+	rows = append(rows, []string{"Active", strconv.FormatBool(item.Active)})
+	err = printOutput(cmd, &tableOutput{Headers: []string{"PROPERTY", "VALUE"}, Rows: rows, Items: item})
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	return nil
+}
+
+var ShowCommand = &cobra.Command{
+	Use:     "show [alias]",
+	Aliases: []string{"get"},
+	Short:   "Show a server entry. If alias is not specified the active server is shown.",
+	Args:    cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		err := showServer(cmd, args)
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+// editServer changes the fields of a server entry given as flags and keeps all other fields, including the
+// credentials.
+func editServer(cmd *cobra.Command, args []string) error {
+	alias := args[0]
+	servers, err := utils.GetServers()
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	serverConfig, ok := servers[alias]
+	if !ok {
+		err := fmt.Errorf("server with alias %v does not exist", alias)
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	var changed int
+	if cmd.Flags().Changed("host") {
+		serverConfig.Host, _ = cmd.Flags().GetString("host")
+		changed++
+	}
+	if cmd.Flags().Changed("port") {
+		serverConfig.Port, _ = cmd.Flags().GetInt("port")
+		changed++
+	}
+	if cmd.Flags().Changed("secure") {
+		serverConfig.Secure, _ = cmd.Flags().GetBool("secure")
+		changed++
+	}
+	if cmd.Flags().Changed("timeout") {
+		serverConfig.Timeout, _ = cmd.Flags().GetDuration("timeout")
+		changed++
+	}
+	for key, field := range map[string]*string{"tenant": &serverConfig.Tenant, "database": &serverConfig.Database} {
+		if cmd.Flags().Changed(key) {
+			value, _ := cmd.Flags().GetString(key)
+			if value == "" {
+				err := fmt.Errorf("%v cannot be empty", key)
+				cmd.Printf("%v\n", err)
+				return err
+			}
+			*field = value
+			changed++
+		}
+	}
+	tlsChanged, err := applyTLSFlags(cmd, &serverConfig)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	if tlsChanged {
+		changed++
+	}
+	if changed == 0 {
+		err := fmt.Errorf("nothing to change. use --host, --port, --secure, --timeout, --tenant, --database or the TLS flags")
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	// problems the entry already had do not block fixing other fields
+	var existing = make(map[string]bool)
+	for _, problem := range clitypes.Problems(servers[alias].Validate()) {
+		existing[problem.Error()] = true
+	}
+	for _, problem := range clitypes.Problems(serverConfig.Validate()) {
+		if !existing[problem.Error()] {
+			err := fmt.Errorf("invalid server config: %v", problem)
+			cmd.Printf("%v\n", err)
+			return err
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "server %v is still misconfigured: %v\n", alias, problem)
+	}
+	if tlsChanged {
+		if err := checkTLSOptions(cmd, alias, serverConfig); err != nil {
+			cmd.Printf("%v\n", err)
+			return err
+		}
+	}
+	servers[alias] = serverConfig
+	if err := utils.SetServers(servers); err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	return printMessage(cmd, fmt.Sprintf("Server '%v' updated!", alias), serverItemFromConfig(alias, serverConfig))
+}
+
+var EditCommand = &cobra.Command{
+	Use:   "edit <alias>",
+	Short: "Change fields of a server entry",
+	Long: `Change the given fields of a server entry. Fields that are not given, including the credentials, are kept.
+Use --secure=false to switch back to http. An empty value removes a TLS option.`,
+	Args: cobra.ExactArgs(1),
+	Example: `  chroma server edit prod --port 9000 --secure
+  chroma server edit local --tenant acme --database production
+  chroma server edit internal --ca-file ~/certs/ca.pem --cert-file ~/certs/client.pem --key-file ~/certs/client-key.pem
+  chroma server edit internal --server-name chroma.internal --ca-file ""`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := editServer(cmd, args)
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+func renameServer(cmd *cobra.Command, args []string) error {
+	oldAlias, newAlias := args[0], args[1]
+	servers, err := utils.GetServers()
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	serverConfig, ok := servers[oldAlias]
+	if !ok {
+		err := fmt.Errorf("server with alias %v does not exist", oldAlias)
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	if _, exists := servers[newAlias]; exists {
+		err := fmt.Errorf("server with alias %v already exists", newAlias)
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	// secrets are stored under the alias of the server
+	if auth := serverConfig.Auth; auth != nil && auth.SecretRef != "" {
+		backend, _, err := secrets.ParseRef(auth.SecretRef)
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			return err
+		}
+		secret, err := resolveSecret(auth)
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			return err
+		}
+		var moved = *auth
+		if err := storeSecret(newAlias, backend, &moved, secret); err != nil {
+			cmd.Printf("%v\n", err)
+			return err
+		}
+		if err := deleteSecret(auth); err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "unable to remove the credentials of %v: %v\n", oldAlias, err)
+		}
+		serverConfig.Auth = &moved
+	}
+	delete(servers, oldAlias)
+	servers[newAlias] = serverConfig
+	if viper.GetString("active_server") == oldAlias {
+		viper.Set("active_server", newAlias)
+	}
+	if err := utils.SetServers(servers); err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	return printMessage(cmd, fmt.Sprintf("Server '%v' renamed to '%v'", oldAlias, newAlias), serverItemFromConfig(newAlias, serverConfig))
+}
+
+var RenameCommand = &cobra.Command{
+	Use:   "rename <alias> <new-alias>",
+	Short: "Rename a server entry",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		err := renameServer(cmd, args)
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+// serverItem is the structured representation of a configured server in the command output. Credentials are never
+// included.
+type serverItem struct {
+	Alias       string              `json:"alias" yaml:"alias"`
+	Host        string              `json:"host,omitempty" yaml:"host,omitempty"`
+	Port        string              `json:"port,omitempty" yaml:"port,omitempty"`
+	Secure      bool                `json:"secure" yaml:"secure"`
+	Tenant      string              `json:"tenant,omitempty" yaml:"tenant,omitempty"`
+	Database    string              `json:"database,omitempty" yaml:"database,omitempty"`
+	URL         string              `json:"url,omitempty" yaml:"url,omitempty"`
+	AuthType    string              `json:"auth_type,omitempty" yaml:"auth_type,omitempty"`
+	Credentials string              `json:"credentials,omitempty" yaml:"credentials,omitempty"`
+	TLS         *clitypes.TLSConfig `json:"tls,omitempty" yaml:"tls,omitempty"`
+	Status      *serverStatus       `json:"status,omitempty" yaml:"status,omitempty"`
+	Active      bool                `json:"active" yaml:"active"`
+}
+
+func serverItemFromConfig(alias string, serverConfig clitypes.ServerConfig) serverItem {
+	item := serverItem{
+		Alias:    alias,
+		Host:     serverConfig.Host,
+		Port:     strconv.Itoa(serverConfig.Port),
+		Secure:   serverConfig.Secure,
+		Tenant:   serverConfig.Tenant,
+		Database: serverConfig.Database,
+		TLS:      serverConfig.TLS,
+		Active:   alias == viper.GetString("active_server"),
+	}
+	if serverConfig.Auth != nil {
+		item.AuthType = string(serverConfig.Auth.Type)
+	}
+	return item
+}
+
+func listServers(cmd *cobra.Command, args []string) error {
+	withStatus, err := cmd.Flags().GetBool("status")
+	if err != nil {
+		return err
+	}
+	timeout, err := cmd.Flags().GetDuration("timeout")
+	if err != nil {
+		return err
+	}
+	servers, err := utils.GetServers()
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	aliases := configuredServers()
+	var statuses []serverStatus
+	if withStatus {
+		statuses = pingServers(context.TODO(), aliases, timeout)
+	}
+	var items = make([]serverItem, 0, len(aliases))
+	var rows = make([][]string, 0, len(aliases))
+	for i, alias := range aliases {
+		item := serverItemFromConfig(alias, servers[alias])
+		var active string
+		if item.Active {
+			active = "*"
+		}
+		row := []string{alias, item.Host, item.Port, strconv.FormatBool(item.Secure), item.Tenant, item.Database, active}
+		if withStatus {
+			item.Status = &statuses[i]
+			row = append(row, statuses[i].state(), statuses[i].Version, statuses[i].latency())
+		}
+		items = append(items, item)
+		rows = append(rows, append(row, item.AuthType))
+	}
+	var headers = []string{"ALIAS", "HOST", "PORT", "SECURE", "TENANT", "DATABASE", "ACTIVE"}
+	if withStatus {
+		headers = append(headers, "STATUS", "VERSION", "LATENCY")
+	}
+	return printOutput(cmd, &tableOutput{
+		Headers:     headers,
+		WideHeaders: []string{"AUTH"},
+		Rows:        rows,
+		Items:       items,
+	})
+}
+
 var ListCommand = &cobra.Command{
 	Use:     "list",
 	Aliases: []string{"ls"},
 	Short:   "List all available Chroma servers",
+	Example: `  chroma server ls
+  chroma server ls --status`,
 	Run: func(cmd *cobra.Command, args []string) {
-		var servers = viper.GetStringMap("servers")
-		if servers == nil {
-			servers = make(map[string]interface{})
-		}
-		cmd.Printf("Available servers: \n")
-		for alias, server := range servers {
-			cmd.Printf("%v: %v\n", alias, server)
+		err := listServers(cmd, args)
+		if err != nil {
+			cmd.Printf("%v\n", err)
+			os.Exit(1)
 		}
 	},
 }
 
 var DBAndTenantDefaults bool
 
-var UseCommand = &cobra.Command{
-	Use:   "use",
-	Short: "Set active server",
-	Args:  cobra.MinimumNArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		alias := args[0]
-		err := utils.SetActiveServer(alias)
+// pickOne asks the user to select one of the options. The prompt is skipped if there is nothing to choose from. Returns
+// true if the user was asked.
+func pickOne(title string, options []string, value *string) (bool, error) {
+	if len(options) < 2 {
+		if len(options) == 1 {
+			*value = options[0]
+		}
+		return false, nil
+	}
+	err := huh.NewSelect[string]().Title(title).Options(huh.NewOptions(options...)...).Value(value).Run()
+	if err != nil {
+		return false, fmt.Errorf("unable to get the %v: %v", strings.ToLower(title), err)
+	}
+	return true, nil
+}
+
+// uniqueStrings returns the non-empty values in order without duplicates.
+func uniqueStrings(values ...string) []string {
+	var seen = make(map[string]bool, len(values))
+	var result = make([]string, 0, len(values))
+	for _, value := range values {
+		if value != "" && !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// useContext activates a server and optionally a tenant and database. The resulting context is verified against the
+// server unless --offline is set. On a terminal, omitted values are picked interactively.
+func useContext(cmd *cobra.Command, args []string) error {
+	offline, err := cmd.Flags().GetBool("offline")
+	if err != nil {
+		return err
+	}
+	interactive := !offline && isInteractive()
+	var alias string
+	switch {
+	case len(args) > 0:
+		alias = args[0]
+	case interactive:
+		alias = viper.GetString("active_server")
+		if _, err := pickOne("Server", configuredServers(), &alias); err != nil {
+			cmd.Printf("%v\n", err)
+			return err
+		}
+	default:
+		err := fmt.Errorf("a server alias is required")
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	serverConfig, err := utils.GetServer(alias)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	var serverTenant, serverDatabase = DefaultTenant, DefaultDatabase
+	if serverConfig.Tenant != "" {
+		serverTenant = serverConfig.Tenant
+	}
+	if serverConfig.Database != "" {
+		serverDatabase = serverConfig.Database
+	}
+	// the active tenant and database stay in effect when switching servers unless they are changed
+	tenant, database := resolveScope(alias, serverConfig, viper.GetString("active_tenant"), viper.GetString("active_db"))
+	var setTenant, setDatabase bool
+	if cmd.Flags().Changed("tenant") {
+		tenant, setTenant = Tenant, true
+	} else if DBAndTenantDefaults {
+		tenant, setTenant = serverTenant, true
+	}
+	if cmd.Flags().Changed("database") {
+		database, setDatabase = Database, true
+	} else if DBAndTenantDefaults {
+		database, setDatabase = serverDatabase, true
+	}
+	if !offline {
+		ctx := context.TODO()
+		client, err := getClient(alias, tenant, database)
 		if err != nil {
 			cmd.Printf("%v\n", err)
-			os.Exit(1)
+			return err
 		}
-		if cmd.Flags().Changed("tenant") {
-			err := utils.SetActiveTenant(Tenant)
-			if err != nil {
+		if interactive && !setTenant {
+			// Chroma cannot list tenants, the known ones are offered
+			if setTenant, err = pickOne("Tenant", uniqueStrings(tenant, serverTenant, DefaultTenant), &tenant); err != nil {
 				cmd.Printf("%v\n", err)
-				os.Exit(1)
-			}
-			cmd.Printf("Tenant '%v' set as active!\n", Tenant)
-		} else if cmd.Flags().Changed("defaults") {
-			getSrv, err := utils.GetServer(alias)
-			if err != nil {
-				cmd.Printf("%v\n", err)
-				os.Exit(1)
-			}
-			if getSrv["tenant"] == nil {
-				getSrv["tenant"] = DefaultTenant
-			}
-			if _, ok := getSrv["tenant"]; ok {
-				err := utils.SetActiveTenant(getSrv["tenant"].(string))
-				if err != nil {
-					cmd.Printf("%v\n", err)
-					os.Exit(1)
-				}
-				cmd.Printf("Tenant '%v' set as active!\n", getSrv["tenant"])
+				return err
 			}
 		}
-		if cmd.Flags().Changed("database") {
-			err := utils.SetActiveDatabase(Database)
-			if err != nil {
-				cmd.Printf("%v\n", err)
-				os.Exit(1)
-			}
-			cmd.Printf("Database '%v' set as active!\n", Database)
-		} else if cmd.Flags().Changed("defaults") {
-			getSrv, err := utils.GetServer(alias)
-			if err != nil {
+		if interactive && !setDatabase {
+			databases, err := listDatabases(ctx, client, tenant)
+			if err != nil && !errors.Is(err, errListDatabasesUnsupported) {
+				err = fmt.Errorf("unable to list the databases of tenant %v: %v", tenant, err)
 				cmd.Printf("%v\n", err)
-				os.Exit(1)
+				return err
 			}
-			if getSrv["database"] == nil {
-				getSrv["database"] = DefaultDatabase
+			var names = make([]string, 0, len(databases))
+			for _, db := range databases {
+				names = append(names, db.Name)
 			}
-			if _, ok := getSrv["database"]; ok {
-				err := utils.SetActiveDatabase(getSrv["database"].(string))
-				if err != nil {
-					cmd.Printf("%v\n", err)
-					os.Exit(1)
-				}
+			if setDatabase, err = pickOne("Database", names, &database); err != nil {
+				cmd.Printf("%v\n", err)
+				return err
 			}
-			cmd.Printf("Database '%v' set as active!\n", getSrv["database"])
 		}
-		cmd.Printf("Server '%v' set as active!\n", alias)
+		if err := verifyContext(ctx, client, tenant, database); err != nil {
+			err = fmt.Errorf("%v on server %v. use --offline to skip the check", err, alias)
+			cmd.Printf("%v\n", err)
+			return err
+		}
+	}
+	if err := utils.SetActiveServer(alias); err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	if setTenant {
+		if err := utils.SetActiveTenant(tenant); err != nil {
+			cmd.Printf("%v\n", err)
+			return err
+		}
+		cmd.Printf("Tenant '%v' set as active!\n", tenant)
+	}
+	if setDatabase {
+		if err := utils.SetActiveDatabase(database); err != nil {
+			cmd.Printf("%v\n", err)
+			return err
+		}
+		cmd.Printf("Database '%v' set as active!\n", database)
+	}
+	item := contextItem{Server: alias, URL: serverConfig.URL(), Tenant: tenant, Database: database}
+	return printMessage(cmd, fmt.Sprintf("Server '%v' set as active! Using tenant '%v' and database '%v' on %v", alias, tenant, database, item.URL), item)
+}
+
+// configuredServers returns the sorted aliases of the configured servers.
+func configuredServers() []string {
+	var servers = viper.GetStringMap("servers")
+	var aliases = make([]string, 0, len(servers))
+	for alias := range servers {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	return aliases
+}
+
+var UseCommand = &cobra.Command{
+	Use:   "use [alias]",
+	Short: "Set active server, tenant and database",
+	Long: `Set the active server and optionally the active tenant and database. The tenant and database are checked against
+the server, use --offline to skip the check. On a terminal the server, tenant and database can be picked from a list
+when they are not given.`,
+	Args: cobra.MaximumNArgs(1),
+	Example: `  chroma use local
+  chroma use prod -t acme -d production
+  chroma use prod --defaults --offline`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := useContext(cmd, args)
+		if err != nil {
+			os.Exit(1)
+		}
 	},
 }
 
+// contextItem is the structured representation of the server, tenant and database commands operate on.
+type contextItem struct {
+	Server   string `json:"server" yaml:"server"`
+	URL      string `json:"url,omitempty" yaml:"url,omitempty"`
+	Tenant   string `json:"tenant" yaml:"tenant"`
+	Database string `json:"database" yaml:"database"`
+}
+
 // serverCmd represents the server command
 var serverCmd = &cobra.Command{
 	Use:     "server",
@@ -396,13 +787,14 @@ var serverCmd = &cobra.Command{
 	Long:    ``,
 }
 
-type AuthType string
+type AuthType = clitypes.AuthType
 
 const (
-	AuthTypeNone   AuthType = "none"
-	AuthTypeBasic  AuthType = "basic"
-	AuthTypeToken  AuthType = "token"
-	AuthTypeXToken AuthType = "x-token"
+	AuthTypeNone   = clitypes.AuthTypeNone
+	AuthTypeBasic  = clitypes.AuthTypeBasic
+	AuthTypeToken  = clitypes.AuthTypeToken
+	AuthTypeXToken = clitypes.AuthTypeXToken
+	AuthTypeOIDC   = clitypes.AuthTypeOIDC
 )
 
 func init() {
@@ -413,6 +805,8 @@ func init() {
 	AddCommand.Flags().Bool("login", false, "Authenticate with the server. If the following env vars are not provided the user will be prompted to enter the login information - CHROMA_API_TOKEN or CHROMA_BASIC_AUTH.")
 	AddCommand.Flags().StringVar(&Tenant, "tenant", DefaultTenant, "Default tenant for the server")
 	AddCommand.Flags().StringVar(&Database, "database", DefaultDatabase, "Default database for the server")
+	AddCommand.Flags().String("secret-backend", "", "Where to store the credentials: keyring, file, pass or plain. Defaults to secrets.backend of the config or keyring")
+	addTLSFlags(AddCommand)
 	// AddCommand.MarkFlagsRequiredTogether("host", "port")
 	AddCommand.ValidArgs = []string{"alias"}
 	RmCommand.ValidArgs = []string{"alias"}
@@ -421,10 +815,23 @@ func init() {
 	UseCommand.Flags().StringVarP(&Database, "database", "d", "", "Default database for the server")
 	UseCommand.Flags().BoolVar(&DBAndTenantDefaults, "defaults", false, "Reset active tenant and database to defaults")
 	UseCommand.MarkFlagsMutuallyExclusive("tenant", "defaults")
+	UseCommand.Flags().Bool("offline", false, "Do not check the tenant and database against the server")
 	UseCommand.MarkFlagsMutuallyExclusive("database", "defaults")
 	RootCmd.AddCommand(serverCmd)
 	serverCmd.AddCommand(AddCommand)
+	ListCommand.Flags().Bool("status", false, "Check all servers concurrently and show whether they are up, their version and latency")
+	ListCommand.Flags().Duration("timeout", 5*time.Second, "Time to wait for each server to respond with --status")
 	serverCmd.AddCommand(ListCommand)
 	serverCmd.AddCommand(RmCommand)
+	EditCommand.Flags().String("host", "", "Server host")
+	EditCommand.Flags().IntP("port", "p", 0, "Server port")
+	EditCommand.Flags().Bool("secure", false, "Use secure connection (https)")
+	EditCommand.Flags().Duration("timeout", 0, "Timeout of requests to the server, 0 for no timeout")
+	EditCommand.Flags().StringP("tenant", "t", "", "Default tenant for the server")
+	EditCommand.Flags().StringP("database", "d", "", "Default database for the server")
+	addTLSFlags(EditCommand)
+	serverCmd.AddCommand(ShowCommand)
+	serverCmd.AddCommand(EditCommand)
+	serverCmd.AddCommand(RenameCommand)
 	RootCmd.AddCommand(UseCommand)
 }
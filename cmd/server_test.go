@@ -1,9 +1,18 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
 	"testing"
 
+	clitypes "github.com/amikos-tech/chroma-cli/chroma/types"
+	"github.com/amikos-tech/chroma-cli/chroma/utils"
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 )
 
 func TestValidateHost(t *testing.T) {
@@ -45,3 +54,179 @@ func TestValidateHost(t *testing.T) {
 		require.Error(t, err)
 	})
 }
+
+// helperRestoreActiveContext restores the active server, tenant and database when the test ends.
+func helperRestoreActiveContext(t *testing.T) {
+	var keys = []string{"active_server", "active_tenant", "active_db"}
+	var values = make(map[string]string, len(keys))
+	for _, key := range keys {
+		values[key] = viper.GetString(key)
+	}
+	t.Cleanup(func() {
+		for _, key := range keys {
+			viper.Set(key, values[key])
+		}
+		require.NoError(t, viper.WriteConfig())
+	})
+}
+
+func TestUseCommand(t *testing.T) {
+	command := RootCmd
+	defer resetCommandFlags(RootCmd)
+	defer resetCommandFlags(UseCommand)
+	helperRestoreActiveContext(t)
+	client := setup()
+	defer tearDown(client)
+	alias := viper.GetString("active_server")
+	buf := new(bytes.Buffer)
+	command.SetOut(buf)
+	command.SetErr(buf)
+
+	t.Run("Missing tenant", func(t *testing.T) {
+		resetCommandFlags(UseCommand)
+		require.NoError(t, UseCommand.ParseFlags([]string{"-t", getRandomName("missing-tenant")}))
+		err := useContext(UseCommand, []string{alias})
+		require.ErrorContains(t, err, "does not exist")
+		require.ErrorContains(t, err, "--offline")
+	})
+
+	t.Run("Missing database", func(t *testing.T) {
+		resetCommandFlags(UseCommand)
+		require.NoError(t, UseCommand.ParseFlags([]string{"-d", getRandomName("missing-db")}))
+		err := useContext(UseCommand, []string{alias})
+		require.ErrorContains(t, err, "database")
+		require.ErrorContains(t, err, "does not exist in tenant "+DefaultTenant)
+	})
+
+	t.Run("Offline", func(t *testing.T) {
+		resetCommandFlags(UseCommand)
+		var dbName = getRandomName("offline-db")
+		require.NoError(t, UseCommand.ParseFlags([]string{"-d", dbName, "--offline"}))
+		require.NoError(t, useContext(UseCommand, []string{alias}))
+		require.Equal(t, dbName, viper.GetString("active_db"))
+	})
+
+	t.Run("Existing tenant and database", func(t *testing.T) {
+		resetCommandFlags(UseCommand)
+		var tenantName = getRandomName("use-tenant")
+		var dbName = getRandomName("use-db")
+		helperCreateTenant(t, client, tenantName)
+		_, err := client.CreateDatabase(context.TODO(), dbName, &tenantName)
+		require.NoError(t, err)
+		buf.Reset()
+		command.SetArgs([]string{"use", alias, "-t", tenantName, "-d", dbName, "-o", "json"})
+		_, err = command.ExecuteC()
+		require.NoError(t, err)
+		require.Contains(t, buf.String(), `"tenant": "`+tenantName+`"`)
+		require.Equal(t, tenantName, viper.GetString("active_tenant"))
+		require.Equal(t, dbName, viper.GetString("active_db"))
+	})
+}
+
+// helperRestoreServers restores the server entries and the active context when the test ends.
+func helperRestoreServers(t *testing.T) {
+	helperRestoreActiveContext(t)
+	var servers = make(map[string]interface{})
+	for alias, server := range viper.GetStringMap("servers") {
+		var entry = make(map[string]interface{})
+		for key, value := range server.(map[string]interface{}) {
+			entry[key] = value
+		}
+		servers[alias] = entry
+	}
+	t.Cleanup(func() {
+		// viper merges the keys read from the file when writing, so removed servers are dropped from the file directly
+		viper.Set("servers", servers)
+		settings := viper.AllSettings()
+		settings["servers"] = servers
+		data, err := yaml.Marshal(settings)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(viper.ConfigFileUsed(), data, 0600))
+		require.NoError(t, viper.ReadInConfig())
+	})
+}
+
+func TestShowEditRenameServer(t *testing.T) {
+	command := RootCmd
+	defer resetCommandFlags(RootCmd)
+	defer resetCommandFlags(ShowCommand)
+	defer resetCommandFlags(EditCommand)
+	helperRestoreServers(t)
+	// viper lower cases the keys of the config
+	alias := strings.ToLower(getRandomName("server"))
+	require.NoError(t, utils.SetServer(alias, clitypes.ServerConfig{
+		Host:     "localhost",
+		Port:     8000,
+		Tenant:   DefaultTenant,
+		Database: DefaultDatabase,
+		Auth:     &clitypes.AuthConfig{Type: AuthTypeToken, Token: "ck-0123456789abcdef"},
+	}))
+	buf := new(bytes.Buffer)
+	command.SetOut(buf)
+	command.SetErr(buf)
+
+	t.Run("Show", func(t *testing.T) {
+		buf.Reset()
+		command.SetArgs([]string{"server", "show", alias, "-o", "json"})
+		_, err := command.ExecuteC()
+		require.NoError(t, err)
+		var item serverItem
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &item))
+		require.Equal(t, "http://localhost:8000", item.URL)
+		require.Equal(t, "****cdef", item.Credentials)
+		require.NotContains(t, buf.String(), "0123456789")
+	})
+
+	t.Run("Edit", func(t *testing.T) {
+		resetCommandFlags(EditCommand)
+		buf.Reset()
+		command.SetArgs([]string{"server", "edit", alias, "--port", "9000", "--secure"})
+		_, err := command.ExecuteC()
+		require.NoError(t, err)
+		serverConfig, err := utils.GetServer(alias)
+		require.NoError(t, err)
+		require.Equal(t, "localhost", serverConfig.Host)
+		require.Equal(t, 9000, serverConfig.Port)
+		require.True(t, serverConfig.Secure)
+		require.Equal(t, DefaultTenant, serverConfig.Tenant)
+		require.Equal(t, "ck-0123456789abcdef", serverConfig.Auth.Token)
+
+		resetCommandFlags(EditCommand)
+		require.NoError(t, EditCommand.ParseFlags([]string{}))
+		require.ErrorContains(t, editServer(EditCommand, []string{alias}), "nothing to change")
+
+		resetCommandFlags(EditCommand)
+		require.NoError(t, EditCommand.ParseFlags([]string{"--host", "localhost:9000"}))
+		require.Error(t, editServer(EditCommand, []string{alias}))
+	})
+
+	t.Run("Rename", func(t *testing.T) {
+		newAlias := alias + "-renamed"
+		viper.Set("active_server", alias)
+		buf.Reset()
+		command.SetArgs([]string{"server", "rename", alias, newAlias})
+		_, err := command.ExecuteC()
+		require.NoError(t, err)
+		require.Equal(t, newAlias, viper.GetString("active_server"))
+		_, err = utils.GetServer(alias)
+		require.Error(t, err)
+		serverConfig, err := utils.GetServer(newAlias)
+		require.NoError(t, err)
+		require.Equal(t, 9000, serverConfig.Port)
+
+		require.ErrorContains(t, renameServer(RenameCommand, []string{alias, "other"}), "does not exist")
+		require.ErrorContains(t, renameServer(RenameCommand, []string{newAlias, newAlias}), "already exists")
+	})
+
+	t.Run("Remove active server", func(t *testing.T) {
+		newAlias := alias + "-renamed"
+		viper.Set("active_tenant", "default_tenant")
+		buf.Reset()
+		command.SetArgs([]string{"server", "rm", newAlias, "-f"})
+		_, err := command.ExecuteC()
+		require.NoError(t, err)
+		require.Contains(t, buf.String(), "was the active server")
+		require.Empty(t, viper.GetString("active_server"))
+		require.Empty(t, viper.GetString("active_tenant"))
+	})
+}
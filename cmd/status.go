@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amikos-tech/chroma-cli/chroma/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// statusItem is the structured representation of the effective connection of the CLI.
+type statusItem struct {
+	Server         string  `json:"server" yaml:"server"`
+	ServerSource   string  `json:"server_source" yaml:"server_source"`
+	URL            string  `json:"url" yaml:"url"`
+	Scheme         string  `json:"scheme" yaml:"scheme"`
+	Host           string  `json:"host" yaml:"host"`
+	Port           string  `json:"port" yaml:"port"`
+	AuthType       string  `json:"auth_type" yaml:"auth_type"`
+	Credentials    string  `json:"credentials,omitempty" yaml:"credentials,omitempty"`
+	Tenant         string  `json:"tenant" yaml:"tenant"`
+	TenantSource   string  `json:"tenant_source" yaml:"tenant_source"`
+	Database       string  `json:"database" yaml:"database"`
+	DatabaseSource string  `json:"database_source" yaml:"database_source"`
+	ConfigFile     string  `json:"config_file" yaml:"config_file"`
+	Reachable      bool    `json:"reachable" yaml:"reachable"`
+	Version        string  `json:"version,omitempty" yaml:"version,omitempty"`
+	LatencyMS      float64 `json:"latency_ms,omitempty" yaml:"latency_ms,omitempty"`
+	Error          string  `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// redactSecret hides a credential, keeping the user name of basic auth credentials and the last characters of long
+// tokens so the user can tell which credential is configured.
+func redactSecret(authType AuthType, secret string) string {
+	if secret == "" {
+		return ""
+	}
+	if authType == AuthTypeBasic {
+		if username, _, found := strings.Cut(secret, ":"); found {
+			return username + ":****"
+		}
+	}
+	if len(secret) >= 16 {
+		return "****" + secret[len(secret)-4:]
+	}
+	return "****"
+}
+
+// getStatus resolves the server, tenant and database the command would use and checks that the server responds.
+func getStatus(ctx context.Context, cmd *cobra.Command, timeout time.Duration) (*statusItem, error) {
+	var item = &statusItem{Server: viper.GetString("active_server"), ServerSource: scopeSourceActive, ConfigFile: viper.ConfigFileUsed()}
+	if f := cmd.Flag("alias"); f != nil && f.Changed {
+		item.Server, item.ServerSource = f.Value.String(), scopeSourceFlag
+	}
+	if item.Server == "" {
+		return nil, fmt.Errorf("no active server. add one with chroma server add or select one with chroma use")
+	}
+	serverConfig, err := utils.GetServer(item.Server)
+	if err != nil {
+		return nil, err
+	}
+	var tenant, database string
+	if f := cmd.Flag("tenant"); f != nil && f.Changed {
+		tenant = f.Value.String()
+	}
+	if f := cmd.Flag("database"); f != nil && f.Changed {
+		database = f.Value.String()
+	}
+	item.Tenant, item.TenantSource, item.Database, item.DatabaseSource = resolveScopeSources(item.Server, serverConfig, tenant, database)
+	item.URL = serverConfig.URL()
+	item.Scheme, _, _ = strings.Cut(item.URL, "://")
+	item.Host = serverConfig.Host
+	item.Port = strconv.Itoa(serverConfig.Port)
+	item.AuthType = string(serverConfig.AuthType())
+	item.Credentials = describeCredentials(serverConfig.Auth)
+	status := pingServer(ctx, item.Server, timeout)
+	item.Reachable, item.Version, item.LatencyMS, item.Error = status.Up, status.Version, status.LatencyMS, status.Error
+	return item, nil
+}
+
+func showStatus(cmd *cobra.Command, args []string) error {
+	timeout, err := cmd.Flags().GetDuration("timeout")
+	if err != nil {
+		return err
+	}
+	item, err := getStatus(context.TODO(), cmd, timeout)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	reachable := "no"
+	if item.Reachable {
+		reachable = fmt.Sprintf("yes (%.1f ms)", item.LatencyMS)
+	}
+	var rows = [][]string{
+		{"Server", fmt.Sprintf("%v (%v)", item.Server, item.ServerSource)},
+		{"URL", item.URL},
+		{"Auth", strings.TrimSpace(item.AuthType + " " + item.Credentials)},
+		{"Tenant", fmt.Sprintf("%v (%v)", item.Tenant, item.TenantSource)},
+		{"Database", fmt.Sprintf("%v (%v)", item.Database, item.DatabaseSource)},
+		{"Config", item.ConfigFile},
+		{"Reachable", reachable},
+	}
+	if item.Version != "" {
+		rows = append(rows, []string{"Version", item.Version})
+	}
+	if item.Error != "" {
+		rows = append(rows, []string{"Error", item.Error})
+	}
+	err = printOutput(cmd, &tableOutput{Headers: []string{"PROPERTY", "VALUE"}, Rows: rows, Items: item})
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	if !item.Reachable {
+		return fmt.Errorf("server %v is unreachable: %v", item.Server, item.Error)
+	}
+	return nil
+}
+
+var StatusCommand = &cobra.Command{
+	Use:     "status",
+	Aliases: []string{"context", "ctx"},
+	Short:   "Show the server, tenant and database commands run against",
+	Long: `Show the server, tenant and database that commands run against and where each of them comes from: a flag, the
+active context set with chroma use, the server entry or the default. The server is checked with a heartbeat, the
+command exits with a non-zero status if it is unreachable. Credentials are redacted.`,
+	Args: cobra.NoArgs,
+	Example: `  chroma status
+  chroma status -s prod -t acme -o json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := showStatus(cmd, args)
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	StatusCommand.Flags().StringP("alias", "s", "", "Server alias name. If not provided, the active server will be used.")
+	StatusCommand.Flags().StringP("tenant", "t", "", "Tenant name. If not provided, the active tenant or the server default will be used.")
+	StatusCommand.Flags().StringP("database", "d", "", "Database name. If not provided, the active database or the server default will be used.")
+	StatusCommand.Flags().Duration("timeout", 5*time.Second, "Time to wait for the server to respond")
+	RootCmd.AddCommand(StatusCommand)
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactSecret(t *testing.T) {
+	require.Equal(t, "admin:****", redactSecret(AuthTypeBasic, "admin:secret"))
+	require.Equal(t, "****", redactSecret(AuthTypeToken, "short"))
+	require.Equal(t, "****cdef", redactSecret(AuthTypeToken, "0123456789abcdef"))
+	require.Equal(t, "", redactSecret(AuthTypeNone, ""))
+}
+
+func TestStatusCommand(t *testing.T) {
+	command := RootCmd
+	defer resetCommandFlags(RootCmd)
+	defer resetCommandFlags(StatusCommand)
+	buf := new(bytes.Buffer)
+	command.SetOut(buf)
+	command.SetErr(buf)
+
+	t.Run("Reachable server", func(t *testing.T) {
+		buf.Reset()
+		command.SetArgs([]string{"status", "-d", "some-db", "-o", "json"})
+		_, err := command.ExecuteC()
+		require.NoError(t, err)
+		var item statusItem
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &item))
+		require.True(t, item.Reachable)
+		require.NotEmpty(t, item.Version)
+		require.Equal(t, "some-db", item.Database)
+		require.Equal(t, scopeSourceFlag, item.DatabaseSource)
+	})
+
+	t.Run("Unreachable server", func(t *testing.T) {
+		servers := viper.GetStringMap("servers")
+		defer viper.Set("servers", servers)
+		var withUnreachable = make(map[string]interface{}, len(servers)+1)
+		for alias, server := range servers {
+			withUnreachable[alias] = server
+		}
+		withUnreachable["unreachable"] = map[string]interface{}{"host": "localhost", "port": 1, "secure": false, "auth": map[string]interface{}{"type": "token", "token": "0123456789abcdef"}}
+		viper.Set("servers", withUnreachable)
+		resetCommandFlags(StatusCommand)
+		require.NoError(t, StatusCommand.ParseFlags([]string{"-s", "unreachable"}))
+		item, err := getStatus(context.TODO(), StatusCommand, time.Second)
+		require.NoError(t, err)
+		require.False(t, item.Reachable)
+		require.Equal(t, "****cdef", item.Credentials)
+		buf.Reset()
+		require.ErrorContains(t, showStatus(StatusCommand, nil), "unreachable")
+		require.NotContains(t, buf.String(), "0123456789abcdef")
+	})
+}
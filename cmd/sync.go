@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	chroma "github.com/amikos-tech/chroma-go"
+	"github.com/amikos-tech/chroma-go/collection"
+)
+
+// syncResultItem is the structured representation of a sync pass.
+type syncResultItem struct {
+	Source      string    `json:"source" yaml:"source"`
+	Destination string    `json:"destination" yaml:"destination"`
+	Upserted    int       `json:"upserted" yaml:"upserted"`
+	Deleted     int       `json:"deleted" yaml:"deleted"`
+	Unchanged   int       `json:"unchanged" yaml:"unchanged"`
+	Time        time.Time `json:"time" yaml:"time"`
+}
+
+// syncCollections makes the destination collection a copy of the source. Records that are missing or differ in the
+// destination are upserted from the source, records that are not in the source are deleted from the destination.
+func syncCollections(ctx context.Context, source *chroma.Collection, destination *chroma.Collection, options diffOptions) (*syncResultItem, error) {
+	var result = &syncResultItem{}
+	options.CompareEmbeddings = true
+	options.OnBatch = func(records []recordItem, differences []recordDiffItem) error {
+		if len(differences) == 0 {
+			return nil
+		}
+		var outdated = make(map[string]struct{}, len(differences))
+		for _, d := range differences {
+			outdated[d.ID] = struct{}{}
+		}
+		var upserts = make([]recordItem, 0, len(differences))
+		for _, record := range records {
+			if _, ok := outdated[record.ID]; ok {
+				upserts = append(upserts, record)
+			}
+		}
+		if err := upsertRecords(ctx, destination, upserts); err != nil {
+			return fmt.Errorf("failed to upsert records: %v", err)
+		}
+		result.Upserted += len(upserts)
+		return nil
+	}
+	diff, err := diffCollections(ctx, source, destination, options)
+	if err != nil {
+		return nil, err
+	}
+	var deletes = make([]string, 0, diff.Added)
+	for _, d := range diff.Differences {
+		if d.Status == diffAdded {
+			deletes = append(deletes, d.ID)
+		}
+	}
+	for start := 0; start < len(deletes); start += int(options.BatchSize) {
+		end := min(start+int(options.BatchSize), len(deletes))
+		if _, err := destination.Delete(ctx, deletes[start:end], nil, nil); err != nil {
+			return nil, fmt.Errorf("failed to delete records: %v", err)
+		}
+		result.Deleted += end - start
+	}
+	result.Unchanged = diff.Compared - result.Upserted
+	result.Time = time.Now().UTC()
+	return result, nil
+}
+
+// getSyncDestination returns the destination collection and creates it with the metadata of the source if it does not
+// exist.
+func getSyncDestination(cmd *cobra.Command, client *chroma.Client, ref collectionRef, source *chroma.Collection) (*chroma.Collection, error) {
+	exists, err := collectionExists(client, ref.Collection)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return getCollection(client, ref.Collection)
+	}
+	destination, err := createScopedCollection(context.TODO(), client, collection.WithName(ref.Collection), collection.WithMetadatas(source.Metadata))
+	if err != nil {
+		return nil, err
+	}
+	cmd.Printf("created destination collection %v\n", ref)
+	return destination, nil
+}
+
+func syncCollectionsCommand(cmd *cobra.Command, args []string) error {
+	options, err := getDiffOptionsFromFlags(cmd)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	batchSize, err := cmd.Flags().GetInt("batch-size")
+	if err != nil {
+		return err
+	}
+	if batchSize <= 0 {
+		err := fmt.Errorf("batch-size must be greater than 0")
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	options.BatchSize = int32(batchSize)
+	watch, err := cmd.Flags().GetBool("watch")
+	if err != nil {
+		return err
+	}
+	interval, err := cmd.Flags().GetDuration("interval")
+	if err != nil {
+		return err
+	}
+	if watch && interval <= 0 {
+		err := fmt.Errorf("interval must be greater than 0")
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	sourceRef, err := parseCollectionRef(args[0])
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	destinationRef, err := parseCollectionRef(args[1])
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	sourceClient, err := getClientForRef(cmd, sourceRef)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	destinationClient, err := getClientForRef(cmd, destinationRef)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+
+	syncOnce := func(ctx context.Context) error {
+		source, err := getCollection(sourceClient, sourceRef.Collection)
+		if err != nil {
+			return fmt.Errorf("source collection %v not found: %v", sourceRef, err)
+		}
+		destination, err := getSyncDestination(cmd, destinationClient, destinationRef, source)
+		if err != nil {
+			return err
+		}
+		result, err := syncCollections(ctx, source, destination, options)
+		if err != nil {
+			return err
+		}
+		result.Source, result.Destination = sourceRef.String(), destinationRef.String()
+		return printMessage(cmd, fmt.Sprintf("synced %v to %v: %v upserted, %v deleted, %v unchanged", sourceRef, destinationRef, result.Upserted, result.Deleted, result.Unchanged), result)
+	}
+
+	if !watch {
+		if err := syncOnce(context.TODO()); err != nil {
+			cmd.Printf("%v\n", err)
+			return err
+		}
+		return nil
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	for {
+		// a failed pass is reported and retried with the next pass so a mirror survives restarts of either server
+		if err := syncOnce(ctx); err != nil && ctx.Err() == nil {
+			cmd.Printf("sync failed: %v\n", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+var SyncCommand = &cobra.Command{
+	Use:   "sync <source> <destination>",
+	Short: "Make a collection a mirror of another collection",
+	Long: `Bring the destination collection in line with the source. Records that are missing or differ in the destination
+are upserted from the source, records that are not in the source are deleted from the destination. Records are compared
+by id, document hash, metadata and embedding, only changed records are written. The destination is created with the
+metadata of the source if it does not exist. Collections are referenced like in chroma cp. With --watch the sync is
+repeated every --interval until the command is interrupted.`,
+	Example: `  chroma sync prod:my-collection staging:my-collection
+  chroma sync prod:my-collection staging:my-collection --watch --interval 5m`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		err := syncCollectionsCommand(cmd, args)
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	SyncCommand.Flags().StringP("alias", "s", "", "Server alias name. If not provided, the active server will be used.")
+	SyncCommand.Flags().StringP("tenant", "t", "", "Tenant name. If not provided, the active tenant or the server default will be used.")
+	SyncCommand.Flags().StringP("database", "d", "", "Database name. If not provided, the active database or the server default will be used.")
+	SyncCommand.Flags().IntP("batch-size", "b", 1000, "Number of records read and written per request")
+	SyncCommand.Flags().Bool("watch", false, "Repeat the sync every --interval until interrupted")
+	SyncCommand.Flags().Duration("interval", time.Minute, "Time between two syncs with --watch")
+	addDiffFlags(SyncCommand)
+	RootCmd.AddCommand(SyncCommand)
+}
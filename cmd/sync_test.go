@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncCommand(t *testing.T) {
+	command := RootCmd
+
+	t.Run("Sync to a new collection", func(t *testing.T) {
+		client := setup()
+		defer tearDown(client)
+		var sourceCollectionName = getRandomName("sync-source")
+		var destinationCollectionName = getRandomName("sync-destination")
+		helperCreateCollection(t, client, sourceCollectionName)
+		addDummyRecordsToCollection(t, client, sourceCollectionName, 7)
+		buf := new(bytes.Buffer)
+		command.SetOut(buf)
+		command.SetErr(buf)
+		command.SetArgs([]string{"sync", sourceCollectionName, destinationCollectionName, "--batch-size", "3"})
+		_, err := command.ExecuteC()
+		require.NoError(t, err)
+		require.Contains(t, buf.String(), "created destination collection")
+		require.Contains(t, buf.String(), "7 upserted, 0 deleted, 0 unchanged")
+
+		buf.Reset()
+		command.SetArgs([]string{"sync", sourceCollectionName, destinationCollectionName})
+		_, err = command.ExecuteC()
+		require.NoError(t, err)
+		require.Contains(t, buf.String(), "0 upserted, 0 deleted, 7 unchanged")
+	})
+
+	t.Run("Sync changed and vanished records", func(t *testing.T) {
+		client := setup()
+		defer tearDown(client)
+		var sourceCollectionName = getRandomName("sync-source")
+		var destinationCollectionName = getRandomName("sync-destination")
+		helperCreateCollection(t, client, sourceCollectionName)
+		helperCreateCollection(t, client, destinationCollectionName)
+		addDummyRecordsToCollection(t, client, sourceCollectionName, 5)
+		addDummyRecordsToCollection(t, client, destinationCollectionName, 8)
+		source, err := getCollection(client, sourceCollectionName)
+		require.NoError(t, err)
+		destination, err := getCollection(client, destinationCollectionName)
+		require.NoError(t, err)
+		_, err = destination.Delete(context.TODO(), []string{"id-0"}, nil, nil)
+		require.NoError(t, err)
+
+		result, err := syncCollections(context.TODO(), source, destination, diffOptions{BatchSize: 2})
+		require.NoError(t, err)
+		require.Equal(t, 1, result.Upserted)
+		require.Equal(t, 3, result.Deleted)
+		require.Equal(t, 4, result.Unchanged)
+
+		diff, err := diffCollections(context.TODO(), source, destination, diffOptions{BatchSize: 2, CompareEmbeddings: true})
+		require.NoError(t, err)
+		require.True(t, diff.identical(), diff.summary())
+	})
+}
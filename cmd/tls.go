@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	clitypes "github.com/amikos-tech/chroma-cli/chroma/types"
+	"github.com/mitchellh/go-homedir"
+	"github.com/spf13/cobra"
+)
+
+// tlsClientConfig loads the CA and client certificate files of a server entry. The CA is trusted in addition to the
+// system roots.
+func tlsClientConfig(options *clitypes.TLSConfig) (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         options.ServerName,
+		InsecureSkipVerify: options.InsecureSkipVerify,
+	}
+	if options.CAFile != "" {
+		file, err := homedir.Expand(options.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pem, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the CA file: %v", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no PEM certificates found in the CA file %v", options.CAFile)
+		}
+		config.RootCAs = pool
+	}
+	if options.CertFile != "" || options.KeyFile != "" {
+		certFile, err := homedir.Expand(options.CertFile)
+		if err != nil {
+			return nil, err
+		}
+		keyFile, err := homedir.Expand(options.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load the client certificate: %v", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+// newHTTPClient returns the HTTP client for a server entry with its timeout and TLS options, nil if the server uses
+// neither.
+func newHTTPClient(serverConfig clitypes.ServerConfig) (*http.Client, error) {
+	hasTLS := serverConfig.Secure && serverConfig.TLS != nil && !serverConfig.TLS.IsZero()
+	if !hasTLS && serverConfig.Timeout <= 0 {
+		return nil, nil
+	}
+	var httpClient = &http.Client{Timeout: serverConfig.Timeout}
+	if hasTLS {
+		config, err := tlsClientConfig(serverConfig.TLS)
+		if err != nil {
+			return nil, err
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = config
+		httpClient.Transport = transport
+	}
+	return httpClient, nil
+}
+
+// addTLSFlags adds the flags that set the TLS options of a server to server add and edit.
+func addTLSFlags(cmd *cobra.Command) {
+	cmd.Flags().String("ca-file", "", "PEM file of a CA that signed the server certificate, trusted in addition to the system CAs")
+	cmd.Flags().String("cert-file", "", "PEM file of the client certificate presented to the server (mTLS)")
+	cmd.Flags().String("key-file", "", "PEM file of the key of the client certificate")
+	cmd.Flags().String("server-name", "", "Host name the server certificate is verified against, if it differs from the host")
+	cmd.Flags().Bool("insecure-skip-verify", false, "Do not verify the server certificate. Insecure, for testing only")
+}
+
+// applyTLSFlags sets the TLS options given as flags and reports whether any was given. Files are stored as absolute
+// paths, an empty value removes an option.
+func applyTLSFlags(cmd *cobra.Command, serverConfig *clitypes.ServerConfig) (bool, error) {
+	var options clitypes.TLSConfig
+	if serverConfig.TLS != nil {
+		options = *serverConfig.TLS
+	}
+	var changed bool
+	for flag, field := range map[string]*string{"ca-file": &options.CAFile, "cert-file": &options.CertFile, "key-file": &options.KeyFile} {
+		if !cmd.Flags().Changed(flag) {
+			continue
+		}
+		value, _ := cmd.Flags().GetString(flag)
+		if value != "" {
+			expanded, err := homedir.Expand(value)
+			if err != nil {
+				return false, err
+			}
+			if value, err = filepath.Abs(expanded); err != nil {
+				return false, err
+			}
+		}
+		*field = value
+		changed = true
+	}
+	if cmd.Flags().Changed("server-name") {
+		options.ServerName, _ = cmd.Flags().GetString("server-name")
+		changed = true
+	}
+	if cmd.Flags().Changed("insecure-skip-verify") {
+		options.InsecureSkipVerify, _ = cmd.Flags().GetBool("insecure-skip-verify")
+		changed = true
+	}
+	if !changed {
+		return false, nil
+	}
+	if options.IsZero() {
+		serverConfig.TLS = nil
+		return true, nil
+	}
+	serverConfig.TLS = &options
+	return true, nil
+}
+
+// tlsRows describes the TLS options of a server entry in the rows of server show.
+func tlsRows(options *clitypes.TLSConfig) [][]string {
+	if options == nil {
+		return nil
+	}
+	var rows [][]string
+	for _, row := range [][]string{
+		{"CA File", options.CAFile},
+		{"Client Cert", options.CertFile},
+		{"Client Key", options.KeyFile},
+		{"Server Name", options.ServerName},
+	} {
+		if row[1] != "" {
+			rows = append(rows, row)
+		}
+	}
+	if options.InsecureSkipVerify {
+		rows = append(rows, []string{"Insecure Skip Verify", "true"})
+	}
+	return rows
+}
+
+// checkTLSOptions loads the TLS files of a server entry so broken paths are reported when the entry is saved.
+func checkTLSOptions(cmd *cobra.Command, alias string, serverConfig clitypes.ServerConfig) error {
+	if serverConfig.TLS == nil {
+		return nil
+	}
+	if _, err := tlsClientConfig(serverConfig.TLS); err != nil {
+		return err
+	}
+	if serverConfig.TLS.InsecureSkipVerify {
+		fmt.Fprintf(cmd.ErrOrStderr(), "warning: the certificate of %v is not verified\n", alias)
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	clitypes "github.com/amikos-tech/chroma-cli/chroma/types"
+	"github.com/amikos-tech/chroma-cli/chroma/utils"
+	"github.com/stretchr/testify/require"
+)
+
+// helperCertificate issues a certificate signed by parent, self-signed if parent is nil. The certificate and key are
+// written as PEM files to dir.
+func helperCertificate(t *testing.T, dir string, name string, template *x509.Certificate, parent *tls.Certificate) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template.SerialNumber = big.NewInt(time.Now().UnixNano())
+	template.Subject = pkix.Name{CommonName: name}
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+	var signer, signerKey = template, interface{}(key)
+	if parent != nil {
+		signer, signerKey = parent.Leaf, parent.PrivateKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name+".pem"), certPEM, 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name+"-key.pem"), keyPEM, 0600))
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+	cert.Leaf, err = x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert
+}
+
+// helperMTLSServer starts a server with a certificate for chroma.internal issued by a private CA that requires client
+// certificates issued by the same CA. The CA, client certificate and key are written to the returned directory.
+func helperMTLSServer(t *testing.T) (string, int, string) {
+	dir := t.TempDir()
+	ca := helperCertificate(t, dir, "ca", &x509.Certificate{IsCA: true, BasicConstraintsValid: true, KeyUsage: x509.KeyUsageCertSign}, nil)
+	serverCert := helperCertificate(t, dir, "server", &x509.Certificate{DNSNames: []string{"chroma.internal"}, ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}}, &ca)
+	helperCertificate(t, dir, "client", &x509.Certificate{ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}}, &ca)
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Leaf)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"nanosecond heartbeat": 1}`))
+	}))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{serverCert}, ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: pool}
+	// the handshakes the tests expect to fail are logged by the server
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(u.Port())
+	require.NoError(t, err)
+	return u.Hostname(), port, dir
+}
+
+func TestServerTLS(t *testing.T) {
+	command := RootCmd
+	defer resetCommandFlags(EditCommand)
+	helperRestoreServers(t)
+	host, port, dir := helperMTLSServer(t)
+	alias := strings.ToLower(getRandomName("tls"))
+	require.NoError(t, utils.SetServer(alias, clitypes.ServerConfig{Host: host, Port: port, Secure: true}))
+	buf := new(bytes.Buffer)
+	command.SetOut(buf)
+	command.SetErr(buf)
+	heartbeat := func() error {
+		client, err := getClient(alias, "", "")
+		if err != nil {
+			return err
+		}
+		return apiRequest(context.Background(), client, http.MethodGet, "/api/v1/heartbeat", nil, nil)
+	}
+	edit := func(args ...string) error {
+		resetCommandFlags(EditCommand)
+		buf.Reset()
+		if err := EditCommand.ParseFlags(args); err != nil {
+			return err
+		}
+		return editServer(EditCommand, []string{alias})
+	}
+
+	t.Run("Untrusted CA", func(t *testing.T) {
+		require.ErrorContains(t, heartbeat(), "certificate")
+	})
+
+	t.Run("Private CA and client certificate", func(t *testing.T) {
+		require.NoError(t, edit("--ca-file", filepath.Join(dir, "ca.pem"), "--cert-file", filepath.Join(dir, "client.pem"),
+			"--key-file", filepath.Join(dir, "client-key.pem"), "--server-name", "chroma.internal"))
+		serverConfig, err := utils.GetServer(alias)
+		require.NoError(t, err)
+		require.Equal(t, clitypes.TLSConfig{
+			CAFile:     filepath.Join(dir, "ca.pem"),
+			CertFile:   filepath.Join(dir, "client.pem"),
+			KeyFile:    filepath.Join(dir, "client-key.pem"),
+			ServerName: "chroma.internal",
+		}, *serverConfig.TLS)
+		require.NoError(t, heartbeat())
+
+		buf.Reset()
+		command.SetArgs([]string{"server", "show", alias})
+		_, err = command.ExecuteC()
+		require.NoError(t, err)
+		require.Contains(t, buf.String(), "chroma.internal")
+		require.Contains(t, buf.String(), filepath.Join(dir, "client.pem"))
+	})
+
+	t.Run("Server name mismatch", func(t *testing.T) {
+		require.NoError(t, edit("--server-name", ""))
+		require.ErrorContains(t, heartbeat(), "127.0.0.1")
+		require.NoError(t, edit("--server-name", "chroma.internal"))
+	})
+
+	t.Run("Missing client certificate", func(t *testing.T) {
+		require.NoError(t, edit("--cert-file", "", "--key-file", ""))
+		require.Error(t, heartbeat())
+		require.NoError(t, edit("--cert-file", filepath.Join(dir, "client.pem"), "--key-file", filepath.Join(dir, "client-key.pem")))
+	})
+
+	t.Run("Insecure skip verify", func(t *testing.T) {
+		require.NoError(t, edit("--ca-file", "", "--server-name", "", "--insecure-skip-verify"))
+		require.Contains(t, buf.String(), "is not verified")
+		require.NoError(t, heartbeat())
+	})
+
+	t.Run("Invalid options", func(t *testing.T) {
+		require.ErrorContains(t, edit("--ca-file", filepath.Join(dir, "missing.pem")), "unable to read the CA file")
+		require.ErrorContains(t, edit("--ca-file", filepath.Join(dir, "client-key.pem")), "no PEM certificates found")
+		require.ErrorContains(t, edit("--key-file", ""), "cert_file and key_file must be set together")
+		require.ErrorContains(t, edit("--secure=false"), "tls options require secure: true")
+		serverConfig, err := utils.GetServer(alias)
+		require.NoError(t, err)
+		require.Empty(t, serverConfig.TLS.CAFile)
+		require.True(t, serverConfig.TLS.InsecureSkipVerify)
+	})
+
+	t.Run("Remove all options", func(t *testing.T) {
+		require.NoError(t, edit("--cert-file", "", "--key-file", "", "--insecure-skip-verify=false"))
+		serverConfig, err := utils.GetServer(alias)
+		require.NoError(t, err)
+		require.Nil(t, serverConfig.TLS)
+	})
+}
@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// treeItem is the structured representation of the tenants, databases and collections of a server.
+type treeItem struct {
+	Server  string       `json:"server" yaml:"server"`
+	URL     string       `json:"url" yaml:"url"`
+	Tenants []treeTenant `json:"tenants" yaml:"tenants"`
+}
+
+type treeTenant struct {
+	Name      string         `json:"name" yaml:"name"`
+	Databases []treeDatabase `json:"databases" yaml:"databases"`
+}
+
+type treeDatabase struct {
+	Name        string           `json:"name" yaml:"name"`
+	Records     int              `json:"records" yaml:"records"`
+	Collections []collectionItem `json:"collections" yaml:"collections"`
+}
+
+// buildTree reads the databases of the tenant and the collections of each database. Servers that cannot list
+// databases only show the database the command is scoped to.
+func buildTree(ctx context.Context, cmd *cobra.Command) (*treeItem, error) {
+	client, err := getClientForCommand(cmd)
+	if err != nil {
+		return nil, err
+	}
+	alias := viper.GetString("active_server")
+	if f := cmd.Flag("alias"); f != nil && f.Changed {
+		alias = f.Value.String()
+	}
+	var tree = &treeItem{Server: alias}
+	if servers := client.ApiClient.GetConfig().Servers; len(servers) > 0 {
+		tree.URL = servers[0].URL
+	}
+	var names []string
+	databases, err := listDatabases(ctx, client, client.Tenant)
+	switch {
+	case errors.Is(err, errListDatabasesUnsupported):
+		fmt.Fprintf(cmd.ErrOrStderr(), "the server does not support listing databases, only database %v is shown\n", client.Database)
+		names = append(names, client.Database)
+	case err != nil:
+		return nil, err
+	}
+	for _, db := range databases {
+		names = append(names, db.Name)
+	}
+	var tenant = treeTenant{Name: client.Tenant, Databases: make([]treeDatabase, 0, len(names))}
+	for _, name := range names {
+		dbClient, err := getClient(alias, client.Tenant, name)
+		if err != nil {
+			return nil, err
+		}
+		collections, err := listScopedCollections(ctx, dbClient)
+		if err != nil {
+			return nil, fmt.Errorf("unable to list the collections of database %v: %v", name, err)
+		}
+		var db = treeDatabase{Name: name, Collections: make([]collectionItem, 0, len(collections))}
+		for _, col := range collections {
+			count, err := col.Count(ctx)
+			if err != nil {
+				return nil, err
+			}
+			db.Records += int(count)
+			db.Collections = append(db.Collections, collectionItem{Name: col.Name, ID: col.ID, Tenant: col.Tenant, Database: col.Database, Count: count, Metadata: col.Metadata})
+		}
+		tenant.Databases = append(tenant.Databases, db)
+	}
+	tree.Tenants = append(tree.Tenants, tenant)
+	return tree, nil
+}
+
+// printTree draws the tree with box-drawing characters.
+func printTree(w io.Writer, tree *treeItem, wide bool) {
+	fmt.Fprintf(w, "%v (%v)\n", tree.Server, tree.URL)
+	branch := func(last bool) (string, string) {
+		if last {
+			return "└── ", "    "
+		}
+		return "├── ", "│   "
+	}
+	for i, tenant := range tree.Tenants {
+		tenantBranch, tenantIndent := branch(i == len(tree.Tenants)-1)
+		fmt.Fprintf(w, "%v%v\n", tenantBranch, tenant.Name)
+		for j, db := range tenant.Databases {
+			dbBranch, dbIndent := branch(j == len(tenant.Databases)-1)
+			fmt.Fprintf(w, "%v%v%v (%v collections, %v records)\n", tenantIndent, dbBranch, db.Name, len(db.Collections), db.Records)
+			for k, col := range db.Collections {
+				colBranch, _ := branch(k == len(db.Collections)-1)
+				if wide {
+					fmt.Fprintf(w, "%v%v%v%v (%v records, %v)\n", tenantIndent, dbIndent, colBranch, col.Name, col.Count, col.ID)
+				} else {
+					fmt.Fprintf(w, "%v%v%v%v (%v records)\n", tenantIndent, dbIndent, colBranch, col.Name, col.Count)
+				}
+			}
+		}
+	}
+}
+
+func showTree(cmd *cobra.Command, args []string) error {
+	format, err := getOutputFormat(cmd)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	tree, err := buildTree(context.TODO(), cmd)
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	if format == OutputTable || format == OutputWide {
+		printTree(cmd.OutOrStdout(), tree, format == OutputWide)
+		return nil
+	}
+	var rows = make([][]string, 0)
+	for _, tenant := range tree.Tenants {
+		for _, db := range tenant.Databases {
+			for _, col := range db.Collections {
+				rows = append(rows, []string{tree.Server, tenant.Name, db.Name, col.Name, strconv.Itoa(int(col.Count))})
+			}
+		}
+	}
+	err = printOutput(cmd, &tableOutput{
+		Headers: []string{"SERVER", "TENANT", "DATABASE", "COLLECTION", "COUNT"},
+		Rows:    rows,
+		Items:   tree,
+	})
+	if err != nil {
+		cmd.Printf("%v\n", err)
+		return err
+	}
+	return nil
+}
+
+var TreeCommand = &cobra.Command{
+	Use:   "tree",
+	Short: "Show the databases and collections of a tenant",
+	Long: `Show the server, the tenant, its databases and their collections with record counts. Chroma cannot list tenants,
+so the tree starts at the active tenant or the one given with --tenant. Servers that cannot list databases only show
+the active database.`,
+	Args: cobra.NoArgs,
+	Example: `  chroma tree
+  chroma tree -s prod -t acme -o json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := showTree(cmd, args)
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	TreeCommand.Flags().StringP("alias", "s", "", "Server alias name. If not provided, the active server will be used.")
+	TreeCommand.Flags().StringP("tenant", "t", "", "Tenant name. If not provided, the active tenant or the server default will be used.")
+	TreeCommand.Flags().StringP("database", "d", "", "Database shown when the server cannot list databases. If not provided, the active database or the server default will be used.")
+	RootCmd.AddCommand(TreeCommand)
+}
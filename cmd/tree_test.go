@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/amikos-tech/chroma-go/collection"
+)
+
+func TestTreeCommand(t *testing.T) {
+	command := RootCmd
+	defer resetCommandFlags(RootCmd)
+	defer resetCommandFlags(TreeCommand)
+	client := setup()
+	defer tearDown(client)
+	var tenantName = getRandomName("tree-tenant")
+	helperCreateTenant(t, client, tenantName)
+	var dbName = getRandomName("tree-db")
+	_, err := client.CreateDatabase(context.TODO(), dbName, &tenantName)
+	require.NoError(t, err)
+	dbClient, err := getClient("", tenantName, dbName)
+	require.NoError(t, err)
+	var collectionName = getRandomName("tree-collection")
+	col, err := createScopedCollection(context.TODO(), dbClient, collection.WithName(collectionName))
+	require.NoError(t, err)
+	var records = make([]recordItem, 0, 4)
+	for i := 0; i < 4; i++ {
+		document := fmt.Sprintf("record-%v", i)
+		records = append(records, recordItem{ID: fmt.Sprintf("id-%v", i), Document: &document, Embedding: []float32{float32(i), 1}})
+	}
+	require.NoError(t, upsertRecords(context.TODO(), col, records))
+	buf := new(bytes.Buffer)
+	command.SetOut(buf)
+	command.SetErr(buf)
+
+	t.Run("Tree", func(t *testing.T) {
+		buf.Reset()
+		command.SetArgs([]string{"tree", "-t", tenantName})
+		_, err := command.ExecuteC()
+		require.NoError(t, err)
+		require.Contains(t, buf.String(), "└── "+tenantName)
+		require.Contains(t, buf.String(), dbName+" (1 collections, 4 records)")
+		require.Contains(t, buf.String(), collectionName+" (4 records)")
+	})
+
+	t.Run("Tree as JSON", func(t *testing.T) {
+		buf.Reset()
+		command.SetArgs([]string{"tree", "-t", tenantName, "-o", "json"})
+		_, err := command.ExecuteC()
+		require.NoError(t, err)
+		var tree treeItem
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &tree))
+		require.Len(t, tree.Tenants, 1)
+		require.Len(t, tree.Tenants[0].Databases, 1)
+		require.Equal(t, int32(4), tree.Tenants[0].Databases[0].Collections[0].Count)
+	})
+}
@@ -2,16 +2,29 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 
+	clitypes "github.com/amikos-tech/chroma-cli/chroma/types"
 	"github.com/amikos-tech/chroma-cli/chroma/utils"
+	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"golang.org/x/term"
 
 	chroma "github.com/amikos-tech/chroma-go"
 	"github.com/amikos-tech/chroma-go/cohere"
+	"github.com/amikos-tech/chroma-go/collection"
 	"github.com/amikos-tech/chroma-go/hf"
 	"github.com/amikos-tech/chroma-go/openai"
+	openapi "github.com/amikos-tech/chroma-go/swagger"
 	"github.com/amikos-tech/chroma-go/types"
 )
 
@@ -35,30 +48,243 @@ func embeddingFunctionForString(embedder string, err error) (types.EmbeddingFunc
 		return nil, fmt.Errorf("embedding function not found")
 	}
 }
-func getClient(serverAlias string) (*chroma.Client, error) {
-	var serverConfig map[string]interface{}
-	var err error
+
+// Sources of the server, tenant and database a command operates on.
+const (
+	scopeSourceFlag    = "flag"
+	scopeSourceActive  = "active"
+	scopeSourceServer  = "server"
+	scopeSourceDefault = "default"
+)
+
+// resolveScope determines the tenant and database a command operates on. Explicitly provided values (usually the
+// --tenant/--database flags) take precedence, followed by the active tenant/database set with `chroma use` (only when
+// talking to the active server) and finally the defaults stored with the server entry.
+func resolveScope(alias string, serverConfig clitypes.ServerConfig, tenant string, database string) (string, string) {
+	tenant, _, database, _ = resolveScopeSources(alias, serverConfig, tenant, database)
+	return tenant, database
+}
+
+// resolveScopeSources is resolveScope that also returns where the tenant and the database came from.
+func resolveScopeSources(alias string, serverConfig clitypes.ServerConfig, tenant string, database string) (string, string, string, string) {
+	isActive := alias == viper.GetString("active_server")
+	resolve := func(value string, activeKey string, serverValue string, defaultValue string) (string, string) {
+		if value != "" {
+			return value, scopeSourceFlag
+		}
+		if active := viper.GetString(activeKey); isActive && active != "" {
+			return active, scopeSourceActive
+		}
+		if serverValue != "" {
+			return serverValue, scopeSourceServer
+		}
+		return defaultValue, scopeSourceDefault
+	}
+	tenant, tenantSource := resolve(tenant, "active_tenant", serverConfig.Tenant, DefaultTenant)
+	database, databaseSource := resolve(database, "active_db", serverConfig.Database, DefaultDatabase)
+	return tenant, tenantSource, database, databaseSource
+}
+
+// getAuthOption converts the auth block of a server entry into a client option. Returns nil if no auth is configured.
+// OIDC access tokens are refreshed if they are about to expire.
+func getAuthOption(alias string, serverConfig clitypes.ServerConfig) (chroma.ClientOption, error) {
+	if serverConfig.Auth == nil {
+		return nil, nil
+	}
+	authType := serverConfig.Auth.Type
+	if authType == "" || authType == AuthTypeNone {
+		return nil, nil
+	}
+	if authType == AuthTypeOIDC {
+		token, err := oidcAccessToken(alias, serverConfig.Auth)
+		if err != nil {
+			return nil, err
+		}
+		return chroma.WithAuth(types.NewTokenAuthCredentialsProvider(token, types.AuthorizationTokenHeader)), nil
+	}
+	token, err := resolveSecret(serverConfig.Auth)
+	if err != nil {
+		return nil, err
+	}
+	switch authType {
+	case AuthTypeBasic:
+		username, password, found := strings.Cut(token, ":")
+		if !found {
+			return nil, fmt.Errorf("invalid basic auth credentials, expected username:password")
+		}
+		return chroma.WithAuth(types.NewBasicAuthCredentialsProvider(username, password)), nil
+	case AuthTypeToken:
+		return chroma.WithAuth(types.NewTokenAuthCredentialsProvider(token, types.AuthorizationTokenHeader)), nil
+	case AuthTypeXToken:
+		return chroma.WithAuth(types.NewTokenAuthCredentialsProvider(token, types.XChromaTokenHeader)), nil
+	default:
+		return nil, fmt.Errorf("unsupported auth type: %v", authType)
+	}
+}
+
+// getClient creates a client for the server with the given alias (or the active server if empty). The client is
+// authenticated with the credentials stored for the server and scoped to the tenant and database resolved by resolveScope.
+func getClient(serverAlias string, tenant string, database string) (*chroma.Client, error) {
 	if serverAlias == "" {
-		serverConfig, err = utils.GetServer(viper.GetString("active_server"))
-	} else {
-		serverConfig, err = utils.GetServer(serverAlias)
+		serverAlias = viper.GetString("active_server")
 	}
+	serverConfig, err := utils.GetServer(serverAlias)
 	if err != nil {
 		return nil, err
 	}
-	var scheme string
-	if serverConfig["secure"].(bool) {
-		scheme = "https"
-	} else {
-		scheme = "http"
+	return newClient(serverAlias, serverConfig, tenant, database)
+}
+
+// newClient creates a client for a server entry, see getClient.
+func newClient(serverAlias string, serverConfig clitypes.ServerConfig, tenant string, database string) (*chroma.Client, error) {
+	tenant, database = resolveScope(serverAlias, serverConfig, tenant, database)
+	var options = []chroma.ClientOption{chroma.WithDebug(false), chroma.WithTenant(tenant), chroma.WithDatabase(database)}
+	authOption, err := getAuthOption(serverAlias, serverConfig)
+	if err != nil {
+		return nil, err
+	}
+	if authOption != nil {
+		options = append(options, authOption)
+	}
+	client, err := chroma.NewClient(serverConfig.URL(), options...)
+	if err != nil {
+		return nil, err
 	}
-	client, err := chroma.NewClient(fmt.Sprintf("%v://%v:%v", scheme, serverConfig["host"], serverConfig["port"]), chroma.WithDebug(false))
+	httpClient, err := newHTTPClient(serverConfig)
 	if err != nil {
 		return nil, err
 	}
+	if httpClient != nil {
+		client.ApiClient.GetConfig().HTTPClient = httpClient
+	}
 	return client, nil
 }
 
+// isInteractive reports whether the user can be prompted for input.
+func isInteractive() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// getClientForCommand creates a client for the server selected with --alias, scoped to the tenant and database
+// selected with --tenant/--database. Flags the command does not define are ignored.
+func getClientForCommand(cmd *cobra.Command) (*chroma.Client, error) {
+	var alias, tenant, database string
+	if f := cmd.Flag("alias"); f != nil && f.Changed {
+		alias = f.Value.String()
+	}
+	if f := cmd.Flag("tenant"); f != nil && f.Changed {
+		tenant = f.Value.String()
+	}
+	if f := cmd.Flag("database"); f != nil && f.Changed {
+		database = f.Value.String()
+	}
+	return getClient(alias, tenant, database)
+}
+
+// metadataFromAPI converts the typed metadata returned by the API into a plain map.
+func metadataFromAPI(metadata *map[string]openapi.Metadata) *map[string]interface{} {
+	if metadata == nil {
+		return nil
+	}
+	result := make(map[string]interface{})
+	for key, value := range *metadata {
+		switch {
+		case value.String != nil:
+			result[key] = *value.String
+		case value.Bool != nil:
+			result[key] = *value.Bool
+		case value.Float32 != nil:
+			result[key] = *value.Float32
+		case value.Int32 != nil:
+			result[key] = *value.Int32
+		}
+	}
+	return &result
+}
+
+// listScopedCollections lists the collections in the tenant and database the client is scoped to. The chroma-go
+// ListCollections always targets the default tenant and database.
+func listScopedCollections(ctx context.Context, client *chroma.Client) ([]*chroma.Collection, error) {
+	resp, _, err := client.ApiClient.DefaultApi.ListCollections(ctx).Tenant(client.Tenant).Database(client.Database).Execute()
+	if err != nil {
+		return nil, err
+	}
+	collections := make([]*chroma.Collection, len(resp))
+	for i, col := range resp {
+		collections[i] = chroma.NewCollection(client.ApiClient, col.Id, col.Name, metadataFromAPI(col.Metadata), nil, client.Tenant, client.Database)
+	}
+	return collections, nil
+}
+
+// createScopedCollection creates a collection in the tenant and database the client is scoped to.
+func createScopedCollection(ctx context.Context, client *chroma.Client, options ...collection.Option) (*chroma.Collection, error) {
+	b := &collection.Builder{Metadata: make(map[string]interface{})}
+	for _, option := range options {
+		if err := option(b); err != nil {
+			return nil, err
+		}
+	}
+	if b.Name == "" {
+		return nil, fmt.Errorf("collection name cannot be empty")
+	}
+	var metadata = make(map[string]interface{})
+	for k, v := range b.Metadata {
+		metadata[k] = v
+	}
+	if df, ok := metadata[types.HNSWSpace]; ok {
+		distanceFunction, err := types.ToDistanceFunction(df)
+		if err != nil {
+			return nil, err
+		}
+		metadata[types.HNSWSpace] = strings.ToLower(string(distanceFunction))
+	} else {
+		metadata[types.HNSWSpace] = strings.ToLower(string(types.L2))
+	}
+	if metadata["embedding_function"] == nil && b.EmbeddingFunction != nil {
+		metadata["embedding_function"] = chroma.GetStringTypeOfEmbeddingFunction(b.EmbeddingFunction)
+	}
+	createOrGet := b.CreateIfNotExist
+	resp, _, err := client.ApiClient.DefaultApi.CreateCollection(ctx).
+		Tenant(client.Tenant).
+		Database(client.Database).
+		CreateCollection(openapi.CreateCollection{Name: b.Name, GetOrCreate: &createOrGet, Metadata: metadata}).
+		Execute()
+	if err != nil {
+		return nil, err
+	}
+	return chroma.NewCollection(client.ApiClient, resp.Id, resp.Name, metadataFromAPI(resp.Metadata), b.EmbeddingFunction, client.Tenant, client.Database), nil
+}
+
+// deleteScopedCollection deletes a collection from the tenant and database the client is scoped to.
+func deleteScopedCollection(ctx context.Context, client *chroma.Client, collectionName string) error {
+	_, _, err := client.ApiClient.DefaultApi.DeleteCollection(ctx, collectionName).Tenant(client.Tenant).Database(client.Database).Execute()
+	return err
+}
+
+// parseMetadataValue converts a metadata value given on the command line into a bool, float32, integer or string.
+func parseMetadataValue(value string) interface{} {
+	if b, err := strconv.ParseBool(value); err == nil {
+		return b
+	} else if f, err := strconv.ParseFloat(value, 32); strings.Contains(value, ".") && err == nil {
+		return float32(f)
+	} else if i, err := strconv.ParseInt(value, 10, 32); err == nil {
+		return i
+	}
+	return value
+}
+
+// parseMetadataPairs parses key=value pairs into the given metadata map.
+func parseMetadataPairs(pairs []string, metadata map[string]interface{}) error {
+	for _, meta := range pairs {
+		kvPair := strings.Split(meta, "=")
+		if len(kvPair) != 2 {
+			return fmt.Errorf("invalid metadata format: %v. should be key=value", meta)
+		}
+		metadata[kvPair[0]] = parseMetadataValue(kvPair[1])
+	}
+	return nil
+}
+
 func collectionExists(client *chroma.Client, collectionName string) (bool, error) {
 	if client == nil {
 		return false, fmt.Errorf("client is nil")
@@ -66,7 +292,7 @@ func collectionExists(client *chroma.Client, collectionName string) (bool, error
 	if collectionName == "" {
 		return false, fmt.Errorf("collectionName is empty")
 	}
-	collections, err := client.ListCollections(context.TODO())
+	collections, err := listScopedCollections(context.TODO(), client)
 	if err != nil {
 		return false, err
 	}
@@ -85,7 +311,7 @@ func getCollection(client *chroma.Client, collectionName string) (*chroma.Collec
 	if collectionName == "" {
 		return nil, fmt.Errorf("collectionName is empty")
 	}
-	collections, err := client.ListCollections(context.TODO())
+	collections, err := listScopedCollections(context.TODO(), client)
 	if err != nil {
 		return nil, err
 	}
@@ -96,3 +322,98 @@ func getCollection(client *chroma.Client, collectionName string) (*chroma.Collec
 	}
 	return nil, fmt.Errorf("collection not found")
 }
+
+// apiError is an error response of the Chroma API.
+type apiError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *apiError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("request failed with status %v", e.StatusCode)
+	}
+	return fmt.Sprintf("request failed with status %v: %v", e.StatusCode, e.Message)
+}
+
+// isUnsupportedEndpoint reports whether the server does not implement the requested endpoint, e.g. because it runs an
+// older version of Chroma or a newer one that removed the v1 API.
+func isUnsupportedEndpoint(err error) bool {
+	var apiErr *apiError
+	return errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusNotFound || apiErr.StatusCode == http.StatusMethodNotAllowed || apiErr.StatusCode == http.StatusGone)
+}
+
+// apiRequest calls an endpoint of the Chroma API that the chroma-go client does not cover. The request uses the server
+// URL, the authentication headers and the HTTP client of the given client. The JSON response is decoded into out if it
+// is not nil.
+func apiRequest(ctx context.Context, client *chroma.Client, method string, path string, query url.Values, out interface{}) error {
+	config := client.ApiClient.GetConfig()
+	if len(config.Servers) == 0 {
+		return fmt.Errorf("client has no server")
+	}
+	endpoint := strings.TrimSuffix(config.Servers[0].URL, "/") + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	for key, value := range config.DefaultHeader {
+		req.Header.Set(key, value)
+	}
+	if config.UserAgent != "" {
+		req.Header.Set("User-Agent", config.UserAgent)
+	}
+	req.Header.Set("Accept", "application/json")
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		var message struct {
+			Error   string `json:"error"`
+			Message string `json:"message"`
+			Detail  string `json:"detail"`
+		}
+		_ = json.Unmarshal(body, &message)
+		var text = strings.TrimSpace(strings.Join([]string{message.Error, message.Message, message.Detail}, " "))
+		if text == "" {
+			text = strings.TrimSpace(string(body))
+		}
+		return &apiError{StatusCode: resp.StatusCode, Message: text}
+	}
+	if out == nil || len(body) == 0 {
+		return nil
+	}
+	return json.Unmarshal(body, out)
+}
+
+// getChromaDir returns the directory holding the CLI configuration and state, ~/.chroma by default, creating the
+// given subdirectory if needed.
+func getChromaDir(subdir string) (string, error) {
+	var dir string
+	if configFile := viper.ConfigFileUsed(); configFile != "" {
+		dir = filepath.Dir(configFile)
+	} else {
+		home, err := DefaultHomeDirProvider{}.GetHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".chroma")
+	}
+	dir = filepath.Join(dir, subdir)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+// helperSetServers replaces the configured servers for the duration of the test.
+func helperSetServers(t *testing.T, servers map[string]interface{}, active string) {
+	var previous = make(map[string]interface{})
+	for _, key := range []string{"servers", "active_server", "active_tenant", "active_db"} {
+		previous[key] = viper.Get(key)
+	}
+	viper.Set("servers", servers)
+	viper.Set("active_server", active)
+	viper.Set("active_tenant", "")
+	viper.Set("active_db", "")
+	t.Cleanup(func() {
+		for key, value := range previous {
+			viper.Set(key, value)
+		}
+	})
+}
+
+func TestGetClientAuth(t *testing.T) {
+	t.Run("Token auth", func(t *testing.T) {
+		helperSetServers(t, map[string]interface{}{
+			"secured": map[string]interface{}{"host": "localhost", "port": 8000, "auth": map[string]interface{}{"type": "token", "token": "my-token"}},
+		}, "secured")
+		client, err := getClient("", "", "")
+		require.NoError(t, err)
+		require.Equal(t, "Bearer my-token", client.ApiClient.GetConfig().DefaultHeader["Authorization"])
+	})
+
+	t.Run("X-Chroma-Token auth", func(t *testing.T) {
+		helperSetServers(t, map[string]interface{}{
+			"secured": map[string]interface{}{"host": "localhost", "port": 8000, "auth": map[string]interface{}{"type": "x-token", "token": "my-token"}},
+		}, "secured")
+		client, err := getClient("secured", "", "")
+		require.NoError(t, err)
+		require.Equal(t, "my-token", client.ApiClient.GetConfig().DefaultHeader["X-Chroma-Token"])
+	})
+
+	t.Run("Basic auth", func(t *testing.T) {
+		helperSetServers(t, map[string]interface{}{
+			"secured": map[string]interface{}{"host": "localhost", "port": 8000, "auth": map[string]interface{}{"type": "basic", "token": "admin:secret"}},
+		}, "secured")
+		client, err := getClient("secured", "", "")
+		require.NoError(t, err)
+		expected := "Basic " + base64.StdEncoding.EncodeToString([]byte("admin:secret"))
+		require.Equal(t, expected, client.ApiClient.GetConfig().DefaultHeader["Authorization"])
+	})
+
+	t.Run("Invalid basic auth", func(t *testing.T) {
+		helperSetServers(t, map[string]interface{}{
+			"secured": map[string]interface{}{"host": "localhost", "port": 8000, "auth": map[string]interface{}{"type": "basic", "token": "admin"}},
+		}, "secured")
+		_, err := getClient("secured", "", "")
+		require.Error(t, err)
+	})
+
+	t.Run("No secure flag", func(t *testing.T) {
+		helperSetServers(t, map[string]interface{}{
+			"local": map[string]interface{}{"host": "localhost", "port": "8000"},
+		}, "local")
+		client, err := getClient("", "", "")
+		require.NoError(t, err)
+		require.Equal(t, "http://localhost:8000", client.ApiClient.GetConfig().Servers[0].URL)
+	})
+}
+
+func TestGetClientScope(t *testing.T) {
+	servers := map[string]interface{}{
+		"active": map[string]interface{}{"host": "localhost", "port": 8000, "tenant": "srv-tenant", "database": "srv-db"},
+		"other":  map[string]interface{}{"host": "localhost", "port": 8001, "tenant": "other-tenant", "database": "other-db"},
+		"bare":   map[string]interface{}{"host": "localhost", "port": 8002},
+	}
+
+	t.Run("Server defaults", func(t *testing.T) {
+		helperSetServers(t, servers, "active")
+		client, err := getClient("", "", "")
+		require.NoError(t, err)
+		require.Equal(t, "srv-tenant", client.Tenant)
+		require.Equal(t, "srv-db", client.Database)
+	})
+
+	t.Run("Active tenant and database", func(t *testing.T) {
+		helperSetServers(t, servers, "active")
+		viper.Set("active_tenant", "active-tenant")
+		viper.Set("active_db", "active-db")
+		client, err := getClient("", "", "")
+		require.NoError(t, err)
+		require.Equal(t, "active-tenant", client.Tenant)
+		require.Equal(t, "active-db", client.Database)
+	})
+
+	t.Run("Explicit values win", func(t *testing.T) {
+		helperSetServers(t, servers, "active")
+		viper.Set("active_tenant", "active-tenant")
+		viper.Set("active_db", "active-db")
+		client, err := getClient("", "flag-tenant", "flag-db")
+		require.NoError(t, err)
+		require.Equal(t, "flag-tenant", client.Tenant)
+		require.Equal(t, "flag-db", client.Database)
+	})
+
+	t.Run("Active values ignored for other servers", func(t *testing.T) {
+		helperSetServers(t, servers, "active")
+		viper.Set("active_tenant", "active-tenant")
+		viper.Set("active_db", "active-db")
+		client, err := getClient("other", "", "")
+		require.NoError(t, err)
+		require.Equal(t, "other-tenant", client.Tenant)
+		require.Equal(t, "other-db", client.Database)
+	})
+
+	t.Run("Fallback to defaults", func(t *testing.T) {
+		helperSetServers(t, servers, "active")
+		client, err := getClient("bare", "", "")
+		require.NoError(t, err)
+		require.Equal(t, DefaultTenant, client.Tenant)
+		require.Equal(t, DefaultDatabase, client.Database)
+	})
+}
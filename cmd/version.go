@@ -2,34 +2,37 @@ package cmd
 
 import (
 	"context"
+	"fmt"
 	"os"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
+// versionItem is the structured representation of the server version in the command output.
+type versionItem struct {
+	Version string `json:"version" yaml:"version"`
+}
+
 var VersionCommand = &cobra.Command{
 	Use:     "version",
 	Aliases: []string{"v"},
 	Short:   "Get the version of the Chroma Server. If alias is not specified the currently active server is used.",
 	Run: func(cmd *cobra.Command, args []string) {
-		activeAlias := viper.GetString("active_server")
-		alias, err := getStringFlagIfChangedWithDefault(cmd, "alias", &activeAlias)
+		client, err := getClientForCommand(cmd)
 		if err != nil {
 			cmd.Printf("%v\n", err)
 			os.Exit(1)
 		}
-		client, err := getClient(*alias)
+		version, err := client.Version(context.TODO())
 		if err != nil {
 			cmd.Printf("%v\n", err)
 			os.Exit(1)
 		}
-		version, err := client.Version(context.TODO())
+		err = printMessage(cmd, fmt.Sprintf("Chroma Server Version: %v", version), versionItem{Version: version})
 		if err != nil {
 			cmd.Printf("%v\n", err)
 			os.Exit(1)
 		}
-		cmd.Printf("Chroma Server Version: %v\n", version)
 	},
 }
 
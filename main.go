@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"github.com/amikos-tech/chroma-cli/chroma/cmd"
+	"github.com/amikos-tech/chroma-cli/chroma/types"
+	"github.com/amikos-tech/chroma-cli/chroma/utils"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"log"
@@ -60,6 +62,7 @@ func (c *ChromaCLI) Initialize(options ...CliOption) error {
 					// Unable to create file
 					log.Fatal(err)
 				}
+				viper.Set("version", types.ConfigVersion)
 				err = viper.WriteConfig()
 				if err != nil {
 					fmt.Println("Can't initialize config:", err)
@@ -67,6 +70,15 @@ func (c *ChromaCLI) Initialize(options ...CliOption) error {
 				}
 			}
 		}
+		if migrated, err := utils.MigrateConfig(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		} else if migrated {
+			fmt.Fprintf(os.Stderr, "Config file %v migrated to version %v\n", viper.ConfigFileUsed(), types.ConfigVersion)
+		}
+		// invalid entries are reported when they are used, commands that fix them must keep working
+		if err := utils.ValidateConfig(); err != nil {
+			fmt.Fprintf(os.Stderr, "Config file %v has problems, run chroma config validate for details\n", viper.ConfigFileUsed())
+		}
 	})
 	err := c.rootCmd.Execute()
 	if err != nil {
@@ -0,0 +1,50 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zalando/go-keyring"
+)
+
+// KeyringService is the service name the secrets are stored under in the OS keyring.
+const KeyringService = "chroma-cli"
+
+// Keyring stores secrets in the OS keyring: the Secret Service on Linux, the Keychain on macOS and the Credential
+// Manager on Windows.
+type Keyring struct {
+	Service string
+}
+
+func (k *Keyring) Name() string {
+	return BackendKeyring
+}
+
+func (k *Keyring) Get(key string) (string, error) {
+	secret, err := keyring.Get(k.Service, key)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("unable to read from the OS keyring: %v", err)
+	}
+	return secret, nil
+}
+
+func (k *Keyring) Set(key string, secret string) error {
+	if err := keyring.Set(k.Service, key, secret); err != nil {
+		return fmt.Errorf("unable to write to the OS keyring: %v", err)
+	}
+	return nil
+}
+
+func (k *Keyring) Delete(key string) error {
+	err := keyring.Delete(k.Service, key)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("unable to delete from the OS keyring: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package secrets
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+const (
+	DefaultPassCommand = "pass"
+	DefaultPassPrefix  = "chroma"
+)
+
+// Pass stores secrets with pass (https://www.passwordstore.org) or a command with the same show, insert and rm
+// subcommands such as gopass. The secret is the first line of the entry.
+type Pass struct {
+	Command string
+	Prefix  string
+}
+
+func (p *Pass) Name() string {
+	return BackendPass
+}
+
+func (p *Pass) entry(key string) string {
+	if p.Prefix == "" {
+		return key
+	}
+	return p.Prefix + "/" + key
+}
+
+func (p *Pass) run(stdin string, args ...string) (string, error) {
+	command := exec.Command(p.Command, args...)
+	command.Stdin = strings.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	command.Stdout = &stdout
+	command.Stderr = &stderr
+	if err := command.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%v %v failed: %v", p.Command, args[0], msg)
+		}
+		return "", fmt.Errorf("%v %v failed: %v", p.Command, args[0], err)
+	}
+	return stdout.String(), nil
+}
+
+func (p *Pass) Get(key string) (string, error) {
+	out, err := p.run("", "show", p.entry(key))
+	if err != nil {
+		if strings.Contains(err.Error(), "is not in the password store") {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	secret, _, _ := strings.Cut(out, "\n")
+	return strings.TrimRight(secret, "\r"), nil
+}
+
+func (p *Pass) Set(key string, secret string) error {
+	_, err := p.run(secret+"\n", "insert", "--multiline", "--force", p.entry(key))
+	return err
+}
+
+func (p *Pass) Delete(key string) error {
+	_, err := p.run("", "rm", "--force", p.entry(key))
+	if err != nil && strings.Contains(err.Error(), "is not in the password store") {
+		return ErrNotFound
+	}
+	return err
+}
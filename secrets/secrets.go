@@ -0,0 +1,83 @@
+// Package secrets stores credentials outside of the config file. The config file only keeps a reference of the form
+// <backend>:<key>, e.g. keyring:prod.
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Names of the secret backends. Plain keeps the secret in the config file and has no backend.
+const (
+	BackendKeyring = "keyring"
+	BackendFile    = "file"
+	BackendPass    = "pass"
+	BackendPlain   = "plain"
+)
+
+// Backends are the names of the backends that can be referenced from the config file.
+var Backends = []string{BackendKeyring, BackendFile, BackendPass}
+
+var ErrNotFound = errors.New("secret not found")
+
+// Backend stores secrets by key.
+type Backend interface {
+	Name() string
+	Get(key string) (string, error)
+	Set(key string, secret string) error
+	Delete(key string) error
+}
+
+// Options configure the backends.
+type Options struct {
+	// VaultFile is the encrypted file of the file backend.
+	VaultFile string
+	// Passphrase returns the master passphrase of the vault file. confirm is set when a new vault is created.
+	Passphrase func(confirm bool) (string, error)
+	// Command is the pass compatible command of the pass backend.
+	Command string
+}
+
+// New creates the backend with the given name.
+func New(name string, options Options) (Backend, error) {
+	switch name {
+	case BackendKeyring:
+		return &Keyring{Service: KeyringService}, nil
+	case BackendFile:
+		if options.VaultFile == "" {
+			return nil, fmt.Errorf("the file secret backend requires a vault file")
+		}
+		if options.Passphrase == nil {
+			return nil, fmt.Errorf("the file secret backend requires a passphrase")
+		}
+		return &Vault{File: options.VaultFile, Passphrase: options.Passphrase}, nil
+	case BackendPass:
+		command := options.Command
+		if command == "" {
+			command = DefaultPassCommand
+		}
+		return &Pass{Command: command, Prefix: DefaultPassPrefix}, nil
+	default:
+		return nil, fmt.Errorf("unsupported secret backend: %v. use one of %v", name, strings.Join(Backends, ", "))
+	}
+}
+
+// Ref returns the reference to a secret stored in a backend.
+func Ref(backend string, key string) string {
+	return backend + ":" + key
+}
+
+// ParseRef splits a reference into the backend name and the key.
+func ParseRef(ref string) (string, string, error) {
+	backend, key, found := strings.Cut(ref, ":")
+	if !found || key == "" {
+		return "", "", fmt.Errorf("invalid secret reference %v, expected <backend>:<key>", ref)
+	}
+	for _, b := range Backends {
+		if b == backend {
+			return backend, key, nil
+		}
+	}
+	return "", "", fmt.Errorf("invalid secret reference %v, unsupported backend %v", ref, backend)
+}
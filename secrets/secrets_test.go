@@ -0,0 +1,77 @@
+package secrets
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRef(t *testing.T) {
+	backend, key, err := ParseRef("keyring:prod")
+	require.NoError(t, err)
+	require.Equal(t, BackendKeyring, backend)
+	require.Equal(t, "prod", key)
+
+	_, _, err = ParseRef("prod")
+	require.ErrorContains(t, err, "expected <backend>:<key>")
+	_, _, err = ParseRef("plain:prod")
+	require.ErrorContains(t, err, "unsupported backend plain")
+}
+
+func TestVault(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "secrets.age")
+	passphrase := func(confirm bool) (string, error) { return "correct horse battery staple", nil }
+	vault := &Vault{File: file, Passphrase: passphrase, WorkFactor: 10}
+	_, err := vault.Get("prod")
+	require.ErrorIs(t, err, ErrNotFound)
+	require.NoError(t, vault.Set("prod", "ck-0123456789abcdef"))
+	require.NoError(t, vault.Set("local", "admin:secret"))
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "ck-0123456789abcdef")
+
+	reopened := &Vault{File: file, Passphrase: passphrase}
+	secret, err := reopened.Get("prod")
+	require.NoError(t, err)
+	require.Equal(t, "ck-0123456789abcdef", secret)
+	require.NoError(t, reopened.Delete("prod"))
+	_, err = reopened.Get("prod")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	wrong := &Vault{File: file, Passphrase: func(confirm bool) (string, error) { return "wrong", nil }}
+	_, err = wrong.Get("local")
+	require.ErrorContains(t, err, "wrong passphrase")
+}
+
+func TestPass(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake pass command is a shell script")
+	}
+	dir := t.TempDir()
+	// stores each entry in a file named after the entry, like pass without gpg
+	script := `#!/bin/sh
+store="` + dir + `/store"
+mkdir -p "$store"
+case "$1" in
+show) f="$store/$(echo "$2" | tr / _)"; [ -f "$f" ] || { echo "Error: $2 is not in the password store." >&2; exit 1; }; cat "$f";;
+insert) cat > "$store/$(echo "$4" | tr / _)";;
+rm) f="$store/$(echo "$3" | tr / _)"; [ -f "$f" ] || { echo "Error: $3 is not in the password store." >&2; exit 1; }; rm "$f";;
+esac
+`
+	command := filepath.Join(dir, "pass")
+	require.NoError(t, os.WriteFile(command, []byte(script), 0700))
+	backend, err := New(BackendPass, Options{Command: command})
+	require.NoError(t, err)
+	_, err = backend.Get("prod")
+	require.ErrorIs(t, err, ErrNotFound)
+	require.NoError(t, backend.Set("prod", "ck-0123456789abcdef"))
+	require.FileExists(t, filepath.Join(dir, "store", "chroma_prod"))
+	secret, err := backend.Get("prod")
+	require.NoError(t, err)
+	require.Equal(t, "ck-0123456789abcdef", secret)
+	require.NoError(t, backend.Delete("prod"))
+	require.ErrorIs(t, backend.Delete("prod"), ErrNotFound)
+}
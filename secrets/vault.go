@@ -0,0 +1,147 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"filippo.io/age"
+)
+
+// Vault stores secrets in a file encrypted with age using a master passphrase. The file holds a JSON object of keys
+// to secrets. The passphrase is asked once per process.
+type Vault struct {
+	File       string
+	Passphrase func(confirm bool) (string, error)
+	// WorkFactor is the scrypt work factor of new files, 0 for the age default.
+	WorkFactor int
+
+	passphrase string
+	secrets    map[string]string
+}
+
+func (v *Vault) Name() string {
+	return BackendFile
+}
+
+// load decrypts the vault. A missing file is an empty vault.
+func (v *Vault) load() error {
+	if v.secrets != nil {
+		return nil
+	}
+	data, err := os.ReadFile(v.File)
+	if errors.Is(err, os.ErrNotExist) {
+		v.secrets = make(map[string]string)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to read the secrets file: %v", err)
+	}
+	if v.passphrase == "" {
+		if v.passphrase, err = v.Passphrase(false); err != nil {
+			return err
+		}
+	}
+	identity, err := age.NewScryptIdentity(v.passphrase)
+	if err != nil {
+		return err
+	}
+	r, err := age.Decrypt(bytes.NewReader(data), identity)
+	if err != nil {
+		var noMatch *age.NoIdentityMatchError
+		if errors.As(err, &noMatch) {
+			v.passphrase = ""
+			return fmt.Errorf("wrong passphrase for the secrets file %v", v.File)
+		}
+		return fmt.Errorf("unable to decrypt the secrets file: %v", err)
+	}
+	plaintext, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("unable to decrypt the secrets file: %v", err)
+	}
+	var secrets = make(map[string]string)
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return fmt.Errorf("the secrets file is corrupt: %v", err)
+	}
+	v.secrets = secrets
+	return nil
+}
+
+// save encrypts the vault and replaces the file.
+func (v *Vault) save() error {
+	if v.passphrase == "" {
+		passphrase, err := v.Passphrase(true)
+		if err != nil {
+			return err
+		}
+		v.passphrase = passphrase
+	}
+	recipient, err := age.NewScryptRecipient(v.passphrase)
+	if err != nil {
+		return err
+	}
+	if v.WorkFactor > 0 {
+		recipient.SetWorkFactor(v.WorkFactor)
+	}
+	plaintext, err := json.Marshal(v.secrets)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	w, err := age.Encrypt(&buf, recipient)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(plaintext); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(v.File), 0700); err != nil {
+		return fmt.Errorf("unable to write the secrets file: %v", err)
+	}
+	tmp := v.File + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("unable to write the secrets file: %v", err)
+	}
+	if err := os.Rename(tmp, v.File); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("unable to write the secrets file: %v", err)
+	}
+	return nil
+}
+
+func (v *Vault) Get(key string) (string, error) {
+	if err := v.load(); err != nil {
+		return "", err
+	}
+	secret, ok := v.secrets[key]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return secret, nil
+}
+
+func (v *Vault) Set(key string, secret string) error {
+	if err := v.load(); err != nil {
+		return err
+	}
+	v.secrets[key] = secret
+	return v.save()
+}
+
+func (v *Vault) Delete(key string) error {
+	if err := v.load(); err != nil {
+		return err
+	}
+	if _, ok := v.secrets[key]; !ok {
+		return ErrNotFound
+	}
+	delete(v.secrets, key)
+	return v.save()
+}
@@ -0,0 +1,92 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/amikos-tech/chroma-cli/chroma/secrets"
+)
+
+// ConfigVersion is the version of the config file written by this version of the CLI. Files without a version were
+// written before the config was versioned and are migrated when they are loaded.
+const ConfigVersion = 1
+
+// Config is the config file of the CLI.
+type Config struct {
+	Version      int                     `mapstructure:"version" yaml:"version"`
+	ActiveServer string                  `mapstructure:"active_server" yaml:"active_server"`
+	ActiveTenant string                  `mapstructure:"active_tenant" yaml:"active_tenant"`
+	ActiveDB     string                  `mapstructure:"active_db" yaml:"active_db"`
+	Servers      map[string]ServerConfig `mapstructure:"servers" yaml:"servers"`
+	Secrets      SecretsConfig           `mapstructure:"secrets" yaml:"secrets,omitempty"`
+}
+
+// SecretsConfig selects where new credentials are stored.
+type SecretsConfig struct {
+	// Backend is one of keyring, file, pass or plain. Defaults to keyring.
+	Backend string `mapstructure:"backend" yaml:"backend,omitempty"`
+	// File is the encrypted file of the file backend. Defaults to secrets.age next to the config file.
+	File string `mapstructure:"file" yaml:"file,omitempty"`
+	// Command is the command of the pass backend. Defaults to pass.
+	Command string `mapstructure:"command" yaml:"command,omitempty"`
+}
+
+// Aliases returns the sorted aliases of the servers.
+func (c Config) Aliases() []string {
+	var aliases = make([]string, 0, len(c.Servers))
+	for alias := range c.Servers {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	return aliases
+}
+
+// Validate reports all problems of the config, prefixed with the alias of the server entry they belong to.
+func (c Config) Validate() error {
+	var errs []error
+	if c.Version > ConfigVersion {
+		errs = append(errs, fmt.Errorf("config version %v is not supported, upgrade the CLI", c.Version))
+	}
+	if c.ActiveServer != "" {
+		if _, ok := c.Servers[c.ActiveServer]; !ok {
+			errs = append(errs, fmt.Errorf("active server %v does not exist", c.ActiveServer))
+		}
+	}
+	switch c.Secrets.Backend {
+	case "", secrets.BackendKeyring, secrets.BackendFile, secrets.BackendPass, secrets.BackendPlain:
+	default:
+		errs = append(errs, fmt.Errorf("unsupported secret backend: %v", c.Secrets.Backend))
+	}
+	for _, alias := range c.Aliases() {
+		for _, err := range Problems(c.Servers[alias].Validate()) {
+			errs = append(errs, &ServerError{Alias: alias, Err: err})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ServerError is a problem of a server entry.
+type ServerError struct {
+	Alias string
+	Err   error
+}
+
+func (e *ServerError) Error() string {
+	return fmt.Sprintf("server %v: %v", e.Alias, e.Err)
+}
+
+func (e *ServerError) Unwrap() error {
+	return e.Err
+}
+
+// Problems splits an error returned by Validate into the individual problems.
+func Problems(err error) []error {
+	if err == nil {
+		return nil
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return joined.Unwrap()
+	}
+	return []error{err}
+}
@@ -1,17 +1,193 @@
 package types
 
-// connect to local Chroma
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
 
+	"github.com/amikos-tech/chroma-cli/chroma/secrets"
+	"github.com/go-playground/validator/v10"
+)
+
+type AuthType string
+
+const (
+	AuthTypeNone   AuthType = "none"
+	AuthTypeBasic  AuthType = "basic"
+	AuthTypeToken  AuthType = "token"
+	AuthTypeXToken AuthType = "x-token"
+	AuthTypeOIDC   AuthType = "oidc"
+)
+
+// OIDC login flows.
+const (
+	OIDCFlowDevice            = "device"
+	OIDCFlowClientCredentials = "client_credentials"
+)
+
+// ServerConfig is a server entry of the config file.
 type ServerConfig struct {
-	Host string
-	Port int
+	Host     string        `mapstructure:"host" yaml:"host"`
+	Port     int           `mapstructure:"port" yaml:"port"`
+	Secure   bool          `mapstructure:"secure" yaml:"secure"`
+	Tenant   string        `mapstructure:"tenant" yaml:"tenant,omitempty"`
+	Database string        `mapstructure:"database" yaml:"database,omitempty"`
+	Auth     *AuthConfig   `mapstructure:"auth" yaml:"auth,omitempty"`
+	TLS      *TLSConfig    `mapstructure:"tls" yaml:"tls,omitempty"`
+	Timeout  time.Duration `mapstructure:"timeout" yaml:"timeout,omitempty"`
+}
+
+// AuthConfig holds the credentials of a server. Basic auth credentials are stored as username:password. The
+// credentials are either stored in the config file as token or in a secret backend referenced by SecretRef. For OIDC
+// the credentials are the cached tokens of the last login.
+type AuthConfig struct {
+	Type      AuthType    `mapstructure:"type" yaml:"type"`
+	Token     string      `mapstructure:"token" yaml:"token,omitempty"`
+	SecretRef string      `mapstructure:"secret_ref" yaml:"secret_ref,omitempty"`
+	OIDC      *OIDCConfig `mapstructure:"oidc" yaml:"oidc,omitempty"`
+}
+
+// OIDCConfig holds the identity provider the tokens of a server are obtained from. The endpoints are discovered from
+// the issuer.
+type OIDCConfig struct {
+	Issuer   string   `mapstructure:"issuer" yaml:"issuer"`
+	ClientID string   `mapstructure:"client_id" yaml:"client_id"`
+	Flow     string   `mapstructure:"flow" yaml:"flow"`
+	Scopes   []string `mapstructure:"scopes" yaml:"scopes,omitempty"`
+	Audience string   `mapstructure:"audience" yaml:"audience,omitempty"`
+}
+
+// TLSConfig holds the TLS options of a secure server. The CA file and the client certificate and key are PEM encoded.
+// ServerName overrides the host name the server certificate is verified against.
+type TLSConfig struct {
+	CAFile             string `mapstructure:"ca_file" yaml:"ca_file,omitempty" json:"ca_file,omitempty"`
+	CertFile           string `mapstructure:"cert_file" yaml:"cert_file,omitempty" json:"cert_file,omitempty"`
+	KeyFile            string `mapstructure:"key_file" yaml:"key_file,omitempty" json:"key_file,omitempty"`
+	ServerName         string `mapstructure:"server_name" yaml:"server_name,omitempty" json:"server_name,omitempty"`
+	InsecureSkipVerify bool   `mapstructure:"insecure_skip_verify" yaml:"insecure_skip_verify,omitempty" json:"insecure_skip_verify,omitempty"`
+}
+
+// IsZero reports whether no TLS option is set.
+func (t TLSConfig) IsZero() bool {
+	return t == TLSConfig{}
 }
 
 // Add server configuration
 
 func NewServerConfig() *ServerConfig {
 	return &ServerConfig{
-		Host: "localhost",
-		Port: 8080,
+		Host:   "localhost",
+		Port:   8000,
+		Secure: false,
+	}
+}
+
+// URL returns the base URL of the server.
+func (s ServerConfig) URL() string {
+	var scheme = "http"
+	if s.Secure {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%v://%v:%v", scheme, s.Host, s.Port)
+}
+
+// AuthType returns the configured auth type, none if the server has no credentials.
+func (s ServerConfig) AuthType() AuthType {
+	if s.Auth == nil || s.Auth.Type == "" {
+		return AuthTypeNone
+	}
+	return s.Auth.Type
+}
+
+// ValidateHost checks that the host is a host name or an IPv4 address, without scheme or port.
+func ValidateHost(host string) error {
+	if host == "" {
+		return fmt.Errorf("host cannot be empty")
+	}
+
+	v := validator.New()
+	hostErr := v.Var(host, "hostname")
+	ipErr := v.Var(host, "ip4_addr")
+	if hostErr != nil && ipErr != nil {
+		return fmt.Errorf("invalid host: %v", host)
+	}
+
+	return nil
+}
+
+func (a AuthConfig) validate() []error {
+	var errs []error
+	switch a.Type {
+	case "", AuthTypeNone:
+		return nil
+	case AuthTypeBasic, AuthTypeToken, AuthTypeXToken:
+	case AuthTypeOIDC:
+		if a.OIDC == nil {
+			return []error{fmt.Errorf("auth type oidc requires an oidc block with issuer and client_id")}
+		}
+		errs = append(errs, a.OIDC.validate()...)
+	default:
+		return []error{fmt.Errorf("unsupported auth type: %v", a.Type)}
+	}
+	if a.SecretRef != "" {
+		if a.Token != "" {
+			errs = append(errs, fmt.Errorf("auth has both a token and a secret_ref, remove the token"))
+		}
+		if _, _, err := secrets.ParseRef(a.SecretRef); err != nil {
+			errs = append(errs, err)
+		}
+		return errs
+	}
+	if a.Type == AuthTypeOIDC {
+		// the tokens are missing until the first login
+		return errs
+	}
+	if a.Type == AuthTypeBasic && !strings.Contains(a.Token, ":") {
+		errs = append(errs, fmt.Errorf("invalid basic auth credentials, expected username:password"))
+	} else if a.Token == "" {
+		errs = append(errs, fmt.Errorf("auth type %v requires a token", a.Type))
+	}
+	return errs
+}
+
+func (o OIDCConfig) validate() []error {
+	var errs []error
+	if u, err := url.Parse(o.Issuer); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("invalid oidc issuer: %v. must be an http(s) URL", o.Issuer))
+	}
+	if o.ClientID == "" {
+		errs = append(errs, fmt.Errorf("oidc client_id cannot be empty"))
+	}
+	if o.Flow != OIDCFlowDevice && o.Flow != OIDCFlowClientCredentials {
+		errs = append(errs, fmt.Errorf("unsupported oidc flow: %v. use %v or %v", o.Flow, OIDCFlowDevice, OIDCFlowClientCredentials))
+	}
+	return errs
+}
+
+// Validate reports all problems of the server entry.
+func (s ServerConfig) Validate() error {
+	var errs []error
+	if err := ValidateHost(s.Host); err != nil {
+		errs = append(errs, err)
+	}
+	if s.Port <= 0 || s.Port > 65535 {
+		errs = append(errs, fmt.Errorf("invalid port: %v. must be between 1 and 65535", s.Port))
+	}
+	if s.Timeout < 0 {
+		errs = append(errs, fmt.Errorf("timeout cannot be negative"))
+	}
+	if s.Auth != nil {
+		errs = append(errs, s.Auth.validate()...)
+	}
+	if s.TLS != nil {
+		if !s.Secure {
+			errs = append(errs, fmt.Errorf("tls options require secure: true"))
+		}
+		if (s.TLS.CertFile == "") != (s.TLS.KeyFile == "") {
+			errs = append(errs, fmt.Errorf("tls cert_file and key_file must be set together"))
+		}
 	}
+	return errors.Join(errs...)
 }
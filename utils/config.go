@@ -2,26 +2,173 @@ package utils
 
 import (
 	"fmt"
+	"os"
+	"strings"
 
+	"github.com/amikos-tech/chroma-cli/chroma/types"
 	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
 )
 
-func GetServer(alias string) (map[string]interface{}, error) {
-	var servers = viper.GetStringMap("servers")
+// GetConfig decodes the loaded config file. Ports stored as strings and other loosely typed values written by older
+// versions of the CLI are converted.
+func GetConfig() (*types.Config, error) {
+	var config types.Config
+	if err := viper.Unmarshal(&config); err != nil {
+		return nil, fmt.Errorf("unable to decode config file: %v", err)
+	}
+	if config.Servers == nil {
+		config.Servers = make(map[string]types.ServerConfig)
+	}
+	return &config, nil
+}
+
+// ReadConfigFile decodes the config file at the given path without loading it.
+func ReadConfigFile(path string) (*types.Config, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType("yaml")
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("unable to read config file: %v", err)
+	}
+	var config types.Config
+	if err := v.Unmarshal(&config); err != nil {
+		return nil, fmt.Errorf("unable to decode config file: %v", err)
+	}
+	if config.Servers == nil {
+		config.Servers = make(map[string]types.ServerConfig)
+	}
+	return &config, nil
+}
+
+// GetServers returns the configured servers by alias.
+func GetServers() (map[string]types.ServerConfig, error) {
+	var servers map[string]types.ServerConfig
+	if err := viper.UnmarshalKey("servers", &servers); err != nil {
+		return nil, fmt.Errorf("unable to decode servers from config file: %v", err)
+	}
 	if servers == nil {
-		servers = make(map[string]interface{})
+		servers = make(map[string]types.ServerConfig)
+	}
+	return servers, nil
+}
+
+// GetServer returns the server with the given alias. The entry is validated so a broken entry is reported before it
+// is used.
+func GetServer(alias string) (types.ServerConfig, error) {
+	servers, err := GetServers()
+	if err != nil {
+		return types.ServerConfig{}, err
+	}
+	server, ok := servers[alias]
+	if !ok {
+		return types.ServerConfig{}, fmt.Errorf("server with alias %v does not exist", alias)
 	}
-	if server, ok := servers[alias]; ok {
-		return server.(map[string]interface{}), nil
+	if err := server.Validate(); err != nil {
+		return types.ServerConfig{}, fmt.Errorf("server %v is misconfigured: %v. fix it with chroma server edit or check the config with chroma config validate", alias, joinProblems(err))
 	}
-	return nil, fmt.Errorf("server with alias %v does not exist", alias)
+	return server, nil
+}
+
+// SetServers replaces the server entries and writes the config file with the current config version.
+func SetServers(servers map[string]types.ServerConfig) error {
+	// viper only merges plain maps, so the typed entries are converted through yaml
+	data, err := yaml.Marshal(servers)
+	if err != nil {
+		return fmt.Errorf("unable to encode servers: %v", err)
+	}
+	var entries = make(map[string]interface{})
+	if err := yaml.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("unable to encode servers: %v", err)
+	}
+	viper.Set("servers", entries)
+	viper.Set("version", types.ConfigVersion)
+	// viper merges the nested keys read from the file into the written config, so fields removed from an entry would
+	// be written back. The servers are replaced as a whole and the file is read again.
+	var settings = viper.AllSettings()
+	settings["servers"] = entries
+	data, err = yaml.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("unable to encode config: %v", err)
+	}
+	file := viper.ConfigFileUsed()
+	if file == "" {
+		return fmt.Errorf("unable to write to config file: no config file loaded")
+	}
+	var mode os.FileMode = 0600
+	if info, err := os.Stat(file); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.WriteFile(file, data, mode); err != nil {
+		return fmt.Errorf("unable to write to config file: %v", err)
+	}
+	if err := viper.ReadInConfig(); err != nil {
+		return fmt.Errorf("unable to read config file: %v", err)
+	}
+	return nil
+}
+
+// SetServer adds or replaces the server with the given alias.
+func SetServer(alias string, server types.ServerConfig) error {
+	servers, err := GetServers()
+	if err != nil {
+		return err
+	}
+	servers[alias] = server
+	return SetServers(servers)
+}
+
+// MigrateConfig upgrades a config file written by an older version of the CLI and reports whether it was changed.
+// Files without a version may store ports as strings and omit the secure flag, the entries are rewritten typed. The
+// original file is kept next to it with the old version as suffix.
+func MigrateConfig() (bool, error) {
+	version := viper.GetInt("version")
+	if version >= types.ConfigVersion {
+		return false, nil
+	}
+	servers, err := GetServers()
+	if err != nil {
+		return false, fmt.Errorf("unable to migrate config file from version %v: %v", version, err)
+	}
+	if file := viper.ConfigFileUsed(); file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return false, fmt.Errorf("unable to back up config file: %v", err)
+		}
+		if len(strings.TrimSpace(string(data))) > 0 {
+			if err := os.WriteFile(fmt.Sprintf("%v.v%v.bak", file, version), data, 0600); err != nil {
+				return false, fmt.Errorf("unable to back up config file: %v", err)
+			}
+		}
+	}
+	if err := SetServers(servers); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ValidateConfig decodes and validates the loaded config file.
+func ValidateConfig() error {
+	config, err := GetConfig()
+	if err != nil {
+		return err
+	}
+	return config.Validate()
+}
+
+func joinProblems(err error) string {
+	var problems []string
+	for _, problem := range types.Problems(err) {
+		problems = append(problems, problem.Error())
+	}
+	return strings.Join(problems, "; ")
 }
 
 // SetActiveServer sets the active server to the one with the given alias
 func SetActiveServer(alias string) error {
-	var servers = viper.GetStringMap("servers")
-	if servers == nil {
-		servers = make(map[string]interface{})
+	servers, err := GetServers()
+	if err != nil {
+		return err
 	}
 	if _, ok := servers[alias]; ok {
 		viper.Set("active_server", alias)